@@ -0,0 +1,464 @@
+/*
+Package bridge exposes the stdin/stdout console of a dedicated server process as RCON.
+
+Games like Terraria or Valheim ship without RCON but read commands from their console.
+A Bridge starts the process, writes every command to its stdin and collects the output
+until the process shows its prompt or stays quiet for a moment:
+
+	b := bridge.New(exec.Command("./TerrariaServer", "-config", "serverconfig.txt"))
+	b.Prompt = ": "
+	if err := b.Start(); err != nil {
+		log.Fatal(err)
+	}
+	srv := &server.Server{Addr: ":7777", Auth: server.PasswordAuth("secret"), Handler: b}
+	log.Fatal(srv.ListenAndServe())
+
+Output that is not part of a response, like chat messages or join notifications,
+is passed line by line to the subscribers.
+*/
+package bridge
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamburghammer/grcon/server"
+)
+
+// Defaults of the Bridge.
+const (
+	DefaultQuietPeriod = 250 * time.Millisecond
+	DefaultMaxWait     = 10 * time.Second
+	DefaultStopTimeout = 30 * time.Second
+	DefaultMaxResponse = 1 << 20
+)
+
+// ErrProcessExited is returned for commands after the process exited.
+var ErrProcessExited = errors.New("grcon-bridge: process exited")
+
+// ErrAlreadyStarted is returned by Start if the process was already started.
+var ErrAlreadyStarted = errors.New("grcon-bridge: process already started")
+
+// ErrLineBreak is returned for commands with line breaks, which the console would execute as multiple commands.
+var ErrLineBreak = errors.New("grcon-bridge: command contains a line break")
+
+// New is a constructor for the Bridge struct with the default timings.
+// The stdin, stdout and stderr of the command get connected by Start.
+func New(cmd *exec.Cmd) *Bridge {
+	return &Bridge{
+		Cmd:         cmd,
+		QuietPeriod: DefaultQuietPeriod,
+		MaxWait:     DefaultMaxWait,
+		StopTimeout: DefaultStopTimeout,
+		MaxResponse: DefaultMaxResponse,
+	}
+}
+
+// Bridge runs a console process and implements the server.Handler interface to execute commands on it.
+// Commands are executed one after another.
+type Bridge struct {
+	// Cmd is the process to start.
+	Cmd *exec.Cmd
+	// Prompt ends a response as soon as the output ends with it, e.g. "> ".
+	// The prompt is removed from the responses and the unprompted output.
+	// Without a prompt a response ends after the QuietPeriod.
+	Prompt string
+	// QuietPeriod ends a response if there was no output for this time.
+	QuietPeriod time.Duration
+	// MaxWait limits the time to collect a response. The output collected so far is returned.
+	MaxWait time.Duration
+	// StopCommand is written to stdin by Stop, e.g. "exit". The process gets interrupted if it is empty.
+	StopCommand string
+	// StopTimeout is the time the process has to exit after Stop before it gets killed.
+	StopTimeout time.Duration
+	// MaxResponse limits the bytes of a response. Further output of the command is dropped.
+	MaxResponse int
+
+	// execMutex serializes the commands.
+	execMutex sync.Mutex
+
+	mutex       sync.Mutex
+	stdin       io.WriteCloser
+	started     bool
+	exited      chan struct{}
+	waitErr     error
+	capture     *capture
+	output      chan struct{}
+	partial     []byte
+	lastOutput  time.Time
+	atPrompt    bool
+	subscribers map[chan string]struct{}
+}
+
+// Start starts the process and the reading of its output.
+// Stdout and stderr are both read.
+func (b *Bridge) Start() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.started {
+		return ErrAlreadyStarted
+	}
+
+	stdin, err := b.Cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		stdin.Close()
+		return err
+	}
+	b.Cmd.Stdout = writer
+	b.Cmd.Stderr = writer
+
+	if err := b.Cmd.Start(); err != nil {
+		reader.Close()
+		writer.Close()
+		return err
+	}
+	// the process holds its own copy of the writer, EOF is read when it exits.
+	writer.Close()
+
+	b.started = true
+	b.stdin = stdin
+	b.exited = make(chan struct{})
+	b.output = make(chan struct{}, 1)
+	b.lastOutput = time.Now()
+
+	go b.read(reader)
+	return nil
+}
+
+// read passes the output to the running command or the subscribers until the process exits.
+func (b *Bridge) read(reader io.ReadCloser) {
+	defer reader.Close()
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			b.handleOutput(buf[:n])
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	waitErr := b.Cmd.Wait()
+
+	b.mutex.Lock()
+	b.waitErr = waitErr
+	if len(b.partial) > 0 {
+		b.publish(string(b.partial))
+		b.partial = nil
+	}
+	for subscriber := range b.subscribers {
+		close(subscriber)
+	}
+	b.subscribers = nil
+	b.mutex.Unlock()
+
+	close(b.exited)
+}
+
+func (b *Bridge) handleOutput(data []byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.lastOutput = time.Now()
+	b.atPrompt = false
+	select {
+	case b.output <- struct{}{}:
+	default:
+	}
+
+	if b.capture != nil {
+		b.capture.Write(data)
+		return
+	}
+
+	b.partial = append(b.partial, data...)
+	for {
+		i := bytes.IndexByte(b.partial, '\n')
+		if i < 0 {
+			break
+		}
+		b.publish(string(b.partial[:i]))
+		b.partial = b.partial[i+1:]
+	}
+	if b.Prompt != "" && string(b.partial) == b.Prompt {
+		b.partial = nil
+		b.atPrompt = true
+	}
+}
+
+// capture collects the output of a command up to a limit.
+type capture struct {
+	buf   bytes.Buffer
+	limit int
+	// tail is the end of the output, which can be dropped from the buf, to detect the prompt.
+	tail      []byte
+	tailSize  int
+	truncated bool
+}
+
+func (c *capture) Write(data []byte) {
+	room := c.limit - c.buf.Len()
+	if len(data) > room {
+		c.buf.Write(data[:room])
+		c.truncated = true
+	} else {
+		c.buf.Write(data)
+	}
+
+	c.tail = append(c.tail, data...)
+	if len(c.tail) > c.tailSize {
+		c.tail = c.tail[len(c.tail)-c.tailSize:]
+	}
+}
+
+// publish sends the line to the subscribers. The mutex has to be held.
+func (b *Bridge) publish(line string) {
+	line = strings.TrimRight(line, "\r")
+	if b.Prompt != "" {
+		line = strings.TrimPrefix(line, b.Prompt)
+	}
+	if line == "" {
+		return
+	}
+
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- line:
+		default:
+			// slow subscribers miss lines instead of blocking the console.
+		}
+	}
+}
+
+// Subscribe returns a channel with the lines of the output that are not part of a response.
+// Lines get dropped if the buffer of the channel is full.
+// The channel gets closed when the process exits or the returned cancel function is called.
+func (b *Bridge) Subscribe(buffer int) (<-chan string, func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	lines := make(chan string, buffer)
+	if b.exited != nil && b.isExited() {
+		close(lines)
+		return lines, func() {}
+	}
+	if b.subscribers == nil {
+		b.subscribers = map[chan string]struct{}{}
+	}
+	b.subscribers[lines] = struct{}{}
+
+	var once sync.Once
+	return lines, func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+			if _, ok := b.subscribers[lines]; ok {
+				delete(b.subscribers, lines)
+				close(lines)
+			}
+		})
+	}
+}
+
+// Exec writes the command to stdin and returns the output until the prompt, the quiet period or the MaxWait.
+// The command is written after the console showed its prompt or was quiet for the QuietPeriod,
+// so that the output of the startup or of the previous command is not part of the response.
+//
+// The response is cut after MaxResponse bytes.
+//
+// Errors:
+// Returns ErrLineBreak for commands with line breaks, ErrProcessExited if the process is not running
+// and the errors of writing to stdin.
+func (b *Bridge) Exec(cmd string) (string, error) {
+	if strings.ContainsAny(cmd, "\r\n") {
+		return "", ErrLineBreak
+	}
+
+	b.execMutex.Lock()
+	defer b.execMutex.Unlock()
+
+	b.mutex.Lock()
+	started := b.started
+	b.mutex.Unlock()
+	if !started {
+		return "", ErrProcessExited
+	}
+
+	b.wait(func() bool { return b.atPrompt })
+
+	b.mutex.Lock()
+	if b.isExited() {
+		b.mutex.Unlock()
+		return "", ErrProcessExited
+	}
+	limit := b.MaxResponse
+	if limit <= 0 {
+		limit = DefaultMaxResponse
+	}
+	capture := &capture{limit: limit, tailSize: len(b.Prompt)}
+	b.capture = capture
+	b.lastOutput = time.Now()
+	// a started line belongs to the unprompted output.
+	if len(b.partial) > 0 {
+		b.publish(string(b.partial))
+		b.partial = nil
+	}
+	b.mutex.Unlock()
+
+	defer func() {
+		b.mutex.Lock()
+		b.capture = nil
+		b.mutex.Unlock()
+	}()
+
+	if _, err := io.WriteString(b.stdin, cmd+"\n"); err != nil {
+		return "", err
+	}
+
+	prompted := b.wait(func() bool {
+		return b.Prompt != "" && string(capture.tail) == b.Prompt
+	})
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	response := capture.buf.String()
+	if prompted {
+		if !capture.truncated {
+			response = strings.TrimSuffix(response, b.Prompt)
+		}
+		b.atPrompt = true
+	}
+	return response, nil
+}
+
+// wait waits until done returns true, there was no output for the QuietPeriod,
+// the MaxWait passed or the process exited. done is called with the mutex held.
+// Returns the result of done.
+func (b *Bridge) wait(done func() bool) bool {
+	maxWait := time.NewTimer(orDefault(b.MaxWait, DefaultMaxWait))
+	defer maxWait.Stop()
+	quietPeriod := orDefault(b.QuietPeriod, DefaultQuietPeriod)
+
+	for {
+		b.mutex.Lock()
+		if done() {
+			b.mutex.Unlock()
+			return true
+		}
+		quiet := quietPeriod - time.Since(b.lastOutput)
+		b.mutex.Unlock()
+		if quiet <= 0 {
+			return false
+		}
+
+		quietTimer := time.NewTimer(quiet)
+		select {
+		case <-b.output:
+		case <-quietTimer.C:
+		case <-maxWait.C:
+			quietTimer.Stop()
+			return false
+		case <-b.exited:
+			quietTimer.Stop()
+			return false
+		}
+		quietTimer.Stop()
+	}
+}
+
+// ServeRCON executes the command and writes the output as response.
+// Errors are written as response because the protocol has no error packets.
+func (b *Bridge) ServeRCON(w server.ResponseWriter, r *server.Request) {
+	response, err := b.Exec(r.Command)
+	if err == ErrProcessExited || err == ErrLineBreak {
+		io.WriteString(w, err.Error())
+		return
+	}
+	if err != nil {
+		io.WriteString(w, "grcon-bridge: "+err.Error())
+		return
+	}
+	w.Write([]byte(response))
+}
+
+// Stop writes the StopCommand or interrupts the process and waits until it exited.
+// The process gets killed if it does not exit within the StopTimeout.
+func (b *Bridge) Stop() error {
+	b.mutex.Lock()
+	started := b.started
+	b.mutex.Unlock()
+	if !started {
+		return nil
+	}
+
+	if b.StopCommand != "" {
+		b.mutex.Lock()
+		_, err := io.WriteString(b.stdin, b.StopCommand+"\n")
+		b.mutex.Unlock()
+		if err != nil {
+			b.Cmd.Process.Kill()
+		}
+	} else if err := b.Cmd.Process.Signal(os.Interrupt); err != nil {
+		b.Cmd.Process.Kill()
+	}
+
+	select {
+	case <-b.exited:
+	case <-time.After(orDefault(b.StopTimeout, DefaultStopTimeout)):
+		b.Cmd.Process.Kill()
+		<-b.exited
+	}
+	return b.Wait()
+}
+
+// Wait waits until the process exited and returns its error.
+func (b *Bridge) Wait() error {
+	b.mutex.Lock()
+	exited := b.exited
+	b.mutex.Unlock()
+	if exited == nil {
+		return nil
+	}
+
+	<-exited
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.waitErr
+}
+
+// Exited returns a channel that gets closed when the process exited.
+func (b *Bridge) Exited() <-chan struct{} {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.exited
+}
+
+// isExited reports if the process exited. The mutex has to be held.
+func (b *Bridge) isExited() bool {
+	select {
+	case <-b.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+func orDefault(value, def time.Duration) time.Duration {
+	if value <= 0 {
+		return def
+	}
+	return value
+}
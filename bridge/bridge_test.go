@@ -0,0 +1,193 @@
+package bridge_test
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon/bridge"
+)
+
+// TestMain runs the fake console instead of the tests if the test binary is started as helper process.
+func TestMain(m *testing.M) {
+	if os.Getenv("GRCON_BRIDGE_HELPER") == "1" {
+		fakeConsole()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeConsole behaves like the console of a dedicated server with the prompt "> ".
+func fakeConsole() {
+	prompt := os.Getenv("GRCON_BRIDGE_PROMPT")
+	fmt.Print("Server started\n" + prompt)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		cmd := scanner.Text()
+		switch {
+		case cmd == "exit":
+			fmt.Println("Saving world")
+			return
+		case cmd == "playing":
+			fmt.Println("alice")
+			fmt.Println("bob")
+			fmt.Println("2 players connected.")
+		case cmd == "flood":
+			for i := 0; i < 1000; i++ {
+				fmt.Printf("line %d\n", i)
+			}
+		case cmd == "slow":
+			fmt.Println("first")
+			time.Sleep(100 * time.Millisecond)
+			fmt.Println("second")
+		case strings.HasPrefix(cmd, "say "):
+			fmt.Println("<Server> " + strings.TrimPrefix(cmd, "say "))
+			fmt.Print(prompt)
+			// a chat message that is not part of the response.
+			time.Sleep(50 * time.Millisecond)
+			fmt.Println("<alice> hi")
+			continue
+		default:
+			fmt.Println("Unknown command.")
+		}
+		fmt.Print(prompt)
+	}
+}
+
+func startBridge(t *testing.T, prompt string) *bridge.Bridge {
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), "GRCON_BRIDGE_HELPER=1", "GRCON_BRIDGE_PROMPT="+prompt)
+
+	b := bridge.New(cmd)
+	b.Prompt = prompt
+	b.QuietPeriod = 300 * time.Millisecond
+	b.MaxWait = 2 * time.Second
+	b.StopCommand = "exit"
+	b.StopTimeout = 5 * time.Second
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Stop() })
+	return b
+}
+
+func TestBridge_Exec(t *testing.T) {
+	t.Run("prompt", func(t *testing.T) {
+		b := startBridge(t, "> ")
+		// a long quiet period shows that the prompt ends the response.
+		b.QuietPeriod = 5 * time.Second
+
+		start := time.Now()
+		got, err := b.Exec("playing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "alice\nbob\n2 players connected.\n"
+		if got != expected {
+			t.Errorf("response did not match:\nexpected: %q\ngot: %q\n", expected, got)
+		}
+		if time.Since(start) > time.Second {
+			t.Errorf("response took %s instead of ending at the prompt\n", time.Since(start))
+		}
+	})
+
+	t.Run("quiet period", func(t *testing.T) {
+		b := startBridge(t, "")
+
+		got, err := b.Exec("slow")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "first\nsecond\n"
+		if got != expected {
+			t.Errorf("response did not match:\nexpected: %q\ngot: %q\n", expected, got)
+		}
+	})
+
+	t.Run("line break", func(t *testing.T) {
+		b := startBridge(t, "> ")
+
+		for _, cmd := range []string{"say hi\nexit", "say hi\rexit"} {
+			if _, err := b.Exec(cmd); err != bridge.ErrLineBreak {
+				t.Errorf("error of %q did not match:\nexpected: %v\ngot: %v\n", cmd, bridge.ErrLineBreak, err)
+			}
+		}
+		select {
+		case <-b.Exited():
+			t.Error("expected the process to keep running")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("max response", func(t *testing.T) {
+		b := startBridge(t, "> ")
+		b.QuietPeriod = 5 * time.Second
+		b.MaxResponse = 100
+
+		got, err := b.Exec("flood")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 100 || !strings.HasPrefix(got, "line 0\n") {
+			t.Errorf("expected the first 100 bytes but got %d: %q\n", len(got), got)
+		}
+		// the prompt after the dropped output ends the response.
+		got, err = b.Exec("playing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := "alice\nbob\n2 players connected.\n"; got != expected {
+			t.Errorf("response did not match:\nexpected: %q\ngot: %q\n", expected, got)
+		}
+	})
+
+	t.Run("process exited", func(t *testing.T) {
+		b := startBridge(t, "> ")
+
+		if err := b.Stop(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := b.Exec("playing"); err != bridge.ErrProcessExited {
+			t.Errorf("error did not match:\nexpected: %v\ngot: %v\n", bridge.ErrProcessExited, err)
+		}
+	})
+}
+
+func TestBridge_Subscribe(t *testing.T) {
+	b := startBridge(t, "> ")
+	lines, cancel := b.Subscribe(10)
+	defer cancel()
+
+	got, err := b.Exec("say hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "<Server> hello\n" {
+		t.Errorf("response did not match:\nexpected: %q\ngot: %q\n", "<Server> hello\n", got)
+	}
+
+	// the startup output is unprompted too.
+	timeout := time.After(2 * time.Second)
+	for line := ""; line != "<alice> hi"; {
+		select {
+		case line = <-lines:
+			if line == "<Server> hello" {
+				t.Error("expected the response not to be passed to the subscribers")
+			}
+		case <-timeout:
+			t.Fatal("expected the unprompted output")
+		}
+	}
+
+	if err := b.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	// the channel gets closed after the remaining output of the process.
+	for range lines {
+	}
+}
@@ -0,0 +1,17 @@
+package client
+
+import (
+	"net"
+	"time"
+)
+
+// DialFunc opens a new connection to a remote console.
+type DialFunc func() (net.Conn, error)
+
+// TCPDialer returns a DialFunc that connects over TCP to the address.
+// A timeout of zero means no timeout.
+func TCPDialer(addr string, timeout time.Duration) DialFunc {
+	return func() (net.Conn, error) {
+		return net.DialTimeout("tcp", addr, timeout)
+	}
+}
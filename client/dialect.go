@@ -0,0 +1,67 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// Dialect names a Client implementation for servers with the same RCON behavior.
+type Dialect string
+
+// Supported dialects.
+const (
+	// DialectSimple uses the SimpleClient.
+	DialectSimple Dialect = "simple"
+	// DialectSource uses the SourceClient.
+	DialectSource Dialect = "source"
+	// DialectMinecraft uses the MinecraftClient.
+	DialectMinecraft Dialect = "minecraft"
+	// DialectFactorio uses the FactorioClient.
+	DialectFactorio Dialect = "factorio"
+)
+
+// NewDialectClient creates the Client for the dialect.
+// The util.GenerateRequestId can be used as idGenFunc.
+//
+// Returns an InvalidArgumentError for unknown dialects.
+func NewDialectClient(dialect Dialect, r util.RemoteConsole, idGenFunc func() grcon.PacketId) (Client, error) {
+	switch dialect {
+	case DialectSimple:
+		return NewSimpleClient(r, idGenFunc), nil
+	case DialectSource:
+		return NewSourceClient(r, idGenFunc), nil
+	case DialectMinecraft:
+		return NewMinecraftClient(r, idGenFunc), nil
+	case DialectFactorio:
+		return NewFactorioClient(r, idGenFunc), nil
+	}
+
+	return nil, newInvalidArgumentError(fmt.Sprintf("unknown dialect '%s'", dialect))
+}
+
+// MirrorsResponseValue reports if servers of the dialect mirror empty SERVERDATA_RESPONSE_VALUE packets.
+// Clients of these dialects send such a packet after each command to detect the end of multi packet responses.
+func (d Dialect) MirrorsResponseValue() bool {
+	return d == DialectSimple || d == DialectSource
+}
+
+// MaxCommandLength returns the maximal length of a command that servers of the dialect accept.
+// Minecraft servers close the connection on commands longer than 1446 bytes.
+func (d Dialect) MaxCommandLength() int {
+	if d == DialectMinecraft {
+		return 1446
+	}
+	return int(grcon.MaxBody)
+}
+
+// MaxPacket returns the maximal packet size that servers of the dialect send.
+// Minecraft servers send the fragments of long responses in packets up to the MinecraftMaxPacket.
+// The size can be used with grcon.NewRemoteConsoleSize.
+func (d Dialect) MaxPacket() int {
+	if d == DialectMinecraft {
+		return MinecraftMaxPacket
+	}
+	return int(grcon.MaxPacket)
+}
@@ -78,3 +78,73 @@ func newResponseBodyError(expected, actual string) ResponseBodyError {
 type ResponseBodyError struct {
 	GrconClientError
 }
+
+func newLuaError(msg string) LuaError {
+	return LuaError{
+		GrconClientError: newGrconClientError(grcon.Read, fmt.Errorf("lua error: %s", msg)),
+		Message:          msg,
+	}
+}
+
+// LuaError occurres when a server could not execute a Lua script.
+type LuaError struct {
+	GrconClientError
+	// Message is the error message reported by the server.
+	Message string
+}
+
+func newResponseParseError(response string, err error) ResponseParseError {
+	return ResponseParseError{
+		GrconClientError: newGrconClientError(grcon.Read, fmt.Errorf("unable to parse response '%s': %w", response, err)),
+		Response:         response,
+	}
+}
+
+// ResponseParseError occurres when a response has not the expected format.
+type ResponseParseError struct {
+	GrconClientError
+	// Response is the raw response that could not be parsed.
+	Response string
+}
+
+func newPlayerNotFoundError(player string) PlayerNotFoundError {
+	return PlayerNotFoundError{
+		GrconClientError: newGrconClientError(grcon.Read, fmt.Errorf("player not found: '%s'", player)),
+		Player:           player,
+	}
+}
+
+// PlayerNotFoundError occurres when the server does not know the player a command targeted.
+type PlayerNotFoundError struct {
+	GrconClientError
+	// Player is the name of the player that was not found.
+	Player string
+}
+
+func newUnknownCommandError(cmd, response string) UnknownCommandError {
+	return UnknownCommandError{
+		GrconClientError: newGrconClientError(grcon.Read, fmt.Errorf("unknown command '%s': %s", cmd, response)),
+		Command:          cmd,
+		Response:         response,
+	}
+}
+
+// UnknownCommandError occurres when the server does not know or could not parse a command.
+type UnknownCommandError struct {
+	GrconClientError
+	// Command is the command that was sent.
+	Command string
+	// Response is the error message of the server.
+	Response string
+}
+
+func newInvalidArgumentError(msg string) InvalidArgumentError {
+	return InvalidArgumentError{
+		newGrconClientError(grcon.Write, fmt.Errorf("invalid argument: %s", msg)),
+	}
+}
+
+// InvalidArgumentError occurres when an argument can not be sent to the server.
+type InvalidArgumentError struct {
+	GrconClientError
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// FactorioAchievementsPrompt is the response Factorio sends instead of executing
+// the first Lua command of a save that still has achievements enabled.
+const FactorioAchievementsPrompt = "Using Lua console commands will disable achievements, please repeat the command to proceed."
+
+// factorioLuaErrorPrefix is prepended by Factorio to the message of a failed Lua command.
+const factorioLuaErrorPrefix = "Cannot execute command. Error: "
+
+// NewFactorioClient is a constructor for the FactorioClient struct.
+// The util.GenerateRequestId can be used as idGenFunc.
+func NewFactorioClient(r util.RemoteConsole, idGenFunc func() grcon.PacketId) FactorioClient {
+	return FactorioClient{RemoteConsole: r, IdGenFunc: idGenFunc}
+}
+
+// FactorioClient is a wrapper for a RemoteConsole that provides some utility functions.
+// It simplifies the interaction with a remote console of a Factorio server.
+//
+// Factorio answers every command with exactly one packet and does not mirror
+// empty SERVERDATA_RESPONSE_VALUE packets, which is why no delimiter packet is used.
+type FactorioClient struct {
+	// RemoteConsole is the console to use for the interactions.
+	util.RemoteConsole
+	// IdGenFunc is the function to use to generate ids.
+	IdGenFunc func() grcon.PacketId
+}
+
+// Auth should be used to authenticate the connection.
+// Factorio does not send an empty response value packet before the auth response.
+//
+// It can return following errors:
+//	- InvalidResponseTypeError
+//	- ResponseIdMismatchError
+//	- AuthFailedError
+func (fc FactorioClient) Auth(password string) error {
+	reqID := fc.IdGenFunc()
+	err := fc.Write(grcon.Packet{Id: reqID, Type: grcon.SERVERDATA_AUTH, Body: []byte(password)})
+	if err != nil {
+		return err
+	}
+
+	packet, err := fc.Read()
+	if err != nil {
+		return err
+	}
+	if packet.Type != grcon.SERVERDATA_AUTH_RESPONSE {
+		return newInvalidResponseTypeError(grcon.SERVERDATA_AUTH_RESPONSE, packet.Type)
+	}
+	if packet.Id == -1 {
+		return newAuthFailedError()
+	}
+	if packet.Id != reqID {
+		return newResponseIdMismatchError(reqID, packet.Id)
+	}
+
+	return nil
+}
+
+// Exec executes the command on the given RemoteConsole implementation and
+// waits till the response is read returns it.
+//
+// If Factorio answers with the FactorioAchievementsPrompt the command gets repeated once,
+// as requested by the server. Be aware that this disables the achievements for the running save.
+//
+// Errors:
+// Returns all errors returned from the Write and Read methode from the RemoteConsole implementation.
+// Can also return an InvalidResponseTypeError if the response is not of the type
+// grcon.SERVERDATA_RESPONSE_VALUE.
+func (fc FactorioClient) Exec(cmd string) ([]byte, error) {
+	response, err := fc.exec(cmd)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	if strings.TrimSpace(string(response)) == FactorioAchievementsPrompt {
+		return fc.exec(cmd)
+	}
+
+	return response, nil
+}
+
+// exec writes a single command packet and reads the one response packet Factorio sends.
+func (fc FactorioClient) exec(cmd string) ([]byte, error) {
+	cmdPacket := grcon.Packet{
+		Id:   fc.IdGenFunc(),
+		Type: grcon.SERVERDATA_EXECCOMMAND,
+		Body: []byte(cmd),
+	}
+	err := fc.Write(cmdPacket)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	packet, err := fc.Read()
+	if err != nil {
+		return []byte{}, err
+	}
+	if packet.Type != grcon.SERVERDATA_RESPONSE_VALUE {
+		return []byte{}, newInvalidResponseTypeError(grcon.SERVERDATA_RESPONSE_VALUE, packet.Type)
+	}
+	if packet.Id != cmdPacket.Id {
+		return []byte{}, newResponseIdMismatchError(cmdPacket.Id, packet.Id)
+	}
+
+	return packet.Body, nil
+}
+
+// Lua runs the Lua script with /silent-command and returns everything the script
+// printed with rcon.print.
+// The script is not echoed to the chat of the players.
+//
+// Errors:
+// Returns all errors from Exec and a LuaError if the script could not be executed.
+func (fc FactorioClient) Lua(script string) ([]byte, error) {
+	response, err := fc.Exec("/silent-command " + script)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	trimmed := bytes.TrimSpace(response)
+	if bytes.HasPrefix(trimmed, []byte(factorioLuaErrorPrefix)) {
+		return []byte{}, newLuaError(string(bytes.TrimPrefix(trimmed, []byte(factorioLuaErrorPrefix))))
+	}
+
+	return response, nil
+}
+
+// LuaJSON evaluates the Lua expression, serializes the resulting table with
+// game.table_to_json and decodes the JSON into v.
+//
+// The expression has to evaluate to a table, e.g.
+// "game.forces.player.item_production_statistics.input_counts".
+// More complex logic can be wrapped into an anonymous function: "(function() ... end)()".
+//
+// Errors:
+// Returns all errors from Lua and a ResponseParseError if the output is not valid JSON for v.
+func (fc FactorioClient) LuaJSON(expr string, v interface{}) error {
+	response, err := fc.Lua("rcon.print(game.table_to_json(" + expr + "))")
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(bytes.TrimSpace(response), v)
+	if err != nil {
+		return newResponseParseError(string(response), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package client_test
+
+import (
+	"log"
+	"net"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/util"
+)
+
+func ExampleNewFactorioClient() {
+	conn, err := net.Dial("tcp", "127.0.0.1:27015")
+	if err != nil {
+		log.Fatalf("connection failed: %s", err.Error())
+	}
+	defer conn.Close()
+
+	remoteConsole := grcon.NewRemoteConsole(conn)
+
+	// the returned FactorioClient can now be used.
+	// It will use the utility function to generate ids
+	_ = client.NewFactorioClient(remoteConsole, util.GenerateRequestId)
+}
+
+func ExampleFactorioClient_Lua() {
+	conn, err := net.Dial("tcp", "127.0.0.1:27015")
+	if err != nil {
+		log.Fatalf("connection failed: %s", err.Error())
+	}
+	defer conn.Close()
+
+	factorioClient := client.NewFactorioClient(grcon.NewRemoteConsole(conn), util.GenerateRequestId)
+
+	err = factorioClient.Auth("password")
+	if err != nil {
+		log.Fatalf("authentication failed: %s", err.Error())
+	}
+
+	result, err := factorioClient.Lua("rcon.print(game.tick)")
+	if err != nil {
+		log.Fatalf("failed to retrive the current tick: %s", err.Error())
+	}
+
+	log.Println(string(result))
+}
+
+func ExampleFactorioClient_LuaJSON() {
+	conn, err := net.Dial("tcp", "127.0.0.1:27015")
+	if err != nil {
+		log.Fatalf("connection failed: %s", err.Error())
+	}
+	defer conn.Close()
+
+	factorioClient := client.NewFactorioClient(grcon.NewRemoteConsole(conn), util.GenerateRequestId)
+
+	err = factorioClient.Auth("password")
+	if err != nil {
+		log.Fatalf("authentication failed: %s", err.Error())
+	}
+
+	var produced map[string]float64
+	err = factorioClient.LuaJSON("game.forces.player.item_production_statistics.input_counts", &produced)
+	if err != nil {
+		log.Fatalf("failed to retrive the production statistics: %s", err.Error())
+	}
+
+	log.Printf("iron plates produced: %.0f", produced["iron-plate"])
+}
@@ -0,0 +1,203 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+)
+
+func TestFactorioClient_Auth(t *testing.T) {
+	t.Run("successful auth", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1}}
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: 1, Type: grcon.SERVERDATA_AUTH_RESPONSE, Body: []byte("")},
+		}}
+		factorioClient := client.FactorioClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+		err := factorioClient.Auth("foo")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+	})
+
+	t.Run("auth failed", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1}}
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: -1, Type: grcon.SERVERDATA_AUTH_RESPONSE, Body: []byte("")},
+		}}
+		factorioClient := client.FactorioClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+		err := factorioClient.Auth("foo")
+
+		_, ok := err.(client.AuthFailedError)
+		if !ok {
+			t.Errorf("expected: AuthFailedError\ngot: %T\n", err)
+		}
+	})
+}
+
+func TestFactorioClient_Exec(t *testing.T) {
+	t.Run("successful execution", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1}}
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("bar")},
+		}}
+		factorioClient := client.FactorioClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+		got, err := factorioClient.Exec("foo")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		if string(got) != "bar" {
+			t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", "bar", string(got))
+		}
+		if len(mock.Out) != 1 {
+			t.Errorf("expected 1 written packet but got %d\n", len(mock.Out))
+		}
+	})
+
+	t.Run("repeat command after achievements prompt", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1, 2}}
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte(client.FactorioAchievementsPrompt + "\n")},
+			{Id: 2, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("bar")},
+		}}
+		factorioClient := client.FactorioClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+		got, err := factorioClient.Exec("/c foo")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		if string(got) != "bar" {
+			t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", "bar", string(got))
+		}
+		if len(mock.Out) != 2 {
+			t.Fatalf("expected 2 written packets but got %d\n", len(mock.Out))
+		}
+		if string(mock.Out[1].Body) != "/c foo" {
+			t.Errorf("repeated command did not match:\nexpected: %s\ngot: %s\n", "/c foo", string(mock.Out[1].Body))
+		}
+	})
+
+	t.Run("id response missmatch type error", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1}}
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: 3, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("bar")},
+		}}
+		factorioClient := client.FactorioClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+
+		_, err := factorioClient.Exec("foo")
+		_, ok := err.(client.ResponseIdMismatchError)
+		if !ok {
+			t.Errorf("expected: ResponseIdMismatchError\ngot: %T\n", err)
+		}
+	})
+}
+
+func TestFactorioClient_Lua(t *testing.T) {
+	t.Run("uses silent command", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1}}
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("42\n")},
+		}}
+		factorioClient := client.FactorioClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+		got, err := factorioClient.Lua("rcon.print(42)")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		if string(got) != "42\n" {
+			t.Errorf("response did not match:\nexpected: %q\ngot: %q\n", "42\n", string(got))
+		}
+		if string(mock.Out[0].Body) != "/silent-command rcon.print(42)" {
+			t.Errorf("command did not match:\ngot: %s\n", string(mock.Out[0].Body))
+		}
+	})
+
+	t.Run("lua error", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1}}
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("Cannot execute command. Error: [string \"foo()\"]:1: attempt to call global 'foo' (a nil value)\n")},
+		}}
+		factorioClient := client.FactorioClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+		_, err := factorioClient.Lua("foo()")
+
+		luaErr, ok := err.(client.LuaError)
+		if !ok {
+			t.Fatalf("expected: LuaError\ngot: %T\n", err)
+		}
+		expected := "[string \"foo()\"]:1: attempt to call global 'foo' (a nil value)"
+		if luaErr.Message != expected {
+			t.Errorf("message did not match:\nexpected: %s\ngot: %s\n", expected, luaErr.Message)
+		}
+	})
+}
+
+func TestFactorioClient_LuaJSON(t *testing.T) {
+	t.Run("decode table", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1}}
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("{\"iron-plate\":120,\"copper-plate\":80}\n")},
+		}}
+		factorioClient := client.FactorioClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+
+		var got map[string]int
+		err := factorioClient.LuaJSON("game.forces.player.item_production_statistics.input_counts", &got)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		if got["iron-plate"] != 120 || got["copper-plate"] != 80 {
+			t.Errorf("decoded table did not match: %+v\n", got)
+		}
+		expected := "/silent-command rcon.print(game.table_to_json(game.forces.player.item_production_statistics.input_counts))"
+		if string(mock.Out[0].Body) != expected {
+			t.Errorf("command did not match:\nexpected: %s\ngot: %s\n", expected, string(mock.Out[0].Body))
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1}}
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("")},
+		}}
+		factorioClient := client.FactorioClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+
+		var got map[string]int
+		err := factorioClient.LuaJSON("nil", &got)
+		if _, ok := err.(client.ResponseParseError); !ok {
+			t.Errorf("expected: ResponseParseError\ngot: %T\n", err)
+		}
+	})
+}
@@ -5,8 +5,16 @@ import (
 	"github.com/hamburghammer/grcon/util"
 )
 
+// minecraftFragmentSize is the number of characters minecraft servers split long responses into.
+const minecraftFragmentSize = 4096
+
+// MinecraftMaxPacket is the packet size a grcon.RemoteConsole has to read for the fragments of long responses.
+// The fragments have 4096 characters with up to 4 bytes each and are longer than the grcon.MaxPacket.
+const MinecraftMaxPacket = 4*minecraftFragmentSize + 10
+
 // NewMinecraftClient is a constructor for the MinecraftClient struct.
 // The util.GenerateNewId can be used as idGenFunc.
+// Long responses can only be read with a console of grcon.NewRemoteConsoleSize and the MinecraftMaxPacket.
 func NewMinecraftClient(r util.RemoteConsole, idGenFunc func() grcon.PacketId) MinecraftClient {
 	return MinecraftClient{RemoteConsole: r, IdGenFunc: idGenFunc}
 }
@@ -53,6 +61,11 @@ func (sc MinecraftClient) Auth(password string) error {
 // Exec executes the command on the given RemoteConsole implementation and
 // waits till the response is read returns it.
 //
+// Minecraft servers split long responses into fragments of 4096 characters without an end marker.
+// After a fragment of at least 4096 bytes Exec sends a packet of an unknown type and reads the fragments
+// until the server answers it, which happens after the rest of the response.
+// The RemoteConsole has to read packets up to the MinecraftMaxPacket for such responses.
+//
 // Errors:
 // Returns all errors returned from the Write and Read methode from the RemoteConsole implementation.
 // Can also return an InvalidResponseTypeError if the response is not of the type
@@ -79,6 +92,42 @@ func (sc MinecraftClient) Exec(cmd string) ([]byte, error) {
 	if packet.Id != cmdPacket.Id {
 		return []byte{}, newResponseIdMismatchError(cmdPacket.Id, packet.Id)
 	}
+	if len(packet.Body) < minecraftFragmentSize {
+		return packet.Body, nil
+	}
+
+	return sc.readFragments(cmdPacket.Id, packet.Body)
+}
+
+// readFragments reads the fragments of a long response after the first one.
+func (sc MinecraftClient) readFragments(id grcon.PacketId, response []byte) ([]byte, error) {
+	// the server answers packets of unknown types with "Unknown request <type>".
+	markerPacket := grcon.Packet{
+		Id:   sc.IdGenFunc(),
+		Type: grcon.SERVERDATA_RESPONSE_VALUE,
+		Body: []byte{},
+	}
+	err := sc.Write(markerPacket)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	for {
+		packet, err := sc.Read()
+		if err != nil {
+			return []byte{}, err
+		}
+		if packet.Type != grcon.SERVERDATA_RESPONSE_VALUE {
+			return []byte{}, newInvalidResponseTypeError(grcon.SERVERDATA_RESPONSE_VALUE, packet.Type)
+		}
 
-	return packet.Body, nil
+		switch packet.Id {
+		case id:
+			response = append(response, packet.Body...)
+		case markerPacket.Id:
+			return response, nil
+		default:
+			return []byte{}, newResponseIdMismatchError(id, packet.Id)
+		}
+	}
 }
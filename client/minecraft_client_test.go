@@ -1,6 +1,7 @@
 package client_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/hamburghammer/grcon"
@@ -171,6 +172,56 @@ func TestMinecraftClient_Exec(t *testing.T) {
 		}
 	})
 
+	t.Run("fragmented response", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1, 2}}
+		fragment := bytes.Repeat([]byte("a"), 4096)
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: fragment},
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("bar")},
+			{Id: 2, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("Unknown request 0")},
+		}}
+		minecraftClient := client.MinecraftClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+		got, err := minecraftClient.Exec("foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := append(append([]byte{}, fragment...), "bar"...)
+		if !bytes.Equal(got, expected) {
+			t.Errorf("response did not match:\nexpected: %d bytes\ngot: %d bytes\n", len(expected), len(got))
+		}
+		if len(mock.Out) != 2 || mock.Out[1].Id != 2 || mock.Out[1].Type != grcon.SERVERDATA_RESPONSE_VALUE {
+			t.Errorf("expected a marker packet after the command but got: %+v\n", mock.Out)
+		}
+	})
+
+	t.Run("fragmented response with multi-byte characters", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1, 2}}
+		// a fragment of 4096 characters is longer than 4096 bytes.
+		fragment := bytes.Repeat([]byte("é"), 4096)
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: fragment},
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("bär")},
+			{Id: 2, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("Unknown request 0")},
+		}}
+		minecraftClient := client.MinecraftClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+		got, err := minecraftClient.Exec("foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := append(append([]byte{}, fragment...), "bär"...)
+		if !bytes.Equal(got, expected) {
+			t.Errorf("response did not match:\nexpected: %d bytes\ngot: %d bytes\n", len(expected), len(got))
+		}
+	})
+
 	t.Run("invalid response type error", func(t *testing.T) {
 		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1, 2}}
 		mock := &MockRemoteConsole{In: []grcon.Packet{
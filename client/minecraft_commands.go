@@ -0,0 +1,411 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinecraftPlayer is an online or known player of a minecraft server.
+type MinecraftPlayer struct {
+	Name string
+	// UUID is only set if the server reported it.
+	UUID string
+}
+
+// MinecraftPlayerList is the parsed response of the list command.
+type MinecraftPlayerList struct {
+	Online  int
+	Max     int
+	Players []MinecraftPlayer
+}
+
+// MinecraftWeather is a weather type that can be set with the weather command.
+type MinecraftWeather string
+
+// Weather types supported by the weather command.
+const (
+	MinecraftWeatherClear   MinecraftWeather = "clear"
+	MinecraftWeatherRain    MinecraftWeather = "rain"
+	MinecraftWeatherThunder MinecraftWeather = "thunder"
+)
+
+var (
+	minecraftListRegex        = regexp.MustCompile(`^There are (\d+) of a max of (\d+) players online:(.*)$`)
+	minecraftLegacyListRegex  = regexp.MustCompile(`^There are (\d+)/(\d+) players online:(.*)$`)
+	minecraftListEntryRegex   = regexp.MustCompile(`^(\S+) \(([0-9a-fA-F-]{36})\)$`)
+	minecraftWhitelistRegex   = regexp.MustCompile(`^There (?:are|is) \d+ whitelisted player(?:s|\(s\))?: (.*)$`)
+	minecraftTimeRegex        = regexp.MustCompile(`^(?:The time is|Set the time to) (\d+)$`)
+	minecraftGameruleRegex    = regexp.MustCompile(`^Gamerule \S+ is (?:currently|now) set to: (.*)$`)
+	minecraftWorldBorderRegex = regexp.MustCompile(`^(?:The world border is currently|Set the world border to|Growing the world border to|Shrinking the world border to) ([0-9.]+) block(?:s|\(s\))? wide`)
+)
+
+// Responses of the vanilla server that indicate an error.
+const (
+	minecraftUnknownCommand   = "Unknown or incomplete command"
+	minecraftIncorrectArg     = "Incorrect argument for command"
+	minecraftNoPlayerFound    = "No player was found"
+	minecraftNoEntityFound    = "No entity was found"
+	minecraftPlayerNotExists  = "That player does not exist"
+	minecraftNothingChanged   = "Nothing changed."
+	minecraftAlreadyWhitelist = "Player is already whitelisted"
+	minecraftNotWhitelisted   = "Player is not whitelisted"
+	minecraftSaved            = "Saved the game"
+)
+
+// ParseMinecraftPlayerList parses the response of the list or "list uuids" command.
+// Both the current and the legacy ("There are 1/20 players online:") format are supported.
+//
+// Returns a ResponseParseError if the response has an unknown format.
+func ParseMinecraftPlayerList(response string) (MinecraftPlayerList, error) {
+	response = strings.TrimSpace(response)
+	matches := minecraftListRegex.FindStringSubmatch(response)
+	if matches == nil {
+		matches = minecraftLegacyListRegex.FindStringSubmatch(response)
+	}
+	if matches == nil {
+		return MinecraftPlayerList{}, newResponseParseError(response, errors.New("unknown player list format"))
+	}
+
+	online, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return MinecraftPlayerList{}, newResponseParseError(response, err)
+	}
+	max, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return MinecraftPlayerList{}, newResponseParseError(response, err)
+	}
+
+	list := MinecraftPlayerList{Online: online, Max: max, Players: make([]MinecraftPlayer, 0, online)}
+	for _, entry := range splitMinecraftNames(matches[3]) {
+		if entryMatches := minecraftListEntryRegex.FindStringSubmatch(entry); entryMatches != nil {
+			list.Players = append(list.Players, MinecraftPlayer{Name: entryMatches[1], UUID: entryMatches[2]})
+			continue
+		}
+		list.Players = append(list.Players, MinecraftPlayer{Name: entry})
+	}
+
+	return list, nil
+}
+
+// splitMinecraftNames splits a comma separated list of names and drops empty entries.
+func splitMinecraftNames(s string) []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// command executes the command and checks the response for the generic error responses.
+// The returned response is trimmed.
+func (sc MinecraftClient) command(cmd string, player string) (string, error) {
+	raw, err := sc.Exec(cmd)
+	if err != nil {
+		return "", err
+	}
+	response := strings.TrimSpace(string(raw))
+
+	switch {
+	case strings.HasPrefix(response, minecraftUnknownCommand), strings.HasPrefix(response, minecraftIncorrectArg):
+		return "", newUnknownCommandError(cmd, response)
+	case strings.HasPrefix(response, minecraftNoPlayerFound), strings.HasPrefix(response, minecraftNoEntityFound),
+		strings.HasPrefix(response, minecraftPlayerNotExists):
+		return "", newPlayerNotFoundError(player)
+	}
+
+	return response, nil
+}
+
+// expect executes the command and returns a ResponseParseError if the response does not start
+// with one of the expected prefixes.
+func (sc MinecraftClient) expect(cmd string, player string, prefixes ...string) error {
+	response, err := sc.command(cmd, player)
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(response, prefix) {
+			return nil
+		}
+	}
+
+	return newResponseParseError(response, fmt.Errorf("unexpected response to '%s'", cmd))
+}
+
+// ListPlayers returns the online players including their UUIDs.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) ListPlayers() (MinecraftPlayerList, error) {
+	response, err := sc.command("list uuids", "")
+	if err != nil {
+		return MinecraftPlayerList{}, err
+	}
+
+	return ParseMinecraftPlayerList(response)
+}
+
+// WhitelistAdd adds the player to the whitelist.
+// Adding an already whitelisted player is not an error.
+//
+// Errors:
+// Returns all errors from Exec, a PlayerNotFoundError, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) WhitelistAdd(player string) error {
+	return sc.expect("whitelist add "+player, player, "Added ", minecraftAlreadyWhitelist)
+}
+
+// WhitelistRemove removes the player from the whitelist.
+// Removing a player that is not whitelisted is not an error.
+//
+// Errors:
+// Returns all errors from Exec, a PlayerNotFoundError, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) WhitelistRemove(player string) error {
+	return sc.expect("whitelist remove "+player, player, "Removed ", minecraftNotWhitelisted)
+}
+
+// WhitelistList returns the names of all whitelisted players.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) WhitelistList() ([]string, error) {
+	response, err := sc.command("whitelist list", "")
+	if err != nil {
+		return nil, err
+	}
+	if response == "There are no whitelisted players" {
+		return []string{}, nil
+	}
+
+	matches := minecraftWhitelistRegex.FindStringSubmatch(response)
+	if matches == nil {
+		return nil, newResponseParseError(response, errors.New("unknown whitelist format"))
+	}
+
+	return splitMinecraftNames(matches[1]), nil
+}
+
+// Op makes the player a server operator.
+// Making an operator an operator again is not an error.
+//
+// Errors:
+// Returns all errors from Exec, a PlayerNotFoundError, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) Op(player string) error {
+	return sc.expect("op "+player, player, "Made ", minecraftNothingChanged)
+}
+
+// Deop revokes the operator status of the player.
+// Revoking the status of a player that is no operator is not an error.
+//
+// Errors:
+// Returns all errors from Exec, a PlayerNotFoundError, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) Deop(player string) error {
+	return sc.expect("deop "+player, player, "Made ", minecraftNothingChanged)
+}
+
+// Kick kicks the player from the server.
+// The default message of the server is used if the reason is empty.
+//
+// Errors:
+// Returns all errors from Exec, a PlayerNotFoundError, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) Kick(player, reason string) error {
+	return sc.expect(appendArg("kick "+player, reason), player, "Kicked ")
+}
+
+// Ban bans the player from the server.
+// The default message of the server is used if the reason is empty.
+// Banning an already banned player is not an error.
+//
+// Errors:
+// Returns all errors from Exec, a PlayerNotFoundError, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) Ban(player, reason string) error {
+	return sc.expect(appendArg("ban "+player, reason), player, "Banned ", minecraftNothingChanged)
+}
+
+// appendArg appends the argument to the command if it is not empty.
+func appendArg(cmd, arg string) string {
+	if arg == "" {
+		return cmd
+	}
+	return cmd + " " + arg
+}
+
+// SetTime sets the time of the day.
+// The value can be a number of ticks or one of: day, night, noon and midnight.
+// Returns the time in ticks it was set to.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) SetTime(value string) (int, error) {
+	return sc.time("time set " + value)
+}
+
+// QueryTime returns the time for the query which can be one of: daytime, gametime and day.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) QueryTime(query string) (int, error) {
+	return sc.time("time query " + query)
+}
+
+// time executes a time command and parses the time from the response.
+func (sc MinecraftClient) time(cmd string) (int, error) {
+	response, err := sc.command(cmd, "")
+	if err != nil {
+		return 0, err
+	}
+
+	matches := minecraftTimeRegex.FindStringSubmatch(response)
+	if matches == nil {
+		return 0, newResponseParseError(response, errors.New("unknown time format"))
+	}
+
+	ticks, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, newResponseParseError(response, err)
+	}
+
+	return ticks, nil
+}
+
+// SetWeather changes the weather.
+// A duration of zero lets the server choose a random duration.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) SetWeather(weather MinecraftWeather, duration time.Duration) error {
+	cmd := "weather " + string(weather)
+	if duration > 0 {
+		cmd += " " + strconv.Itoa(int(duration.Seconds()))
+	}
+	return sc.expect(cmd, "", "Set the weather to ", "Changing to ")
+}
+
+// GameRule returns the current value of the game rule.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) GameRule(rule string) (string, error) {
+	return sc.gameRule("gamerule " + rule)
+}
+
+// SetGameRule sets the game rule to the value.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) SetGameRule(rule, value string) error {
+	_, err := sc.gameRule("gamerule " + rule + " " + value)
+	return err
+}
+
+// gameRule executes a gamerule command and parses the value from the response.
+func (sc MinecraftClient) gameRule(cmd string) (string, error) {
+	response, err := sc.command(cmd, "")
+	if err != nil {
+		return "", err
+	}
+
+	matches := minecraftGameruleRegex.FindStringSubmatch(response)
+	if matches == nil {
+		return "", newResponseParseError(response, errors.New("unknown gamerule format"))
+	}
+
+	return matches[1], nil
+}
+
+// WorldBorder returns the current diameter of the world border in blocks.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) WorldBorder() (float64, error) {
+	return sc.worldBorder("worldborder get")
+}
+
+// SetWorldBorder sets the diameter of the world border in blocks.
+// The border moves to the new size over the given duration, zero changes it immediately.
+// Setting the border to its current size is not an error.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) SetWorldBorder(diameter float64, duration time.Duration) error {
+	cmd := "worldborder set " + strconv.FormatFloat(diameter, 'f', -1, 64)
+	if duration > 0 {
+		cmd += " " + strconv.Itoa(int(duration.Seconds()))
+	}
+
+	response, err := sc.command(cmd, "")
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(response, minecraftNothingChanged) {
+		return nil
+	}
+	if !minecraftWorldBorderRegex.MatchString(response) {
+		return newResponseParseError(response, errors.New("unknown worldborder format"))
+	}
+
+	return nil
+}
+
+// worldBorder executes a worldborder command and parses the diameter from the response.
+func (sc MinecraftClient) worldBorder(cmd string) (float64, error) {
+	response, err := sc.command(cmd, "")
+	if err != nil {
+		return 0, err
+	}
+
+	matches := minecraftWorldBorderRegex.FindStringSubmatch(response)
+	if matches == nil {
+		return 0, newResponseParseError(response, errors.New("unknown worldborder format"))
+	}
+
+	diameter, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, newResponseParseError(response, err)
+	}
+
+	return diameter, nil
+}
+
+// SaveAll saves the world to the disk with "save-all flush" and
+// waits till the server confirmed it.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError
+// if the server did not confirm the save.
+func (sc MinecraftClient) SaveAll() error {
+	response, err := sc.command("save-all flush", "")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(response, minecraftSaved) {
+		return newResponseParseError(response, errors.New("save was not confirmed"))
+	}
+
+	return nil
+}
+
+// SaveOff disables the automatic saving of the world, e.g. for a backup.
+// Disabling it again is not an error.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) SaveOff() error {
+	return sc.expect("save-off", "", "Automatic saving is now disabled", "Saving is already turned off")
+}
+
+// SaveOn enables the automatic saving of the world again.
+// Enabling it again is not an error.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError.
+func (sc MinecraftClient) SaveOn() error {
+	return sc.expect("save-on", "", "Automatic saving is now enabled", "Saving is already turned on")
+}
@@ -0,0 +1,280 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+)
+
+// newMinecraftCommandClient returns a MinecraftClient that answers the first command with the response.
+func newMinecraftCommandClient(response string) (client.MinecraftClient, *MockRemoteConsole) {
+	mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1}}
+	mock := &MockRemoteConsole{In: []grcon.Packet{
+		{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte(response)},
+	}}
+	return client.MinecraftClient{RemoteConsole: mock, IdGenFunc: mockIdGen.GetNextId}, mock
+}
+
+func TestParseMinecraftPlayerList(t *testing.T) {
+	t.Run("players with uuids", func(t *testing.T) {
+		got, err := client.ParseMinecraftPlayerList("There are 2 of a max of 20 players online: Steve (8667ba71-b85a-4004-af54-457a9734eed7), Alex (ec561538-f3fd-461d-aff5-086b22154bce)")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		if got.Online != 2 || got.Max != 20 {
+			t.Errorf("counts did not match:\nexpected: 2/20\ngot: %d/%d\n", got.Online, got.Max)
+		}
+		if len(got.Players) != 2 {
+			t.Fatalf("expected 2 players but got %d\n", len(got.Players))
+		}
+		if got.Players[1].Name != "Alex" || got.Players[1].UUID != "ec561538-f3fd-461d-aff5-086b22154bce" {
+			t.Errorf("player did not match: %+v\n", got.Players[1])
+		}
+	})
+
+	t.Run("no players", func(t *testing.T) {
+		got, err := client.ParseMinecraftPlayerList("There are 0 of a max of 20 players online: ")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if len(got.Players) != 0 {
+			t.Errorf("expected no players but got %d\n", len(got.Players))
+		}
+	})
+
+	t.Run("legacy format", func(t *testing.T) {
+		got, err := client.ParseMinecraftPlayerList("There are 1/10 players online:Steve")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if got.Max != 10 || len(got.Players) != 1 || got.Players[0].Name != "Steve" {
+			t.Errorf("list did not match: %+v\n", got)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := client.ParseMinecraftPlayerList("foo")
+		if _, ok := err.(client.ResponseParseError); !ok {
+			t.Errorf("expected: ResponseParseError\ngot: %T\n", err)
+		}
+	})
+}
+
+func TestMinecraftClient_ListPlayers(t *testing.T) {
+	minecraftClient, mock := newMinecraftCommandClient("There are 1 of a max of 20 players online: Steve (8667ba71-b85a-4004-af54-457a9734eed7)")
+
+	got, err := minecraftClient.ListPlayers()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if string(mock.Out[0].Body) != "list uuids" {
+		t.Errorf("command did not match:\nexpected: %s\ngot: %s\n", "list uuids", string(mock.Out[0].Body))
+	}
+	if len(got.Players) != 1 || got.Players[0].UUID != "8667ba71-b85a-4004-af54-457a9734eed7" {
+		t.Errorf("list did not match: %+v\n", got)
+	}
+}
+
+func TestMinecraftClient_Whitelist(t *testing.T) {
+	t.Run("add", func(t *testing.T) {
+		minecraftClient, mock := newMinecraftCommandClient("Added Steve to the whitelist")
+		err := minecraftClient.WhitelistAdd("Steve")
+		if err != nil {
+			t.Error(err)
+		}
+		if string(mock.Out[0].Body) != "whitelist add Steve" {
+			t.Errorf("command did not match: %s\n", string(mock.Out[0].Body))
+		}
+	})
+
+	t.Run("add already whitelisted", func(t *testing.T) {
+		minecraftClient, _ := newMinecraftCommandClient("Player is already whitelisted")
+		err := minecraftClient.WhitelistAdd("Steve")
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("add unknown player", func(t *testing.T) {
+		minecraftClient, _ := newMinecraftCommandClient("That player does not exist")
+		err := minecraftClient.WhitelistAdd("Steve")
+		notFound, ok := err.(client.PlayerNotFoundError)
+		if !ok {
+			t.Fatalf("expected: PlayerNotFoundError\ngot: %T\n", err)
+		}
+		if notFound.Player != "Steve" {
+			t.Errorf("player did not match: %s\n", notFound.Player)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		minecraftClient, _ := newMinecraftCommandClient("There are 2 whitelisted players: Steve, Alex")
+		got, err := minecraftClient.WhitelistList()
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if len(got) != 2 || got[0] != "Steve" || got[1] != "Alex" {
+			t.Errorf("whitelist did not match: %v\n", got)
+		}
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		minecraftClient, _ := newMinecraftCommandClient("There are no whitelisted players")
+		got, err := minecraftClient.WhitelistList()
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if len(got) != 0 {
+			t.Errorf("expected empty whitelist but got: %v\n", got)
+		}
+	})
+}
+
+func TestMinecraftClient_Kick(t *testing.T) {
+	t.Run("with reason", func(t *testing.T) {
+		minecraftClient, mock := newMinecraftCommandClient("Kicked Steve: cheating")
+		err := minecraftClient.Kick("Steve", "cheating")
+		if err != nil {
+			t.Error(err)
+		}
+		if string(mock.Out[0].Body) != "kick Steve cheating" {
+			t.Errorf("command did not match: %s\n", string(mock.Out[0].Body))
+		}
+	})
+
+	t.Run("player not online", func(t *testing.T) {
+		minecraftClient, _ := newMinecraftCommandClient("No player was found")
+		err := minecraftClient.Kick("Steve", "")
+		if _, ok := err.(client.PlayerNotFoundError); !ok {
+			t.Errorf("expected: PlayerNotFoundError\ngot: %T\n", err)
+		}
+	})
+}
+
+func TestMinecraftClient_UnknownCommand(t *testing.T) {
+	minecraftClient, _ := newMinecraftCommandClient("Unknown or incomplete command, see below for error<--[HERE]")
+	err := minecraftClient.Op("Steve")
+	if _, ok := err.(client.UnknownCommandError); !ok {
+		t.Errorf("expected: UnknownCommandError\ngot: %T\n", err)
+	}
+}
+
+func TestMinecraftClient_Time(t *testing.T) {
+	minecraftClient, _ := newMinecraftCommandClient("Set the time to 1000")
+	got, err := minecraftClient.SetTime("day")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if got != 1000 {
+		t.Errorf("time did not match:\nexpected: %d\ngot: %d\n", 1000, got)
+	}
+}
+
+func TestMinecraftClient_SetWeather(t *testing.T) {
+	minecraftClient, mock := newMinecraftCommandClient("Set the weather to rain")
+	err := minecraftClient.SetWeather(client.MinecraftWeatherRain, 5*time.Minute)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(mock.Out[0].Body) != "weather rain 300" {
+		t.Errorf("command did not match: %s\n", string(mock.Out[0].Body))
+	}
+}
+
+func TestMinecraftClient_GameRule(t *testing.T) {
+	minecraftClient, _ := newMinecraftCommandClient("Gamerule keepInventory is currently set to: false")
+	got, err := minecraftClient.GameRule("keepInventory")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if got != "false" {
+		t.Errorf("value did not match:\nexpected: %s\ngot: %s\n", "false", got)
+	}
+}
+
+func TestMinecraftClient_WorldBorder(t *testing.T) {
+	minecraftClient, _ := newMinecraftCommandClient("The world border is currently 59999968 block(s) wide")
+	_, err := minecraftClient.WorldBorder()
+	if err != nil {
+		t.Error(err)
+	}
+
+	minecraftClient, _ = newMinecraftCommandClient("The world border is currently 1000 blocks wide")
+	got, err := minecraftClient.WorldBorder()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if got != 1000 {
+		t.Errorf("diameter did not match:\nexpected: %d\ngot: %f\n", 1000, got)
+	}
+}
+
+func TestMinecraftClient_SaveAll(t *testing.T) {
+	t.Run("confirmed", func(t *testing.T) {
+		minecraftClient, mock := newMinecraftCommandClient("Saving the game (this may take a moment!)Saved the game")
+		err := minecraftClient.SaveAll()
+		if err != nil {
+			t.Error(err)
+		}
+		if string(mock.Out[0].Body) != "save-all flush" {
+			t.Errorf("command did not match: %s\n", string(mock.Out[0].Body))
+		}
+	})
+
+	t.Run("not confirmed", func(t *testing.T) {
+		minecraftClient, _ := newMinecraftCommandClient("Saving failed")
+		err := minecraftClient.SaveAll()
+		if _, ok := err.(client.ResponseParseError); !ok {
+			t.Errorf("expected: ResponseParseError\ngot: %T\n", err)
+		}
+	})
+}
+
+func TestMinecraftClient_SaveOffOn(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		save     func(c client.MinecraftClient) error
+		command  string
+		fails    bool
+	}{
+		{name: "off", response: "Automatic saving is now disabled", save: client.MinecraftClient.SaveOff, command: "save-off"},
+		{name: "already off", response: "Saving is already turned off", save: client.MinecraftClient.SaveOff, command: "save-off"},
+		{name: "on", response: "Automatic saving is now enabled", save: client.MinecraftClient.SaveOn, command: "save-on"},
+		{name: "already on", response: "Saving is already turned on", save: client.MinecraftClient.SaveOn, command: "save-on"},
+		{name: "unexpected response", response: "Saving failed", save: client.MinecraftClient.SaveOn, command: "save-on", fails: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			minecraftClient, mock := newMinecraftCommandClient(test.response)
+			err := test.save(minecraftClient)
+			if test.fails {
+				if _, ok := err.(client.ResponseParseError); !ok {
+					t.Errorf("expected: ResponseParseError\ngot: %T\n", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Error(err)
+			}
+			if string(mock.Out[0].Body) != test.command {
+				t.Errorf("command did not match:\nexpected: %s\ngot: %s\n", test.command, string(mock.Out[0].Body))
+			}
+		})
+	}
+}
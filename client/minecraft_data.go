@@ -0,0 +1,54 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/hamburghammer/grcon/snbt"
+)
+
+var minecraftDataRegex = regexp.MustCompile(`(?s)^.+? has the following (?:entity|block|storage) data: (.*)$`)
+
+// DataGetEntity returns the NBT data of the entity with "data get entity".
+// The target can be a player name, a UUID or a selector that matches exactly one entity.
+// An empty path returns all data of the entity. Data that is longer than a single packet,
+// like the inventory of a player, is read from all fragments of the response.
+//
+// Errors:
+// Returns all errors from Exec, a PlayerNotFoundError if no entity matched the target,
+// an UnknownCommandError or a ResponseParseError if the path did not match or the data is no valid SNBT.
+func (sc MinecraftClient) DataGetEntity(target, path string) (snbt.Tag, error) {
+	return sc.dataGet(appendArg("data get entity "+target, path), target)
+}
+
+// DataGetBlock returns the NBT data of the block entity at the coordinates with "data get block".
+// An empty path returns all data of the block entity. Data that is longer than a single packet,
+// like the items of a chest, is read from all fragments of the response.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError or a ResponseParseError if the block is no block entity,
+// the path did not match or the data is no valid SNBT.
+func (sc MinecraftClient) DataGetBlock(x, y, z int, path string) (snbt.Tag, error) {
+	return sc.dataGet(appendArg(fmt.Sprintf("data get block %d %d %d", x, y, z), path), "")
+}
+
+// dataGet executes a data get command and parses the SNBT of the response.
+func (sc MinecraftClient) dataGet(cmd, target string) (snbt.Tag, error) {
+	response, err := sc.command(cmd, target)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := minecraftDataRegex.FindStringSubmatch(response)
+	if matches == nil {
+		return nil, newResponseParseError(response, errors.New("unknown data format"))
+	}
+
+	tag, err := snbt.Parse(matches[1])
+	if err != nil {
+		return nil, newResponseParseError(response, err)
+	}
+
+	return tag, nil
+}
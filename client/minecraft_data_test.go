@@ -0,0 +1,97 @@
+package client_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/snbt"
+)
+
+func TestMinecraftClient_DataGetEntity(t *testing.T) {
+	t.Run("decode player position", func(t *testing.T) {
+		minecraftClient, mock := newMinecraftCommandClient("Steve has the following entity data: [0.5d, 64.0d, -12.3d]")
+
+		tag, err := minecraftClient.DataGetEntity("Steve", "Pos")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if string(mock.Out[0].Body) != "data get entity Steve Pos" {
+			t.Errorf("command did not match: %s\n", string(mock.Out[0].Body))
+		}
+
+		var pos [3]float64
+		err = snbt.Decode(tag, &pos)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if pos != [3]float64{0.5, 64, -12.3} {
+			t.Errorf("position did not match: %v\n", pos)
+		}
+	})
+
+	t.Run("fragmented data", func(t *testing.T) {
+		items := make([]string, 1000)
+		for i := range items {
+			items[i] = `{Count: 1b, id: "minecraft:stone"}`
+		}
+		response := "Steve has the following entity data: [" + strings.Join(items, ", ") + "]"
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1, 2}}
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte(response[:4096])},
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte(response[4096:8192])},
+			{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte(response[8192:])},
+			{Id: 2, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("Unknown request 0")},
+		}}
+		minecraftClient := client.MinecraftClient{RemoteConsole: mock, IdGenFunc: mockIdGen.GetNextId}
+
+		tag, err := minecraftClient.DataGetEntity("Steve", "Inventory")
+		if err != nil {
+			t.Fatal(err)
+		}
+		list, ok := tag.(snbt.List)
+		if !ok || len(list) != len(items) {
+			t.Errorf("expected a list with %d items but got %T\n", len(items), tag)
+		}
+	})
+
+	t.Run("entity not found", func(t *testing.T) {
+		minecraftClient, _ := newMinecraftCommandClient("No entity was found")
+		_, err := minecraftClient.DataGetEntity("Steve", "")
+		if _, ok := err.(client.PlayerNotFoundError); !ok {
+			t.Errorf("expected: PlayerNotFoundError\ngot: %T\n", err)
+		}
+	})
+
+	t.Run("path not found", func(t *testing.T) {
+		minecraftClient, _ := newMinecraftCommandClient("Found no elements matching Foo")
+		_, err := minecraftClient.DataGetEntity("Steve", "Foo")
+		if _, ok := err.(client.ResponseParseError); !ok {
+			t.Errorf("expected: ResponseParseError\ngot: %T\n", err)
+		}
+	})
+}
+
+func TestMinecraftClient_DataGetBlock(t *testing.T) {
+	minecraftClient, mock := newMinecraftCommandClient(`1, 64, -3 has the following block data: {Items: [{Count: 3b, Slot: 0b, id: "minecraft:diamond"}], id: "minecraft:chest"}`)
+
+	tag, err := minecraftClient.DataGetBlock(1, 64, -3, "")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if string(mock.Out[0].Body) != "data get block 1 64 -3" {
+		t.Errorf("command did not match: %s\n", string(mock.Out[0].Body))
+	}
+
+	compound, ok := tag.(snbt.Compound)
+	if !ok {
+		t.Fatalf("expected compound but got %T\n", tag)
+	}
+	if compound["id"] != snbt.String("minecraft:chest") {
+		t.Errorf("id did not match: %v\n", compound["id"])
+	}
+}
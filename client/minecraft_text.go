@@ -0,0 +1,34 @@
+package client
+
+import "github.com/hamburghammer/grcon/text"
+
+// MinecraftTitlePosition is the position where the title command shows a text.
+type MinecraftTitlePosition string
+
+// Positions of the title command.
+const (
+	MinecraftTitle     MinecraftTitlePosition = "title"
+	MinecraftSubtitle  MinecraftTitlePosition = "subtitle"
+	MinecraftActionbar MinecraftTitlePosition = "actionbar"
+)
+
+// Tellraw sends the text component as chat message to all players matched by the selector.
+// The selector can be a player name or a target selector like @a.
+//
+// Errors:
+// Returns all errors from Exec, a PlayerNotFoundError if no player matched the selector or
+// an UnknownCommandError.
+func (sc MinecraftClient) Tellraw(selector string, component text.Component) error {
+	_, err := sc.command("tellraw "+selector+" "+component.JSON(), selector)
+	return err
+}
+
+// Title shows the text component on the screen of all players matched by the selector.
+//
+// Errors:
+// Returns all errors from Exec, a PlayerNotFoundError if no player matched the selector or
+// an UnknownCommandError.
+func (sc MinecraftClient) Title(selector string, position MinecraftTitlePosition, component text.Component) error {
+	_, err := sc.command("title "+selector+" "+string(position)+" "+component.JSON(), selector)
+	return err
+}
@@ -0,0 +1,49 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/text"
+)
+
+func TestMinecraftClient_Tellraw(t *testing.T) {
+	t.Run("escaped component", func(t *testing.T) {
+		minecraftClient, mock := newMinecraftCommandClient("")
+
+		err := minecraftClient.Tellraw("@a", text.Text(`Welcome "Steve"`).WithColor(text.Gold))
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		expect := `tellraw @a {"text":"Welcome \"Steve\"","color":"gold"}`
+		if string(mock.Out[0].Body) != expect {
+			t.Errorf("command did not match:\nexpected: %s\ngot: %s\n", expect, string(mock.Out[0].Body))
+		}
+	})
+
+	t.Run("player not found", func(t *testing.T) {
+		minecraftClient, _ := newMinecraftCommandClient("No player was found")
+
+		err := minecraftClient.Tellraw("Steve", text.Text("hi"))
+		if _, ok := err.(client.PlayerNotFoundError); !ok {
+			t.Errorf("expected: PlayerNotFoundError\ngot: %T\n", err)
+		}
+	})
+}
+
+func TestMinecraftClient_Title(t *testing.T) {
+	minecraftClient, mock := newMinecraftCommandClient("Showing new actionbar title for Steve")
+
+	err := minecraftClient.Title("Steve", client.MinecraftActionbar, text.Text("restart"))
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	expect := `title Steve actionbar {"text":"restart"}`
+	if string(mock.Out[0].Body) != expect {
+		t.Errorf("command did not match:\nexpected: %s\ngot: %s\n", expect, string(mock.Out[0].Body))
+	}
+}
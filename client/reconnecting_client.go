@@ -0,0 +1,245 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// NewReconnectingClient is a constructor for the ReconnectingClient struct.
+// The connection gets established lazily with the first command.
+func NewReconnectingClient(dial DialFunc, dialect Dialect, password string) *ReconnectingClient {
+	return &ReconnectingClient{Dial: dial, Dialect: dialect, Password: password}
+}
+
+// ReconnectingClient is a long-lived Client that holds an authenticated connection.
+// The connection gets (re)established and authenticated on demand:
+// after an error that leaves the connection in an unknown state it gets closed
+// and the next command opens a new one.
+// Failed commands are never repeated because the server might have executed them already.
+//
+// This struct can be used concurrently, the commands get executed one after another.
+type ReconnectingClient struct {
+	// Dial opens the connections.
+	Dial DialFunc
+	// Dialect selects the Client implementation to use on the connections.
+	Dialect Dialect
+	// Password used to authenticate new connections.
+	Password string
+	// Timeout for a command including a reconnect. Zero means no timeout.
+	Timeout time.Duration
+	// IdGenFunc is the function to use to generate ids.
+	// The util.GenerateRequestId is used if it is nil.
+	IdGenFunc func() grcon.PacketId
+	// MaxCommandLength rejects longer commands before they are sent. Zero means no limit.
+	// Dialect.MaxCommandLength returns the limit of the servers.
+	MaxCommandLength int
+
+	mutex  sync.Mutex
+	conn   net.Conn
+	client Client
+	stats  ConnectionStats
+}
+
+// ConnectionStats are the health counters of a ReconnectingClient.
+type ConnectionStats struct {
+	// Connects is the number of successfully authenticated connections.
+	Connects int
+	// DialFailures is the number of connections that could not be opened.
+	DialFailures int
+	// AuthFailures is the number of connections that failed to authenticate.
+	AuthFailures int
+	// ConnectionErrors is the number of commands that broke the connection.
+	ConnectionErrors int
+	// Connected reports if a connection is currently open.
+	Connected bool
+}
+
+// Reconnects returns the number of connections opened after the first one.
+func (cs ConnectionStats) Reconnects() int {
+	if cs.Connects == 0 {
+		return 0
+	}
+	return cs.Connects - 1
+}
+
+// Auth sets the password and establishes a new authenticated connection.
+// It can be used to validate the configuration eagerly.
+//
+// Errors:
+// Returns all errors from the DialFunc and the Auth method of the dialect.
+func (rc *ReconnectingClient) Auth(password string) error {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	rc.Password = password
+	rc.closeConn()
+
+	deadline := rc.deadline()
+	if !deadline.IsZero() {
+		defer rc.clearDeadline()
+	}
+
+	return rc.connect(deadline)
+}
+
+// Exec executes the command over the current connection and opens a new one if needed.
+//
+// Errors:
+// Returns all errors from the DialFunc and the Auth and Exec methods of the dialect
+// and an InvalidArgumentError if the command is longer than the MaxCommandLength.
+func (rc *ReconnectingClient) Exec(cmd string) ([]byte, error) {
+	if rc.MaxCommandLength > 0 && len(cmd) > rc.MaxCommandLength {
+		return []byte{}, newInvalidArgumentError(fmt.Sprintf("command is longer than %d bytes", rc.MaxCommandLength))
+	}
+
+	var response []byte
+	err := rc.Do(func(c Client) error {
+		var err error
+		response, err = c.Exec(cmd)
+		return err
+	})
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return response, nil
+}
+
+// Do calls fn with the authenticated Client of the dialect, e.g. to use the typed
+// methods of a MinecraftClient. The Client must not be used after fn returned.
+//
+// The connection gets closed if fn returns an error that indicates a broken connection.
+func (rc *ReconnectingClient) Do(fn func(c Client) error) error {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	deadline := rc.deadline()
+	if !deadline.IsZero() {
+		defer rc.clearDeadline()
+	}
+
+	if rc.conn != nil && !deadline.IsZero() {
+		if err := rc.conn.SetDeadline(deadline); err != nil {
+			rc.closeConn()
+		}
+	}
+	if rc.conn == nil {
+		if err := rc.connect(deadline); err != nil {
+			return err
+		}
+	}
+
+	err := fn(rc.client)
+	if err != nil && isConnectionError(err) {
+		rc.stats.ConnectionErrors++
+		rc.closeConn()
+	}
+
+	return err
+}
+
+// Close closes the current connection.
+// The client can still be used afterwards and reconnects with the next command.
+func (rc *ReconnectingClient) Close() error {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if rc.conn == nil {
+		return nil
+	}
+	err := rc.conn.Close()
+	rc.conn = nil
+	rc.client = nil
+
+	return err
+}
+
+// Stats returns the health counters of the client.
+func (rc *ReconnectingClient) Stats() ConnectionStats {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	stats := rc.stats
+	stats.Connected = rc.conn != nil
+	return stats
+}
+
+// deadline returns the deadline for an operation starting now or the zero time if there is no Timeout.
+func (rc *ReconnectingClient) deadline() time.Time {
+	if rc.Timeout <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(rc.Timeout)
+}
+
+// connect dials and authenticates a new connection that has the deadline set.
+// A zero deadline means no deadline. The mutex has to be held.
+func (rc *ReconnectingClient) connect(deadline time.Time) error {
+	conn, err := rc.Dial()
+	if err != nil {
+		rc.stats.DialFailures++
+		return err
+	}
+	if !deadline.IsZero() {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	idGenFunc := rc.IdGenFunc
+	if idGenFunc == nil {
+		idGenFunc = util.GenerateRequestId
+	}
+	c, err := NewDialectClient(rc.Dialect, grcon.NewRemoteConsoleSize(conn, rc.Dialect.MaxPacket()), idGenFunc)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	err = c.Auth(rc.Password)
+	if err != nil {
+		rc.stats.AuthFailures++
+		conn.Close()
+		return err
+	}
+
+	rc.stats.Connects++
+	rc.conn = conn
+	rc.client = c
+
+	return nil
+}
+
+// clearDeadline removes the deadline of the current connection. The mutex has to be held.
+func (rc *ReconnectingClient) clearDeadline() {
+	if rc.conn != nil {
+		if err := rc.conn.SetDeadline(time.Time{}); err != nil {
+			rc.closeConn()
+		}
+	}
+}
+
+// closeConn closes the current connection and ignores the error. The mutex has to be held.
+func (rc *ReconnectingClient) closeConn() {
+	if rc.conn != nil {
+		rc.conn.Close()
+	}
+	rc.conn = nil
+	rc.client = nil
+}
+
+// isConnectionError reports if the error might have left the connection in an unknown state.
+// Errors that describe the result of a successfully executed command keep the connection open.
+func isConnectionError(err error) bool {
+	switch err.(type) {
+	case PlayerNotFoundError, UnknownCommandError, LuaError, ResponseParseError, InvalidArgumentError:
+		return false
+	}
+	return true
+}
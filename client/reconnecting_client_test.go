@@ -0,0 +1,180 @@
+package client_test
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+)
+
+// fakeMinecraftDialer returns connections to an in-memory server that behaves like a minecraft server
+// and echos every command. A server connection gets closed after closeAfter commands if it is greater than zero.
+type fakeMinecraftDialer struct {
+	Password   string
+	CloseAfter int
+
+	mutex sync.Mutex
+	Dials int
+}
+
+func (d *fakeMinecraftDialer) Dial() (net.Conn, error) {
+	d.mutex.Lock()
+	d.Dials++
+	d.mutex.Unlock()
+
+	clientConn, serverConn := net.Pipe()
+	go d.serve(serverConn)
+
+	return clientConn, nil
+}
+
+func (d *fakeMinecraftDialer) serve(conn net.Conn) {
+	defer conn.Close()
+	remoteConsole := grcon.NewRemoteConsole(conn)
+
+	commands := 0
+	for {
+		packet, err := remoteConsole.Read()
+		if err != nil {
+			return
+		}
+
+		switch packet.Type {
+		case grcon.SERVERDATA_AUTH:
+			id := packet.Id
+			if string(packet.Body) != d.Password {
+				id = -1
+			}
+			err = remoteConsole.Write(grcon.Packet{Id: id, Type: grcon.SERVERDATA_AUTH_RESPONSE, Body: []byte{}})
+		case grcon.SERVERDATA_EXECCOMMAND:
+			commands++
+			if d.CloseAfter > 0 && commands > d.CloseAfter {
+				return
+			}
+			err = remoteConsole.Write(grcon.Packet{Id: packet.Id, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: append([]byte("echo: "), packet.Body...)})
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func TestReconnectingClient(t *testing.T) {
+	t.Run("reuse connection", func(t *testing.T) {
+		dialer := &fakeMinecraftDialer{Password: "secret"}
+		reconnectingClient := client.NewReconnectingClient(dialer.Dial, client.DialectMinecraft, "secret")
+		defer reconnectingClient.Close()
+
+		for i := 0; i < 2; i++ {
+			got, err := reconnectingClient.Exec("list")
+			if err != nil {
+				t.Error(err)
+				t.FailNow()
+			}
+			if string(got) != "echo: list" {
+				t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", "echo: list", string(got))
+			}
+		}
+
+		if dialer.Dials != 1 {
+			t.Errorf("expected 1 dial but got %d\n", dialer.Dials)
+		}
+	})
+
+	t.Run("reconnect after closed connection", func(t *testing.T) {
+		dialer := &fakeMinecraftDialer{Password: "secret", CloseAfter: 1}
+		reconnectingClient := client.NewReconnectingClient(dialer.Dial, client.DialectMinecraft, "secret")
+		defer reconnectingClient.Close()
+
+		_, err := reconnectingClient.Exec("list")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		// the server closes the connection instead of answering.
+		_, err = reconnectingClient.Exec("list")
+		if err == nil {
+			t.Error("expected an error for the closed connection")
+		}
+
+		got, err := reconnectingClient.Exec("list")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if string(got) != "echo: list" {
+			t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", "echo: list", string(got))
+		}
+		if dialer.Dials != 2 {
+			t.Errorf("expected 2 dials but got %d\n", dialer.Dials)
+		}
+
+		expected := client.ConnectionStats{Connects: 2, ConnectionErrors: 1, Connected: true}
+		if stats := reconnectingClient.Stats(); stats != expected || stats.Reconnects() != 1 {
+			t.Errorf("stats did not match:\nexpected: %+v\ngot: %+v\n", expected, stats)
+		}
+	})
+
+	t.Run("typed client", func(t *testing.T) {
+		dialer := &fakeMinecraftDialer{Password: "secret"}
+		reconnectingClient := client.NewReconnectingClient(dialer.Dial, client.DialectMinecraft, "secret")
+		defer reconnectingClient.Close()
+
+		err := reconnectingClient.Do(func(c client.Client) error {
+			if _, ok := c.(client.MinecraftClient); !ok {
+				t.Errorf("expected: MinecraftClient\ngot: %T\n", c)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("auth failed", func(t *testing.T) {
+		dialer := &fakeMinecraftDialer{Password: "secret"}
+		reconnectingClient := client.NewReconnectingClient(dialer.Dial, client.DialectMinecraft, "wrong")
+
+		_, err := reconnectingClient.Exec("list")
+		if _, ok := err.(client.AuthFailedError); !ok {
+			t.Errorf("expected: AuthFailedError\ngot: %T\n", err)
+		}
+		if stats := reconnectingClient.Stats(); stats.AuthFailures != 1 || stats.Connected {
+			t.Errorf("stats did not match: %+v\n", stats)
+		}
+	})
+
+	t.Run("command too long", func(t *testing.T) {
+		dialer := &fakeMinecraftDialer{Password: "secret"}
+		reconnectingClient := client.NewReconnectingClient(dialer.Dial, client.DialectMinecraft, "secret")
+		reconnectingClient.MaxCommandLength = client.DialectMinecraft.MaxCommandLength()
+
+		_, err := reconnectingClient.Exec(strings.Repeat("a", 1447))
+		if _, ok := err.(client.InvalidArgumentError); !ok {
+			t.Errorf("expected: InvalidArgumentError\ngot: %T\n", err)
+		}
+		if dialer.Dials != 0 {
+			t.Errorf("expected no connection but got %d\n", dialer.Dials)
+		}
+	})
+}
+
+func TestNewDialectClient(t *testing.T) {
+	_, err := client.NewDialectClient("unknown", &MockRemoteConsole{}, (&MockIdGenerator{}).GetNextId)
+	if _, ok := err.(client.InvalidArgumentError); !ok {
+		t.Errorf("expected: InvalidArgumentError\ngot: %T\n", err)
+	}
+
+	got, err := client.NewDialectClient(client.DialectSource, &MockRemoteConsole{}, (&MockIdGenerator{}).GetNextId)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if _, ok := got.(client.SourceClient); !ok {
+		t.Errorf("expected: SourceClient\ngot: %T\n", got)
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"github.com/hamburghammer/grcon/util"
 )
 
+// sourceDelimiterTrailer is the body of the packet Source servers send after the mirrored delimiter packet.
+var sourceDelimiterTrailer = []byte{0x00, 0x01, 0x00, 0x00}
+
 // NewSimpleClient is a constructor for the SimpleClient struct.
 // The util.GenerateNewId can be used as idGenFunc.
 func NewSimpleClient(r util.RemoteConsole, idGenFunc func() grcon.PacketId) SimpleClient {
@@ -77,6 +80,10 @@ func (sc SimpleClient) Auth(password string) error {
 // waits till the response is read returns it.
 // Supports multi-packet responses.
 //
+// Source servers answer the delimiter packet with the mirrored empty packet and
+// a second packet with the body 0x00 0x01 0x00 0x00. The second packet is skipped
+// by the next Exec.
+//
 // The server has to response synchronously!
 //
 // Errors:
@@ -116,6 +123,11 @@ func (sc SimpleClient) Exec(cmd string) ([]byte, error) {
 		if packet.Type != grcon.SERVERDATA_RESPONSE_VALUE {
 			return []byte{}, newInvalidResponseTypeError(grcon.SERVERDATA_RESPONSE_VALUE, packet.Type)
 		}
+		// skip the trailing packet of the delimiter of a previous command.
+		// It is checked first because consecutive commands can reuse the ids.
+		if packet.Id != cmdPacket.Id && bytes.Equal(packet.Body, sourceDelimiterTrailer) {
+			continue
+		}
 		// early break if delimiter packet is read.
 		if packet.Id == delimiterPacket.Id {
 			break
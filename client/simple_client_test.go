@@ -223,6 +223,29 @@ func TestSimpleClient_Exec(t *testing.T) {
 		}
 	})
 
+	t.Run("skip trailer of the previous delimiter", func(t *testing.T) {
+		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{3, 4}}
+		mock := &MockRemoteConsole{In: []grcon.Packet{
+			// trailer of the previous delimiter which had the same id as the current one.
+			{Id: 4, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte{0x00, 0x01, 0x00, 0x00}},
+			{Id: 3, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("bar")},
+			{Id: 4, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("")},
+		}}
+		simpleClient := client.SimpleClient{
+			RemoteConsole: mock,
+			IdGenFunc:     mockIdGen.GetNextId,
+		}
+		got, err := simpleClient.Exec("foo")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		if string(got) != "bar" {
+			t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", "bar", string(got))
+		}
+	})
+
 	t.Run("write cmd packet", func(t *testing.T) {
 		mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1, 2}}
 		mock := &MockRemoteConsole{In: []grcon.Packet{
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// NewSourceClient is a constructor for the SourceClient struct.
+// The util.GenerateRequestId can be used as idGenFunc.
+func NewSourceClient(r util.RemoteConsole, idGenFunc func() grcon.PacketId) SourceClient {
+	return SourceClient{SimpleClient: NewSimpleClient(r, idGenFunc)}
+}
+
+// SourceClient is a SimpleClient with typed methods for the common commands of
+// Source engine servers like CS2, CS:GO, TF2 or GMod.
+type SourceClient struct {
+	SimpleClient
+}
+
+// Status executes the status command and returns the parsed result.
+//
+// Errors:
+// Returns all errors from Exec and a ResponseParseError if the response has an unknown format.
+func (sc SourceClient) Status() (SourceStatus, error) {
+	response, err := sc.Exec("status")
+	if err != nil {
+		return SourceStatus{}, err
+	}
+
+	return ParseSourceStatus(string(response))
+}
+
+// Stats executes the stats command and returns the parsed result.
+//
+// Errors:
+// Returns all errors from Exec and a ResponseParseError if the response has an unknown format.
+func (sc SourceClient) Stats() (SourceStats, error) {
+	response, err := sc.Exec("stats")
+	if err != nil {
+		return SourceStats{}, err
+	}
+
+	return ParseSourceStats(string(response))
+}
+
+// GetCvar queries the current value of the cvar.
+//
+// Errors:
+// Returns all errors from Exec, an InvalidArgumentError if the name is empty or contains whitespace,
+// a semicolon or a double quote, an UnknownCommandError if the cvar does not exist and
+// a ResponseParseError if the response has an unknown format.
+func (sc SourceClient) GetCvar(name string) (SourceCvar, error) {
+	if err := validateCvarName(name); err != nil {
+		return SourceCvar{}, err
+	}
+
+	response, err := sc.Exec(name)
+	if err != nil {
+		return SourceCvar{}, err
+	}
+
+	cvar, err := ParseSourceCvar(string(response))
+	if unknownErr, ok := err.(UnknownCommandError); ok {
+		return SourceCvar{}, newUnknownCommandError(name, unknownErr.Response)
+	}
+
+	return cvar, err
+}
+
+// SetCvar sets the cvar to the value.
+// The value gets quoted and can therefore contain spaces but no double quotes or control characters.
+//
+// Errors:
+// Returns all errors from Exec, an UnknownCommandError if the cvar does not exist and
+// an InvalidArgumentError if the name is invalid like for GetCvar or the value contains a double quote
+// or a control character like a line break.
+func (sc SourceClient) SetCvar(name, value string) error {
+	if err := validateCvarName(name); err != nil {
+		return err
+	}
+	if strings.Contains(value, `"`) {
+		return newInvalidArgumentError(fmt.Sprintf("cvar value for '%s' contains a double quote", name))
+	}
+	if strings.IndexFunc(value, unicode.IsControl) >= 0 {
+		return newInvalidArgumentError(fmt.Sprintf("cvar value for '%s' contains a control character", name))
+	}
+
+	cmd := fmt.Sprintf(`%s "%s"`, name, value)
+	response, err := sc.Exec(cmd)
+	if err != nil {
+		return err
+	}
+
+	trimmed := strings.TrimSpace(string(response))
+	if isSourceUnknownCommand(trimmed) {
+		return newUnknownCommandError(cmd, trimmed)
+	}
+
+	return nil
+}
+
+// validateCvarName returns an InvalidArgumentError if the name would not be sent as a single cvar name.
+// Whitespace separates the arguments and a semicolon or a double quote would start another command.
+func validateCvarName(name string) error {
+	if name == "" {
+		return newInvalidArgumentError("empty cvar name")
+	}
+	if strings.IndexFunc(name, unicode.IsSpace) >= 0 || strings.ContainsAny(name, `;"`) {
+		return newInvalidArgumentError(fmt.Sprintf("cvar name '%s' contains whitespace, a semicolon or a double quote", name))
+	}
+	return nil
+}
+
+// CvarList returns all cvars and commands that start with the prefix.
+// An empty prefix lists all of them.
+//
+// Errors:
+// Returns all errors from Exec.
+func (sc SourceClient) CvarList(prefix string) ([]SourceCvarListEntry, error) {
+	response, err := sc.Exec(appendArg("cvarlist", prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSourceCvarList(string(response)), nil
+}
+
+// Maps returns the names of all maps on the server that match the filter.
+// An empty filter lists all maps.
+//
+// Errors:
+// Returns all errors from Exec.
+func (sc SourceClient) Maps(filter string) ([]string, error) {
+	if filter == "" {
+		filter = "*"
+	}
+
+	response, err := sc.Exec("maps " + filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSourceMaps(string(response)), nil
+}
@@ -0,0 +1,146 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+)
+
+// newSourceCommandClient returns a SourceClient that answers the first command with the response.
+func newSourceCommandClient(response string) (client.SourceClient, *MockRemoteConsole) {
+	mockIdGen := &MockIdGenerator{Ids: []grcon.PacketId{1, 2}}
+	mock := &MockRemoteConsole{In: []grcon.Packet{
+		{Id: 1, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte(response)},
+		{Id: 2, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("")},
+	}}
+	return client.NewSourceClient(mock, mockIdGen.GetNextId), mock
+}
+
+func TestSourceClient_Status(t *testing.T) {
+	sourceClient, mock := newSourceCommandClient(tf2Status)
+
+	got, err := sourceClient.Status()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if string(mock.Out[0].Body) != "status" {
+		t.Errorf("command did not match: %s\n", string(mock.Out[0].Body))
+	}
+	if len(got.Players) != 3 {
+		t.Errorf("expected 3 players but got %d\n", len(got.Players))
+	}
+}
+
+func TestSourceClient_GetCvar(t *testing.T) {
+	t.Run("known cvar", func(t *testing.T) {
+		sourceClient, _ := newSourceCommandClient(`"mp_timelimit" = "30" ( def. "0" ) notify`)
+
+		got, err := sourceClient.GetCvar("mp_timelimit")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if got.Value != "30" || got.Default != "0" {
+			t.Errorf("cvar did not match: %+v\n", got)
+		}
+	})
+
+	t.Run("unknown cvar", func(t *testing.T) {
+		sourceClient, _ := newSourceCommandClient(`Unknown command "mp_foo"`)
+
+		_, err := sourceClient.GetCvar("mp_foo")
+		unknownErr, ok := err.(client.UnknownCommandError)
+		if !ok {
+			t.Fatalf("expected: UnknownCommandError\ngot: %T\n", err)
+		}
+		if unknownErr.Command != "mp_foo" {
+			t.Errorf("command did not match: %s\n", unknownErr.Command)
+		}
+	})
+
+	t.Run("reject invalid names", func(t *testing.T) {
+		for _, name := range []string{"", "mp_timelimit;quit", "mp_timelimit quit", "mp_timelimit\nquit", `mp_timelimit"`} {
+			sourceClient, mock := newSourceCommandClient("")
+
+			_, err := sourceClient.GetCvar(name)
+			if _, ok := err.(client.InvalidArgumentError); !ok {
+				t.Errorf("expected: InvalidArgumentError for %q\ngot: %T\n", name, err)
+			}
+			if len(mock.Out) != 0 {
+				t.Errorf("expected no written packets for %q but got %d\n", name, len(mock.Out))
+			}
+		}
+	})
+}
+
+func TestSourceClient_SetCvar(t *testing.T) {
+	t.Run("quote value", func(t *testing.T) {
+		sourceClient, mock := newSourceCommandClient("")
+
+		err := sourceClient.SetCvar("hostname", "My Server")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if string(mock.Out[0].Body) != `hostname "My Server"` {
+			t.Errorf("command did not match: %s\n", string(mock.Out[0].Body))
+		}
+	})
+
+	t.Run("reject invalid name", func(t *testing.T) {
+		sourceClient, mock := newSourceCommandClient("")
+
+		err := sourceClient.SetCvar("sv_cheats 1;hostname", "x")
+		if _, ok := err.(client.InvalidArgumentError); !ok {
+			t.Errorf("expected: InvalidArgumentError\ngot: %T\n", err)
+		}
+		if len(mock.Out) != 0 {
+			t.Errorf("expected no written packets but got %d\n", len(mock.Out))
+		}
+	})
+
+	t.Run("reject double quote", func(t *testing.T) {
+		sourceClient, mock := newSourceCommandClient("")
+
+		err := sourceClient.SetCvar("hostname", `"; quit`)
+		if _, ok := err.(client.InvalidArgumentError); !ok {
+			t.Errorf("expected: InvalidArgumentError\ngot: %T\n", err)
+		}
+		if len(mock.Out) != 0 {
+			t.Errorf("expected no written packets but got %d\n", len(mock.Out))
+		}
+	})
+
+	t.Run("reject control characters", func(t *testing.T) {
+		for _, value := range []string{"x\nrcon_password y", "x\rquit", "x\x00"} {
+			sourceClient, mock := newSourceCommandClient("")
+
+			err := sourceClient.SetCvar("hostname", value)
+			if _, ok := err.(client.InvalidArgumentError); !ok {
+				t.Errorf("expected: InvalidArgumentError for %q\ngot: %T\n", value, err)
+			}
+			if len(mock.Out) != 0 {
+				t.Errorf("expected no written packets for %q but got %d\n", value, len(mock.Out))
+			}
+		}
+	})
+}
+
+func TestSourceClient_Maps(t *testing.T) {
+	sourceClient, mock := newSourceCommandClient("PENDING:   (fs) cp_badlands.bsp\n")
+
+	got, err := sourceClient.Maps("")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if string(mock.Out[0].Body) != "maps *" {
+		t.Errorf("command did not match: %s\n", string(mock.Out[0].Body))
+	}
+	if len(got) != 1 || got[0] != "cp_badlands" {
+		t.Errorf("maps did not match: %v\n", got)
+	}
+}
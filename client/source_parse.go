@@ -0,0 +1,392 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SourceStatus is the parsed response of the status command of a Source engine server.
+type SourceStatus struct {
+	Hostname   string
+	Version    string
+	Map        string
+	Humans     int
+	Bots       int
+	MaxPlayers int
+	Players    []SourcePlayer
+}
+
+// SourcePlayer is a row of the player table of the status command.
+// Values that the server did not report are left empty.
+type SourcePlayer struct {
+	UserID int
+	Name   string
+	// SteamID is the unique id of the player, e.g. [U:1:12345] or STEAM_1:0:1234.
+	SteamID   string
+	Connected time.Duration
+	Ping      int
+	Loss      int
+	State     string
+	Address   string
+	Bot       bool
+}
+
+// SourceCvar is the parsed response of a cvar query like: "sv_cheats" = "0" ( def. "0" ).
+type SourceCvar struct {
+	Name    string
+	Value   string
+	Default string
+	// Flags like notify or replicated.
+	Flags       []string
+	Description string
+}
+
+// SourceCvarListEntry is a row of the cvarlist command.
+// Commands have the Value "cmd".
+type SourceCvarListEntry struct {
+	Name        string
+	Value       string
+	Flags       []string
+	Description string
+}
+
+// SourceStats is the parsed response of the stats command.
+// Columns the server does not print are zero.
+type SourceStats struct {
+	// CPU is the usage in percent.
+	CPU float64
+	// NetIn and NetOut are the traffic in KB/s.
+	NetIn  float64
+	NetOut float64
+	Uptime time.Duration
+	// MapChanges is the number of map changes since the start.
+	MapChanges int
+	FPS        float64
+	Players    int
+	Connects   int
+}
+
+var (
+	sourceStatusLineRegex  = regexp.MustCompile(`^(\w+(?:/\w+)?)\s*:\s?(.*)$`)
+	sourceStatusCountRegex = regexp.MustCompile(`^(\d+) humans?, (\d+) bots? \((\d+)(?:/\d+)? max\)`)
+	sourceStatusOldCount   = regexp.MustCompile(`^(\d+) \((\d+) max\)`)
+	sourceCvarRegex        = regexp.MustCompile(`^"([^"]+)" = "([^"]*)"(?: \( def\. "([^"]*)" \))?`)
+	sourceCvarPlainRegex   = regexp.MustCompile(`^(\S+) = (\S*)(?: \( def\. (\S*) \))?`)
+)
+
+// ParseSourceStatus parses the response of the status command.
+// It supports the player table of the Source 1 engine (TF2, CS:GO, GMod, ...)
+// and the one of CS2. Player names may contain spaces and quotes.
+//
+// Returns a ResponseParseError if the response contains no known status fields.
+func ParseSourceStatus(response string) (SourceStatus, error) {
+	status := SourceStatus{Players: []SourcePlayer{}}
+	parsedField := false
+	cs2Table := false
+
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			continue
+		case strings.HasPrefix(trimmed, "# userid"), strings.HasPrefix(trimmed, "---------"):
+			continue
+		case strings.HasPrefix(trimmed, "id ") && strings.HasSuffix(trimmed, " name"):
+			// header of the CS2 player table
+			cs2Table = true
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			player, ok := parseSourcePlayer(strings.TrimSpace(trimmed[1:]))
+			if ok {
+				status.Players = append(status.Players, player)
+			}
+			continue
+		case cs2Table:
+			player, ok := parseCS2Player(trimmed)
+			if ok {
+				status.Players = append(status.Players, player)
+			}
+			continue
+		}
+
+		matches := sourceStatusLineRegex.FindStringSubmatch(trimmed)
+		if matches == nil {
+			continue
+		}
+		value := strings.TrimSpace(matches[2])
+
+		switch matches[1] {
+		case "hostname":
+			status.Hostname = value
+			parsedField = true
+		case "version":
+			status.Version = value
+			parsedField = true
+		case "map":
+			// strip the position of the map: "cp_badlands at: 0 x, 0 y, 0 z"
+			if fields := strings.Fields(value); len(fields) > 0 {
+				status.Map = fields[0]
+			}
+			parsedField = true
+		case "players":
+			if counts := sourceStatusCountRegex.FindStringSubmatch(value); counts != nil {
+				status.Humans, _ = strconv.Atoi(counts[1])
+				status.Bots, _ = strconv.Atoi(counts[2])
+				status.MaxPlayers, _ = strconv.Atoi(counts[3])
+			} else if counts := sourceStatusOldCount.FindStringSubmatch(value); counts != nil {
+				status.Humans, _ = strconv.Atoi(counts[1])
+				status.MaxPlayers, _ = strconv.Atoi(counts[2])
+			}
+			parsedField = true
+		}
+	}
+
+	if !parsedField {
+		return SourceStatus{}, newResponseParseError(response, errors.New("unknown status format"))
+	}
+
+	return status, nil
+}
+
+// parseSourcePlayer parses a row of the Source 1 player table without the leading '#':
+//	2 "Player One"   [U:1:12345]   05:12   67   0 active 1.2.3.4:27005
+// CS:GO prints an additional slot number after the user id.
+func parseSourcePlayer(line string) (SourcePlayer, bool) {
+	nameStart := strings.Index(line, `"`)
+	nameEnd := strings.LastIndex(line, `"`)
+	if nameStart < 0 || nameEnd <= nameStart {
+		return SourcePlayer{}, false
+	}
+
+	prefix := strings.Fields(line[:nameStart])
+	if len(prefix) == 0 {
+		return SourcePlayer{}, false
+	}
+	userID, err := strconv.Atoi(prefix[0])
+	if err != nil {
+		return SourcePlayer{}, false
+	}
+
+	player := SourcePlayer{UserID: userID, Name: line[nameStart+1 : nameEnd]}
+
+	fields := strings.Fields(line[nameEnd+1:])
+	if len(fields) == 0 {
+		return player, true
+	}
+	player.SteamID = fields[0]
+	player.Bot = fields[0] == "BOT"
+
+	if len(fields) >= 5 {
+		player.Connected = parseSourceDuration(fields[1])
+		player.Ping, _ = strconv.Atoi(fields[2])
+		player.Loss, _ = strconv.Atoi(fields[3])
+		player.State = fields[4]
+	} else if len(fields) >= 2 {
+		player.State = fields[1]
+	}
+	if last := fields[len(fields)-1]; len(fields) >= 5 && strings.Contains(last, ":") {
+		player.Address = last
+	}
+
+	return player, true
+}
+
+// parseCS2Player parses a row of the CS2 player table:
+//	2    00:30   24    0     active 786432 127.0.0.1:27005 'Player One'
+func parseCS2Player(line string) (SourcePlayer, bool) {
+	nameStart := strings.Index(line, "'")
+	nameEnd := strings.LastIndex(line, "'")
+	if nameStart < 0 || nameEnd <= nameStart {
+		return SourcePlayer{}, false
+	}
+
+	fields := strings.Fields(line[:nameStart])
+	// the first row is a placeholder for connecting clients: 65535 [NoChan] ...
+	if len(fields) < 5 || fields[1] == "[NoChan]" {
+		return SourcePlayer{}, false
+	}
+	userID, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return SourcePlayer{}, false
+	}
+
+	player := SourcePlayer{
+		UserID:    userID,
+		Name:      line[nameStart+1 : nameEnd],
+		Connected: parseSourceDuration(fields[1]),
+		State:     fields[4],
+	}
+	player.Ping, _ = strconv.Atoi(fields[2])
+	player.Loss, _ = strconv.Atoi(fields[3])
+	if len(fields) >= 7 {
+		player.Address = fields[6]
+	}
+	player.Bot = player.Address == "BOT"
+
+	return player, true
+}
+
+// parseSourceDuration parses a duration in the format [hh:]mm:ss.
+// Invalid durations are returned as 0.
+func parseSourceDuration(s string) time.Duration {
+	var total time.Duration
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0
+		}
+		total = total*60 + time.Duration(n)
+	}
+	return total * time.Second
+}
+
+// ParseSourceCvar parses the response of a cvar query.
+// Both the quoted ("sv_cheats" = "0" ( def. "0" )) and the unquoted format
+// (sv_cheats = false) are supported.
+// The lines following the value contain the flags and the description starting with " - ".
+//
+// Returns an UnknownCommandError if the server does not know the cvar and
+// a ResponseParseError if the response has an unknown format.
+func ParseSourceCvar(response string) (SourceCvar, error) {
+	response = strings.TrimSpace(response)
+	if isSourceUnknownCommand(response) {
+		return SourceCvar{}, newUnknownCommandError("", response)
+	}
+
+	lines := strings.Split(response, "\n")
+	first := strings.TrimSpace(lines[0])
+	matches := sourceCvarRegex.FindStringSubmatch(first)
+	if matches == nil {
+		matches = sourceCvarPlainRegex.FindStringSubmatch(first)
+	}
+	if matches == nil {
+		return SourceCvar{}, newResponseParseError(response, errors.New("unknown cvar format"))
+	}
+
+	cvar := SourceCvar{Name: matches[1], Value: matches[2], Default: matches[3], Flags: []string{}}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "- "):
+			cvar.Description = strings.TrimPrefix(line, "- ")
+		default:
+			cvar.Flags = append(cvar.Flags, strings.Fields(line)...)
+		}
+	}
+
+	return cvar, nil
+}
+
+// ParseSourceCvarList parses the response of the cvarlist command.
+// The header, separators and the total count are skipped.
+func ParseSourceCvarList(response string) []SourceCvarListEntry {
+	entries := []SourceCvarListEntry{}
+	for _, line := range strings.Split(response, "\n") {
+		parts := strings.SplitN(line, " : ", 4)
+		if len(parts) < 3 {
+			continue
+		}
+
+		entry := SourceCvarListEntry{
+			Name:  strings.TrimSpace(parts[0]),
+			Value: strings.TrimSpace(parts[1]),
+			Flags: []string{},
+		}
+		for _, flag := range strings.Split(parts[2], ",") {
+			flag = strings.Trim(strings.TrimSpace(flag), `"`)
+			if flag != "" {
+				entry.Flags = append(entry.Flags, flag)
+			}
+		}
+		if len(parts) == 4 {
+			entry.Description = strings.TrimSpace(parts[3])
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// ParseSourceMaps parses the response of the maps command and returns the map names without the .bsp extension.
+func ParseSourceMaps(response string) []string {
+	maps := []string{}
+	for _, line := range strings.Split(response, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		name := fields[len(fields)-1]
+		if strings.HasSuffix(name, ".bsp") {
+			maps = append(maps, strings.TrimSuffix(name, ".bsp"))
+		}
+	}
+	return maps
+}
+
+// sourceStatsColumns normalizes the multi word column names of the stats header.
+var sourceStatsColumns = strings.NewReplacer("In (KB/s)", "NetIn", "Out (KB/s)", "NetOut", "Map changes", "Maps")
+
+// ParseSourceStats parses the response of the stats command:
+//	CPU    In (KB/s)  Out (KB/s)  Uptime  Map changes  FPS      Players  Connects
+//	0.00   0.00       0.00        3       0            64.00    0        0
+// The variant of CS:GO ("CPU NetIn NetOut Uptime Maps FPS Players Svms +-ms ~tick") is also supported.
+// The Uptime is printed in minutes.
+//
+// Returns a ResponseParseError if the response contains no stats table.
+func ParseSourceStats(response string) (SourceStats, error) {
+	var header []string
+	for _, line := range strings.Split(response, "\n") {
+		fields := strings.Fields(sourceStatsColumns.Replace(line))
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "CPU" {
+			header = fields
+			continue
+		}
+		if header == nil || len(fields) != len(header) {
+			continue
+		}
+
+		stats := SourceStats{}
+		for i, column := range header {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return SourceStats{}, newResponseParseError(response, fmt.Errorf("invalid value of %s: %w", column, err))
+			}
+
+			switch column {
+			case "CPU":
+				stats.CPU = value
+			case "NetIn":
+				stats.NetIn = value
+			case "NetOut":
+				stats.NetOut = value
+			case "Uptime":
+				stats.Uptime = time.Duration(value * float64(time.Minute))
+			case "Maps":
+				stats.MapChanges = int(value)
+			case "FPS":
+				stats.FPS = value
+			case "Players":
+				stats.Players = int(value)
+			case "Connects":
+				stats.Connects = int(value)
+			}
+		}
+		return stats, nil
+	}
+
+	return SourceStats{}, newResponseParseError(response, errors.New("unknown stats format"))
+}
+
+// isSourceUnknownCommand reports if the response is the error of an unknown command or cvar.
+func isSourceUnknownCommand(response string) bool {
+	return strings.HasPrefix(response, "Unknown command")
+}
@@ -0,0 +1,215 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+)
+
+const tf2Status = `hostname: My "Awesome" Server
+version : 7729541/24 7729541 secure
+udp/ip  : 0.0.0.0:27015  (public ip: 1.2.3.4)
+steamid : [G:1:1234] (85568392920040402)
+account : not logged in  (No account specified)
+map     : cp_badlands at: 0 x, 0 y, 0 z
+tags    : cp,increased_maxplayers
+players : 2 humans, 1 bots (24 max)
+edicts  : 1234 used of 2048 max
+# userid name                uniqueid            connected ping loss state  adr
+#      2 "Player One"        [U:1:12345]         05:12       67    0 active 1.2.3.4:27005
+#      3 "The "Quoted" One"  [U:1:67890]         1:02:03     12    1 active 5.6.7.8:27005
+#      4 "Bot"               BOT                                     active
+`
+
+const cs2Status = `Server:  Running [0.0.0.0:27015]
+Client:  Disconnected
+Source TV:  not active
+players  : 1 humans, 0 bots (0 max) (not hibernating) (unreserved)
+hostname : Counter-Strike 2
+map     : de_dust2
+---------players--------
+  id     time ping loss      state   rate adr name
+65535 [NoChan]    0    0 challenging      0unknown ''
+    2    00:30   24    0     active 786432 127.0.0.1:27005 'Player With Spaces'
+#end
+`
+
+func TestParseSourceStatus(t *testing.T) {
+	t.Run("source 1", func(t *testing.T) {
+		got, err := client.ParseSourceStatus(tf2Status)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		if got.Hostname != `My "Awesome" Server` || got.Map != "cp_badlands" {
+			t.Errorf("header did not match: %+v\n", got)
+		}
+		if got.Humans != 2 || got.Bots != 1 || got.MaxPlayers != 24 {
+			t.Errorf("player counts did not match: %+v\n", got)
+		}
+		if len(got.Players) != 3 {
+			t.Fatalf("expected 3 players but got %d\n", len(got.Players))
+		}
+
+		expect := client.SourcePlayer{
+			UserID:    3,
+			Name:      `The "Quoted" One`,
+			SteamID:   "[U:1:67890]",
+			Connected: time.Hour + 2*time.Minute + 3*time.Second,
+			Ping:      12,
+			Loss:      1,
+			State:     "active",
+			Address:   "5.6.7.8:27005",
+		}
+		if got.Players[1] != expect {
+			t.Errorf("player did not match:\nexpected: %+v\ngot: %+v\n", expect, got.Players[1])
+		}
+		if !got.Players[2].Bot || got.Players[2].State != "active" {
+			t.Errorf("bot did not match: %+v\n", got.Players[2])
+		}
+	})
+
+	t.Run("cs2", func(t *testing.T) {
+		got, err := client.ParseSourceStatus(cs2Status)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		if got.Map != "de_dust2" || got.Humans != 1 {
+			t.Errorf("header did not match: %+v\n", got)
+		}
+		if len(got.Players) != 1 {
+			t.Fatalf("expected 1 player but got %d\n", len(got.Players))
+		}
+		if got.Players[0].Name != "Player With Spaces" || got.Players[0].Ping != 24 || got.Players[0].Address != "127.0.0.1:27005" {
+			t.Errorf("player did not match: %+v\n", got.Players[0])
+		}
+	})
+
+	t.Run("empty map", func(t *testing.T) {
+		got, err := client.ParseSourceStatus("hostname: server\nmap     : \nplayers : 0 humans, 0 bots (24 max)\n")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		if got.Hostname != "server" || got.Map != "" || got.MaxPlayers != 24 {
+			t.Errorf("header did not match: %+v\n", got)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := client.ParseSourceStatus("foo")
+		if _, ok := err.(client.ResponseParseError); !ok {
+			t.Errorf("expected: ResponseParseError\ngot: %T\n", err)
+		}
+	})
+}
+
+func TestParseSourceCvar(t *testing.T) {
+	t.Run("quoted", func(t *testing.T) {
+		got, err := client.ParseSourceCvar("\"sv_cheats\" = \"0\" ( def. \"0\" )\n notify replicated\n - Allow cheats on server\n")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		if got.Name != "sv_cheats" || got.Value != "0" || got.Default != "0" {
+			t.Errorf("cvar did not match: %+v\n", got)
+		}
+		if len(got.Flags) != 2 || got.Flags[1] != "replicated" {
+			t.Errorf("flags did not match: %v\n", got.Flags)
+		}
+		if got.Description != "Allow cheats on server" {
+			t.Errorf("description did not match: %s\n", got.Description)
+		}
+	})
+
+	t.Run("unquoted", func(t *testing.T) {
+		got, err := client.ParseSourceCvar("sv_cheats = false")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if got.Name != "sv_cheats" || got.Value != "false" {
+			t.Errorf("cvar did not match: %+v\n", got)
+		}
+	})
+
+	t.Run("unknown cvar", func(t *testing.T) {
+		_, err := client.ParseSourceCvar(`Unknown command "sv_foo"`)
+		if _, ok := err.(client.UnknownCommandError); !ok {
+			t.Errorf("expected: UnknownCommandError\ngot: %T\n", err)
+		}
+	})
+}
+
+func TestParseSourceCvarList(t *testing.T) {
+	response := `cvar list
+--------------
+sv_cheats                                : 0        : , "nf", "rep"    : Allow cheats on server
+changelevel                              : cmd      :                  : Change server to the specified map
+--------------
+  2 total convars/concommands
+`
+	got := client.ParseSourceCvarList(response)
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 entries but got %d\n", len(got))
+	}
+	if got[0].Name != "sv_cheats" || got[0].Value != "0" || len(got[0].Flags) != 2 || got[0].Flags[0] != "nf" {
+		t.Errorf("entry did not match: %+v\n", got[0])
+	}
+	if got[1].Value != "cmd" || got[1].Description != "Change server to the specified map" {
+		t.Errorf("entry did not match: %+v\n", got[1])
+	}
+}
+
+func TestParseSourceMaps(t *testing.T) {
+	got := client.ParseSourceMaps("-------------\nPENDING:   (fs) cp_badlands.bsp\nPENDING:   (fs) ctf_2fort.bsp\n")
+
+	if len(got) != 2 || got[0] != "cp_badlands" || got[1] != "ctf_2fort" {
+		t.Errorf("maps did not match: %v\n", got)
+	}
+}
+
+func TestParseSourceStats(t *testing.T) {
+	t.Run("source 1", func(t *testing.T) {
+		response := "CPU    In (KB/s)  Out (KB/s)  Uptime  Map changes  FPS      Players  Connects\n" +
+			"12.50  1.25       3.50        90      2            66.67    5        17\n"
+
+		got, err := client.ParseSourceStats(response)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		expected := client.SourceStats{CPU: 12.5, NetIn: 1.25, NetOut: 3.5, Uptime: 90 * time.Minute, MapChanges: 2, FPS: 66.67, Players: 5, Connects: 17}
+		if got != expected {
+			t.Errorf("stats did not match:\nexpected: %+v\ngot: %+v\n", expected, got)
+		}
+	})
+
+	t.Run("cs:go", func(t *testing.T) {
+		response := "  CPU   NetIn   NetOut    Uptime  Maps   FPS   Players  Svms    +-ms   ~tick\n" +
+			"  10.0      0.0      0.0     123     1  128.00       3    1.73    0.28    0.08\n"
+
+		got, err := client.ParseSourceStats(response)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if got.FPS != 128 || got.Players != 3 || got.Uptime != 123*time.Minute {
+			t.Errorf("stats did not match: %+v\n", got)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := client.ParseSourceStats("Unknown command \"stats\"")
+		if _, ok := err.(client.ResponseParseError); !ok {
+			t.Errorf("expected: ResponseParseError\ngot: %T\n", err)
+		}
+	})
+}
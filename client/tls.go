@@ -0,0 +1,118 @@
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// pinPrefix is the prefix of the public key pins.
+const pinPrefix = "sha256/"
+
+// TLSOptions configures RCONS: the unchanged RCON protocol over a TLS connection,
+// e.g. to a server behind stunnel or a grcon server with a TLSConfig.
+type TLSOptions struct {
+	// CAFile is a PEM file with the certificates that are trusted to sign the server certificate.
+	// The system roots are used if it is empty.
+	CAFile string `json:"ca_file"`
+	// CertFile and KeyFile are the PEM files of the client certificate for mutual TLS.
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+	// ServerName is the expected name in the server certificate. Defaults to the host of the address.
+	ServerName string `json:"server_name"`
+	// Pins are the allowed public keys of the server certificate as "sha256/<base64>" of the
+	// DER encoded SubjectPublicKeyInfo (see PublicKeyPin).
+	// If pins are set without a CAFile, the certificate chain is not verified and
+	// self-signed certificates are accepted as long as the key matches.
+	Pins []string `json:"pins"`
+}
+
+// Config builds the tls.Config to connect to the address.
+//
+// Errors:
+// Returns the errors of reading the files and an InvalidArgumentError for invalid certificates or pins.
+func (o TLSOptions) Config(addr string) (*tls.Config, error) {
+	config := &tls.Config{ServerName: o.ServerName, MinVersion: tls.VersionTLS12}
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		config.ServerName = host
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, newInvalidArgumentError(fmt.Sprintf("no certificates in '%s'", o.CAFile))
+		}
+		config.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, newInvalidArgumentError(fmt.Sprintf("loading client certificate: %s", err.Error()))
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(o.Pins) > 0 {
+		for _, pin := range o.Pins {
+			if !strings.HasPrefix(pin, pinPrefix) {
+				return nil, newInvalidArgumentError(fmt.Sprintf("pin '%s' does not start with %s", pin, pinPrefix))
+			}
+		}
+		// the pins replace the chain verification, otherwise they are checked in addition.
+		config.InsecureSkipVerify = o.CAFile == ""
+		config.VerifyConnection = verifyPins(o.Pins)
+	}
+
+	return config, nil
+}
+
+// verifyPins returns a tls.Config.VerifyConnection callback that accepts the connection
+// if the public key of the server certificate is one of the pins.
+func verifyPins(pins []string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("server sent no certificate")
+		}
+		pin := PublicKeyPin(state.PeerCertificates[0])
+		for _, allowed := range pins {
+			if pin == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("public key %s of the server certificate is not pinned", pin)
+	}
+}
+
+// PublicKeyPin returns the pin of the public key of the certificate: "sha256/" followed by the
+// base64 encoded SHA-256 of the SubjectPublicKeyInfo. It can be computed with:
+//	openssl x509 -in cert.pem -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+func PublicKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return pinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// TLSDialer returns a DialFunc that connects over TLS to the address.
+// The handshake is done before the connection is returned.
+// A timeout of zero means no timeout.
+func TLSDialer(addr string, timeout time.Duration, config *tls.Config) DialFunc {
+	return func() (net.Conn, error) {
+		dialer := &net.Dialer{Timeout: timeout}
+		return tls.DialWithDialer(dialer, "tcp", addr, config)
+	}
+}
@@ -0,0 +1,229 @@
+package client_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/server"
+)
+
+// testPKI is a generated CA with a server and a client certificate written as PEM files.
+type testPKI struct {
+	CAFile, ServerCertFile, ServerKeyFile, ClientCertFile, ClientKeyFile string
+
+	CAPool     *x509.CertPool
+	ServerCert *x509.Certificate
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	dir := t.TempDir()
+	pki := testPKI{}
+
+	caKey, caCert, caDER := generateCert(t, "test-ca", nil, nil, func(template *x509.Certificate) {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	})
+	pki.CAPool = x509.NewCertPool()
+	pki.CAPool.AddCert(caCert)
+	pki.CAFile = writePEM(t, dir, "ca.pem", "CERTIFICATE", caDER)
+
+	serverKey, serverCert, serverDER := generateCert(t, "localhost", caCert, caKey, func(template *x509.Certificate) {
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	})
+	pki.ServerCert = serverCert
+	pki.ServerCertFile = writePEM(t, dir, "server.pem", "CERTIFICATE", serverDER)
+	pki.ServerKeyFile = writeKey(t, dir, "server-key.pem", serverKey)
+
+	clientKey, _, clientDER := generateCert(t, "alice", caCert, caKey, func(template *x509.Certificate) {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	})
+	pki.ClientCertFile = writePEM(t, dir, "client.pem", "CERTIFICATE", clientDER)
+	pki.ClientKeyFile = writeKey(t, dir, "client-key.pem", clientKey)
+
+	return pki
+}
+
+// generateCert creates a certificate signed by the parent or a self-signed one if the parent is nil.
+func generateCert(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey,
+	modify func(*x509.Certificate)) (*ecdsa.PrivateKey, *x509.Certificate, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	modify(template)
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert, der
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeKey(t *testing.T, dir, name string, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writePEM(t, dir, name, "EC PRIVATE KEY", der)
+}
+
+// startTLSServer serves an echo server over TLS that requires client certificates of the CA
+// if requireClientCert is set. The common names of the client certificates are sent to the channel.
+func startTLSServer(t *testing.T, pki testPKI, requireClientCert bool) (string, chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := make(chan string, 10)
+	srv := &server.Server{
+		Auth: func(s *server.Session, password string) (string, bool) {
+			user := ""
+			if s.TLS != nil && len(s.TLS.PeerCertificates) > 0 {
+				user = s.TLS.PeerCertificates[0].Subject.CommonName
+			}
+			users <- user
+			return user, password == "secret"
+		},
+		Handler: server.HandlerFunc(func(w server.ResponseWriter, r *server.Request) {
+			fmt.Fprintf(w, "echo: %s", r.Command)
+		}),
+		TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+	if requireClientCert {
+		srv.TLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		srv.TLSConfig.ClientCAs = pki.CAPool
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		srv.ServeTLS(l, pki.ServerCertFile, pki.ServerKeyFile)
+	}()
+	t.Cleanup(func() {
+		srv.Close()
+		wg.Wait()
+	})
+
+	return l.Addr().String(), users
+}
+
+func execOverTLS(addr string, options client.TLSOptions) ([]byte, error) {
+	config, err := options.Config(addr)
+	if err != nil {
+		return nil, err
+	}
+	c := client.NewReconnectingClient(client.TLSDialer(addr, 5*time.Second, config), client.DialectMinecraft, "secret")
+	c.Timeout = 5 * time.Second
+	defer c.Close()
+
+	return c.Exec("list")
+}
+
+func TestTLSDialer(t *testing.T) {
+	pki := newTestPKI(t)
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		addr, users := startTLSServer(t, pki, true)
+
+		got, err := execOverTLS(addr, client.TLSOptions{CAFile: pki.CAFile, CertFile: pki.ClientCertFile, KeyFile: pki.ClientKeyFile})
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if string(got) != "echo: list" {
+			t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", "echo: list", string(got))
+		}
+		if user := <-users; user != "alice" {
+			t.Errorf("user of the client certificate did not match:\nexpected: %s\ngot: %s\n", "alice", user)
+		}
+	})
+
+	t.Run("missing client certificate", func(t *testing.T) {
+		addr, _ := startTLSServer(t, pki, true)
+
+		if _, err := execOverTLS(addr, client.TLSOptions{CAFile: pki.CAFile}); err == nil {
+			t.Error("expected an error without client certificate")
+		}
+	})
+
+	t.Run("unknown CA", func(t *testing.T) {
+		addr, _ := startTLSServer(t, pki, false)
+
+		if _, err := execOverTLS(addr, client.TLSOptions{}); err == nil {
+			t.Error("expected an error for the unknown CA")
+		}
+	})
+
+	t.Run("pinned public key", func(t *testing.T) {
+		addr, _ := startTLSServer(t, pki, false)
+
+		got, err := execOverTLS(addr, client.TLSOptions{Pins: []string{client.PublicKeyPin(pki.ServerCert)}})
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if string(got) != "echo: list" {
+			t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", "echo: list", string(got))
+		}
+	})
+
+	t.Run("wrong pin", func(t *testing.T) {
+		addr, _ := startTLSServer(t, pki, false)
+
+		_, err := execOverTLS(addr, client.TLSOptions{CAFile: pki.CAFile, Pins: []string{"sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}})
+		if err == nil {
+			t.Error("expected an error for the wrong pin")
+		}
+	})
+
+	t.Run("invalid pin", func(t *testing.T) {
+		_, err := client.TLSOptions{Pins: []string{"md5/abc"}}.Config("127.0.0.1:27015")
+		if _, ok := err.(client.InvalidArgumentError); !ok {
+			t.Errorf("expected: InvalidArgumentError\ngot: %T\n", err)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+// Command grcon-bridge starts a dedicated server without RCON and exposes its console as RCON server.
+//
+// Usage:
+//
+//	GRCON_BRIDGE_PASSWORD=secret grcon-bridge -listen :25575 -prompt ": " -stop exit -- ./TerrariaServer -config serverconfig.txt
+//
+// Commands are written to the stdin of the process and the output until the prompt
+// or a quiet period is the response. The output that is not part of a response is
+// printed to stdout. The process gets stopped on SIGINT or SIGTERM and grcon-bridge
+// exits when the process exits.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/hamburghammer/grcon/bridge"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/server"
+)
+
+func main() {
+	listen := flag.String("listen", ":25575", "address to listen on for RCON clients")
+	passwordEnv := flag.String("password-env", "GRCON_BRIDGE_PASSWORD", "environment variable with the RCON password")
+	prompt := flag.String("prompt", "", "prompt of the console that ends a response, e.g. \"> \"")
+	quiet := flag.Duration("quiet", bridge.DefaultQuietPeriod, "time without output that ends a response")
+	maxWait := flag.Duration("max-wait", bridge.DefaultMaxWait, "maximal time to collect a response")
+	maxResponse := flag.Int("max-response", bridge.DefaultMaxResponse, "maximal bytes of a response, further output is dropped")
+	stop := flag.String("stop", "", "console command to stop the process, the process gets interrupted if empty")
+	stopTimeout := flag.Duration("stop-timeout", bridge.DefaultStopTimeout, "time to exit after the stop before the process gets killed")
+	dialect := flag.String("dialect", string(client.DialectMinecraft), "dialect of the server behavior for the clients")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: grcon-bridge [flags] -- <command> [args...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "grcon-bridge: ", log.LstdFlags)
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	password := os.Getenv(*passwordEnv)
+	if password == "" {
+		logger.Fatalf("the environment variable %s with the password is not set", *passwordEnv)
+	}
+
+	cmd := exec.Command(flag.Arg(0), flag.Args()[1:]...)
+	b := bridge.New(cmd)
+	b.Prompt = *prompt
+	b.QuietPeriod = *quiet
+	b.MaxWait = *maxWait
+	b.MaxResponse = *maxResponse
+	b.StopCommand = *stop
+	b.StopTimeout = *stopTimeout
+
+	lines, _ := b.Subscribe(100)
+	if err := b.Start(); err != nil {
+		logger.Fatalf("starting the process failed: %s", err.Error())
+	}
+	go func() {
+		for line := range lines {
+			fmt.Println(line)
+		}
+	}()
+
+	srv := &server.Server{
+		Addr:                *listen,
+		Handler:             b,
+		Auth:                server.PasswordAuth(password),
+		AuthGuard:           &server.AuthGuard{OnEvent: server.LogAuthEvents(logger)},
+		SendPreAuthResponse: client.Dialect(*dialect).MirrorsResponseValue(),
+		MirrorEmptyResponse: client.Dialect(*dialect).MirrorsResponseValue(),
+		ErrorLog:            logger,
+	}
+	go func() {
+		logger.Printf("listening on %s", *listen)
+		if err := srv.ListenAndServe(); err != server.ErrServerClosed {
+			logger.Printf("serving RCON failed: %s", err.Error())
+			b.Stop()
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	select {
+	case <-signals:
+		logger.Print("stopping the process")
+		b.Stop()
+	case <-b.Exited():
+	}
+	srv.Close()
+
+	if err := b.Wait(); err != nil {
+		logger.Fatalf("process exited: %s", err.Error())
+	}
+	logger.Print("process exited")
+}
@@ -0,0 +1,54 @@
+// Command grcon-exporter serves metrics of RCON servers for Prometheus.
+//
+// Usage:
+//
+//	grcon-exporter -config exporter.json
+//
+// See the exporter package for the config format and the metrics.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/hamburghammer/grcon/exporter"
+)
+
+func main() {
+	configPath := flag.String("config", "exporter.json", "path to the JSON config file")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "grcon-exporter: ", log.LstdFlags)
+
+	config, err := exporter.LoadConfig(*configPath)
+	if err != nil {
+		logger.Fatalf("loading config failed: %s", err.Error())
+	}
+	if config.Listen == "" {
+		config.Listen = ":9150"
+	}
+
+	e, err := config.NewExporter()
+	if err != nil {
+		logger.Fatalf("creating exporter failed: %s", err.Error())
+	}
+	e.Logger = logger
+	go e.Run(context.Background())
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("grcon-exporter: the metrics are served at /metrics\n"))
+	})
+
+	logger.Printf("listening on %s", config.Listen)
+	err = http.ListenAndServe(config.Listen, mux)
+	logger.Fatalf("serving HTTP failed: %s", err.Error())
+}
@@ -0,0 +1,48 @@
+// Command grcon-gateway exposes the configured RCON servers over HTTP.
+//
+// Usage:
+//
+//	grcon-gateway -config gateway.json
+//
+// See the gateway package for the API and the config format.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/hamburghammer/grcon/gateway"
+)
+
+func main() {
+	configPath := flag.String("config", "gateway.json", "path to the JSON config file")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "grcon-gateway: ", log.LstdFlags)
+
+	config, err := gateway.LoadConfig(*configPath)
+	if err != nil {
+		logger.Fatalf("loading config failed: %s", err.Error())
+	}
+	if config.Listen == "" {
+		config.Listen = ":8080"
+	}
+
+	clients, err := config.NewClients()
+	if err != nil {
+		logger.Fatalf("creating clients failed: %s", err.Error())
+	}
+	consoles, err := config.NewConsoles()
+	if err != nil {
+		logger.Fatalf("creating consoles failed: %s", err.Error())
+	}
+
+	g := gateway.New(clients, config.Tokens, logger)
+	g.Consoles = consoles
+
+	logger.Printf("listening on %s", config.Listen)
+	err = http.ListenAndServe(config.Listen, g)
+	logger.Fatalf("serving HTTP failed: %s", err.Error())
+}
@@ -0,0 +1,52 @@
+// Command grcon-proxy is a RCON reverse proxy with per-user passwords and command rules.
+//
+// Usage:
+//
+//	grcon-proxy -config proxy.json
+//
+// See the proxy package for the config format.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/hamburghammer/grcon/proxy"
+	"github.com/hamburghammer/grcon/server"
+)
+
+func main() {
+	configPath := flag.String("config", "proxy.json", "path to the JSON config file")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "grcon-proxy: ", log.LstdFlags)
+
+	config, err := proxy.LoadConfig(*configPath)
+	if err != nil {
+		logger.Fatalf("loading config failed: %s", err.Error())
+	}
+	if config.Listen == "" {
+		config.Listen = ":27016"
+	}
+
+	upstream, err := config.NewUpstream()
+	if err != nil {
+		logger.Fatalf("creating the upstream client failed: %s", err.Error())
+	}
+	if err := upstream.Auth(config.Upstream.Password); err != nil {
+		logger.Printf("connecting to the upstream failed, retrying with the first command: %s", err.Error())
+	}
+
+	p := proxy.New(upstream, config.Users, logger)
+	srv := p.NewServer(config.Listen, config.Upstream.Dialect)
+	srv.ProxyProtocol = config.ProxyProtocol
+	srv.TrustedProxies, err = server.ParseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		logger.Fatalf("invalid trusted_proxies: %s", err.Error())
+	}
+
+	logger.Printf("listening on %s", config.Listen)
+	err = srv.ListenAndServe()
+	logger.Fatalf("serving RCON failed: %s", err.Error())
+}
@@ -0,0 +1,92 @@
+// Command grcon-tap is a recording man-in-the-middle proxy to debug RCON clients.
+// It forwards the traffic unchanged to the upstream server and prints every packet of both directions.
+//
+// Usage:
+//
+//	grcon-tap -listen :27016 -upstream 127.0.0.1:27015 [-record session.jsonl]
+//
+// Point the client to the listen address instead of the server. The output looks like:
+//
+//	12:00:01.250 #1 client->server id=3 SERVERDATA_EXECCOMMAND size=20 "status"
+//	12:00:01.252 #1 server->client id=3 SERVERDATA_RESPONSE_VALUE size=5010 fragments=2 latency=2ms "hostname: ..."
+//
+// The passwords of SERVERDATA_AUTH packets are masked unless -show-password is set.
+// The recording contains one JSON encoded tap.Event per line with the body encoded as base64.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/tap"
+)
+
+func main() {
+	listen := flag.String("listen", ":27016", "address to listen on for clients")
+	upstream := flag.String("upstream", "", "address of the RCON server")
+	record := flag.String("record", "", "path of a file to record the packets as JSON lines")
+	preview := flag.Int("preview", 120, "maximal number of body characters to print, 0 prints the whole body")
+	showPassword := flag.Bool("show-password", false, "print and record the passwords of auth packets")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "grcon-tap: ", log.LstdFlags)
+	if *upstream == "" {
+		logger.Fatal("the -upstream flag is required")
+	}
+
+	var recorder *json.Encoder
+	if *record != "" {
+		file, err := os.Create(*record)
+		if err != nil {
+			logger.Fatalf("creating the recording failed: %s", err.Error())
+		}
+		recorder = json.NewEncoder(file)
+	}
+
+	t := &tap.Tap{
+		Dial:   client.TCPDialer(*upstream, 10*time.Second),
+		Logger: logger,
+		Handler: func(e tap.Event) {
+			if e.Type == grcon.SERVERDATA_AUTH && e.Direction == tap.ClientToServer && !*showPassword {
+				e.Body = []byte("***")
+			}
+
+			fmt.Println(formatEvent(e, *preview))
+			if recorder != nil {
+				if err := recorder.Encode(e); err != nil {
+					logger.Printf("recording failed: %s", err.Error())
+				}
+			}
+		},
+	}
+
+	logger.Printf("listening on %s and forwarding to %s", *listen, *upstream)
+	err := t.ListenAndServe(*listen)
+	logger.Fatalf("serving failed: %s", err.Error())
+}
+
+// formatEvent formats the event as a single line.
+// Fragments and the latency are only shown if they are interesting.
+func formatEvent(e tap.Event, preview int) string {
+	line := fmt.Sprintf("%s #%d %s id=%d %s size=%d",
+		e.Time.Format("15:04:05.000"), e.Session, e.Direction, e.Id, e.TypeName, e.Size)
+	if e.Fragments > 1 {
+		line += fmt.Sprintf(" fragments=%d", e.Fragments)
+	}
+	if e.Latency > 0 {
+		line += fmt.Sprintf(" latency=%s", e.Latency.Round(time.Microsecond))
+	}
+
+	body := string(e.Body)
+	if preview > 0 && len(body) > preview {
+		body = body[:preview] + "..."
+	}
+	return line + " " + strconv.Quote(body)
+}
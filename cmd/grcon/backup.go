@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hamburghammer/grcon/inventory"
+	"github.com/hamburghammer/grcon/workflow"
+)
+
+// runBackup creates a consistent backup of the world of a minecraft server.
+func runBackup(args []string) int {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	inventoryPath := flags.String("inventory", defaultInventory(), "path to the inventory file, defaults to $GRCON_INVENTORY")
+	world := flags.String("world", "", "directory of the world")
+	destination := flags.String("dest", "", "directory of the snapshots")
+	archive := flags.Bool("tar", false, "write a .tar.gz archive instead of copying the directory")
+	keep := flags.Int("keep", 0, "number of snapshots to keep, 0 keeps all")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: grcon backup <server> -world dir -dest dir [flags]")
+		flags.PrintDefaults()
+	}
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) != 1 || *world == "" || *destination == "" {
+		flags.Usage()
+		return 2
+	}
+	name := positional[0]
+
+	inv, err := inventory.Load(*inventoryPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grcon: loading inventory failed: %s\n", err.Error())
+		return 2
+	}
+	c, err := inv.Client(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grcon: %s\n", err.Error())
+		return 2
+	}
+	defer c.Close()
+
+	var snapshotter workflow.Snapshotter = workflow.DirSnapshot{World: *world, Destination: *destination, Keep: *keep}
+	if *archive {
+		snapshotter = workflow.TarGzSnapshot{World: *world, Destination: *destination, Keep: *keep}
+	}
+	backup := workflow.NewBackup(c, snapshotter)
+	backup.Logger = log.New(os.Stderr, "grcon-backup: ", log.LstdFlags)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	location, err := backup.Run(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grcon: backup of '%s' failed: %s\n", name, err.Error())
+		return 1
+	}
+	fmt.Println(location)
+	return 0
+}
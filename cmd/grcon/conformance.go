@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/conformance"
+)
+
+// runConformance runs the conformance suite against a server address and prints the report.
+// It exits with 1 if a scenario failed.
+func runConformance(args []string) int {
+	flags := flag.NewFlagSet("conformance", flag.ExitOnError)
+	passwordEnv := flags.String("password-env", "GRCON_PASSWORD", "environment variable with the RCON password")
+	command := flags.String("command", conformance.DefaultCommand, "harmless command that is executed by the scenarios")
+	longCommand := flags.String("long-command", "", "command with a response longer than one packet, e.g. cvarlist")
+	timeout := flags.Duration("timeout", conformance.DefaultTimeout, "maximal wait for the first packet of a response")
+	idle := flags.Duration("idle", conformance.DefaultIdleTimeout, "wait after the last packet until a response is complete")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: grcon conformance <address> [flags]")
+		flags.PrintDefaults()
+	}
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	suite := conformance.New(conformance.Dialer(client.TCPDialer(positional[0], 5*time.Second)), os.Getenv(*passwordEnv))
+	suite.Command = *command
+	suite.LongCommand = *longCommand
+	suite.Timeout = *timeout
+	suite.IdleTimeout = *idle
+
+	report := suite.Run()
+	fmt.Print(report)
+	if report.Failed() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/inventory"
+	"github.com/hamburghammer/grcon/scheduler"
+)
+
+// runDaemon runs the jobs of the inventory until it receives SIGINT or SIGTERM.
+func runDaemon(args []string) int {
+	flags := flag.NewFlagSet("daemon", flag.ExitOnError)
+	inventoryPath := flags.String("inventory", defaultInventory(), "path to the inventory file, defaults to $GRCON_INVENTORY")
+	statePath := flags.String("state", "", "path of a file to remember the last runs for the missed run policy")
+	flags.Parse(args)
+
+	logger := log.New(os.Stderr, "grcon-daemon: ", log.LstdFlags)
+
+	inv, err := inventory.Load(*inventoryPath)
+	if err != nil {
+		logger.Printf("loading inventory failed: %s", err.Error())
+		return 2
+	}
+	jobs, err := inv.SchedulerJobs()
+	if err != nil {
+		logger.Printf("%s", err.Error())
+		return 2
+	}
+
+	// all jobs share one long-lived client per server.
+	clients := map[string]client.Client{}
+	for _, job := range jobs {
+		for _, name := range job.Servers {
+			if _, ok := clients[name]; ok {
+				continue
+			}
+			c, err := inv.Client(name)
+			if err != nil {
+				logger.Printf("%s", err.Error())
+				return 2
+			}
+			defer c.Close()
+			clients[name] = c
+		}
+	}
+
+	for i, job := range inv.Jobs {
+		if job.Output == "" {
+			continue
+		}
+		file, err := os.OpenFile(job.Output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			logger.Printf("opening output of job '%s' failed: %s", job.Name, err.Error())
+			return 2
+		}
+		defer file.Close()
+		jobs[i].Output = file
+	}
+
+	s := scheduler.New(clients, jobs, logger)
+	s.StatePath = *statePath
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Printf("running %d jobs on %d servers", len(jobs), len(clients))
+	if err := s.Run(ctx); err != nil {
+		logger.Printf("%s", err.Error())
+		return 1
+	}
+	logger.Print("stopped")
+	return 0
+}
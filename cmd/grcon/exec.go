@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hamburghammer/grcon/fleet"
+	"github.com/hamburghammer/grcon/inventory"
+)
+
+// runExec executes a command on the selected servers and prints the results.
+// Returns 1 if the command failed on a server.
+func runExec(args []string) int {
+	flags := flag.NewFlagSet("exec", flag.ExitOnError)
+	inventoryPath := flags.String("inventory", defaultInventory(), "path to the inventory file, defaults to $GRCON_INVENTORY")
+	all := flags.Bool("all", false, "execute the command on all servers")
+	selector := flags.String("select", "", "selector of the servers like tag=eu,game=cs2")
+	servers := flags.String("servers", "", "comma separated list of servers")
+	group := flags.Bool("group", false, "group servers with identical output")
+	concurrency := flags.Int("concurrency", fleet.DefaultConcurrency, "maximal number of servers that execute the command at the same time")
+	timeout := flags.Duration("timeout", fleet.DefaultTimeout, "timeout for a single server")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: grcon exec [flags] (-all | -select selector | -servers a,b) <command>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	cmd := strings.Join(flags.Args(), " ")
+	if cmd == "" || countSet(*all, *selector != "", *servers != "") != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	inv, err := inventory.Load(*inventoryPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grcon: loading inventory failed: %s\n", err.Error())
+		return 2
+	}
+
+	var names []string
+	switch {
+	case *all:
+		names = inv.Names()
+	case *selector != "":
+		names, err = inv.Select(*selector)
+	default:
+		names = strings.Split(*servers, ",")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grcon: %s\n", err.Error())
+		return 2
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(os.Stderr, "grcon: no servers selected")
+		return 2
+	}
+
+	clients, err := inv.Clients(names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grcon: %s\n", err.Error())
+		return 2
+	}
+
+	f := fleet.New(clients)
+	f.Concurrency = *concurrency
+	f.Timeout = *timeout
+	defer closeClients(f)
+
+	results := f.Exec(context.Background(), names, cmd)
+	if *group {
+		printGroups(os.Stdout, results.Group())
+	} else {
+		printResults(os.Stdout, names, results)
+	}
+
+	failed := results.Failed()
+	fmt.Fprintf(os.Stderr, "%d succeeded, %d failed\n", len(results)-len(failed), len(failed))
+	if len(failed) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printResults(w io.Writer, names []string, results fleet.Results) {
+	for _, name := range names {
+		result := results[name]
+		fmt.Fprintf(w, "== %s (%s)\n", name, result.Duration.Round(time.Millisecond))
+		printOutput(w, result.Response, result.Err)
+	}
+}
+
+func printGroups(w io.Writer, groups []fleet.Group) {
+	for _, group := range groups {
+		count := fmt.Sprintf("%d servers", len(group.Servers))
+		if len(group.Servers) == 1 {
+			count = "1 server"
+		}
+		fmt.Fprintf(w, "== %s (%s)\n", strings.Join(group.Servers, ", "), count)
+		printOutput(w, group.Response, group.Err)
+	}
+}
+
+func printOutput(w io.Writer, response string, err error) {
+	if err != nil {
+		fmt.Fprintf(w, "error: %s\n", err.Error())
+		return
+	}
+	response = strings.TrimRight(response, "\n")
+	if response != "" {
+		fmt.Fprintln(w, response)
+	}
+}
+
+// closeClients closes the connections of all clients that implement io.Closer.
+func closeClients(f *fleet.Fleet) {
+	for _, c := range f.Clients {
+		if closer, ok := c.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+// defaultInventory returns the inventory path of the environment or "grcon.json".
+func defaultInventory() string {
+	if path := os.Getenv("GRCON_INVENTORY"); path != "" {
+		return path
+	}
+	return "grcon.json"
+}
+
+// countSet returns the number of true values.
+func countSet(values ...bool) int {
+	n := 0
+	for _, value := range values {
+		if value {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,58 @@
+// Command grcon executes commands on the configured RCON servers.
+//
+// Usage:
+//
+//	grcon exec [-inventory grcon.json] (-all | -select tag=eu | -servers a,b) [-group] <command>
+//	grcon daemon [-inventory grcon.json] [-state state.json]
+//	grcon restart <server> [-inventory grcon.json] [-in 10m] [-kick message]
+//	grcon backup <server> [-inventory grcon.json] -world dir -dest dir [-tar] [-keep 7]
+//	grcon conformance <address> [-password-env GRCON_PASSWORD] [-long-command cvarlist]
+//
+// The servers and the jobs of the daemon are read from an inventory file (see the inventory package).
+// Flags can also be written with two dashes like --all.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: grcon <command> [flags]
+
+Commands:
+  exec         execute a RCON command on one or many servers
+  daemon       run the scheduled jobs of the inventory
+  restart      announce a restart and stop a server gracefully
+  backup       back up the world of a minecraft server
+  conformance  report the protocol quirks of a server
+
+Run "grcon <command> -h" to show the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var code int
+	switch os.Args[1] {
+	case "exec":
+		code = runExec(os.Args[2:])
+	case "daemon":
+		code = runDaemon(os.Args[2:])
+	case "restart":
+		code = runRestart(os.Args[2:])
+	case "backup":
+		code = runBackup(os.Args[2:])
+	case "conformance":
+		code = runConformance(os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "grcon: unknown command '%s'\n\n%s", os.Args[1], usage)
+		code = 2
+	}
+
+	os.Exit(code)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hamburghammer/grcon/inventory"
+	"github.com/hamburghammer/grcon/workflow"
+)
+
+// runRestart announces a restart of a server, stops it and waits until it is down.
+// Interrupting the countdown cancels the restart.
+func runRestart(args []string) int {
+	flags := flag.NewFlagSet("restart", flag.ExitOnError)
+	inventoryPath := flags.String("inventory", defaultInventory(), "path to the inventory file, defaults to $GRCON_INVENTORY")
+	in := flags.Duration("in", 0, "time until the server gets stopped")
+	warnings := flags.String("warnings", "", "comma separated remaining times of the announcements, defaults to 30m,15m,10m,5m,1m,30s,10s")
+	message := flags.String("message", workflow.DefaultRestartMessage, "announcement, %s is replaced by the remaining time")
+	kick := flags.String("kick", "", "kick all players with the message before the stop")
+	broadcast := flags.String("broadcast", "", "broadcast command like 'AdminBroadcast %s', defaults to the one of the dialect, - disables it")
+	save := flags.String("save", "", "save command, defaults to the one of the dialect, - disables it")
+	stop := flags.String("stop", "", "stop command, defaults to the one of the dialect")
+	downTimeout := flags.Duration("down-timeout", workflow.DefaultDownTimeout, "maximal wait for the server to close the connection")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: grcon restart <server> [flags]")
+		flags.PrintDefaults()
+	}
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) != 1 {
+		flags.Usage()
+		return 2
+	}
+	name := positional[0]
+
+	var warningTimes []time.Duration
+	if *warnings != "" {
+		for _, value := range strings.Split(*warnings, ",") {
+			warning, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "grcon: invalid warning '%s': %s\n", value, err.Error())
+				return 2
+			}
+			warningTimes = append(warningTimes, warning)
+		}
+	}
+
+	inv, err := inventory.Load(*inventoryPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grcon: loading inventory failed: %s\n", err.Error())
+		return 2
+	}
+	c, err := inv.Client(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grcon: %s\n", err.Error())
+		return 2
+	}
+	defer c.Close()
+
+	restart := workflow.NewRestart(c, *in)
+	if warningTimes != nil {
+		restart.Warnings = warningTimes
+	}
+	restart.Message = *message
+	restart.KickMessage = *kick
+	restart.Commands = workflow.Commands{Broadcast: *broadcast, Save: *save, Stop: *stop}
+	restart.DownTimeout = *downTimeout
+	restart.Logger = log.New(os.Stderr, "grcon-restart: ", log.LstdFlags)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := restart.Run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "grcon: restart of '%s' failed: %s\n", name, err.Error())
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "%s is down\n", name)
+	return 0
+}
+
+// parseInterspersed parses the flags before and after the positional arguments
+// like in "grcon restart survival --in 10m" and returns the positional arguments.
+func parseInterspersed(flags *flag.FlagSet, args []string) []string {
+	positional := []string{}
+	for {
+		flags.Parse(args)
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
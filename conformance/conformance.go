@@ -0,0 +1,272 @@
+/*
+Package conformance runs a standard scenario suite against a RCON server and
+reports the quirks of its implementation.
+
+The scenarios cover the authentication, empty commands, the longest accepted
+command, multi-packet responses, the mirrored delimiter packet and pipelined
+requests. Every scenario uses its own connection, because servers close the
+connection on some of them:
+
+	suite := conformance.New(conformance.Dialer(client.TCPDialer("127.0.0.1:27015", 5*time.Second)), "secret")
+	suite.LongCommand = "cvarlist"
+	report := suite.Run()
+	fmt.Print(report)
+
+The report recommends the client.Dialect and the max_command_length of the
+inventory for the server. Probe runs the scenarios the dialects differ in,
+identifies the game and returns a client configured for it:
+
+	c, detection, err := conformance.Probe(client.TCPDialer(addr, 5*time.Second), password)
+*/
+package conformance
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/util"
+)
+
+const (
+	// DefaultTimeout is the default maximal wait for the first packet of a response.
+	DefaultTimeout = 2 * time.Second
+	// DefaultIdleTimeout is the default wait after the last packet until a response is complete.
+	DefaultIdleTimeout = 500 * time.Millisecond
+	// DefaultCommand is the default harmless command of the scenarios.
+	DefaultCommand = "help"
+)
+
+// errNoResponse is returned if the server did not answer in time.
+var errNoResponse = errors.New("conformance: no response")
+
+// DialFunc opens a new connection to the server under test.
+type DialFunc func() (util.RemoteConsole, error)
+
+// Dialer returns a DialFunc that opens a grcon.RemoteConsole on the connections of the dial function,
+// e.g. of a client.TCPDialer or client.TLSDialer.
+func Dialer(dial client.DialFunc) DialFunc {
+	return func() (util.RemoteConsole, error) {
+		conn, err := dial()
+		if err != nil {
+			return nil, err
+		}
+		return grcon.NewRemoteConsole(conn), nil
+	}
+}
+
+// New is a constructor for the Suite struct.
+func New(dial DialFunc, password string) *Suite {
+	return &Suite{
+		Dial:        dial,
+		Password:    password,
+		Command:     DefaultCommand,
+		Timeout:     DefaultTimeout,
+		IdleTimeout: DefaultIdleTimeout,
+	}
+}
+
+// Suite runs the scenarios against a server.
+type Suite struct {
+	// Dial opens a new connection for every scenario.
+	// Connections that implement io.Closer or are a *grcon.RemoteConsole get closed after the scenario.
+	Dial DialFunc
+	// Password of the server.
+	Password string
+	// Command is a harmless command that is executed by the scenarios. Its output does not matter.
+	Command string
+	// LongCommand is a command with a response longer than a single packet, e.g. "cvarlist".
+	// The multi-packet scenario is skipped without it.
+	LongCommand string
+	// Timeout is the maximal wait for the first packet of a response.
+	Timeout time.Duration
+	// IdleTimeout is the wait after the last packet until a response is complete.
+	IdleTimeout time.Duration
+
+	lastId grcon.PacketId
+}
+
+// Run runs all scenarios and returns the report.
+// The scenarios that require an authentication are skipped if the authentication fails.
+func (s *Suite) Run() Report {
+	report := Report{}
+
+	run := func(name string, scenario func(*Quirks) (Status, string)) Status {
+		start := time.Now()
+		status, detail := scenario(&report.Quirks)
+		report.Results = append(report.Results, Result{
+			Scenario: name,
+			Status:   status,
+			Detail:   detail,
+			Duration: time.Since(start),
+		})
+		return status
+	}
+
+	authStatus := run("auth", s.auth)
+	run("auth failure", s.authFailure)
+
+	scenarios := []struct {
+		name     string
+		scenario func(*Quirks) (Status, string)
+	}{
+		{"empty command", s.emptyCommand},
+		{"max-size request", s.maxSizeRequest},
+		{"multi-packet response", s.multiPacketResponse},
+		{"mirrored delimiter", s.mirroredDelimiter},
+		{"pipelined requests", s.pipelinedRequests},
+	}
+	for _, sc := range scenarios {
+		if authStatus == Fail {
+			report.Results = append(report.Results, Result{Scenario: sc.name, Status: Skip, Detail: "authentication failed"})
+			continue
+		}
+		run(sc.name, sc.scenario)
+	}
+
+	return report
+}
+
+// nextId returns a new packet id. The ids are unique within a suite.
+func (s *Suite) nextId() grcon.PacketId {
+	s.lastId++
+	return s.lastId
+}
+
+// open opens a new session on a connection of the Dial function.
+func (s *Suite) open() (*session, error) {
+	console, err := s.Dial()
+	if err != nil {
+		return nil, err
+	}
+
+	sess := &session{
+		console: console,
+		packets: make(chan grcon.Packet, 64),
+		done:    make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+	go sess.read()
+	return sess, nil
+}
+
+// authenticate opens a session and authenticates it.
+func (s *Suite) authenticate() (*session, error) {
+	sess, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+
+	id := s.nextId()
+	if err := sess.console.Write(grcon.Packet{Id: id, Type: grcon.SERVERDATA_AUTH, Body: []byte(s.Password)}); err != nil {
+		sess.close()
+		return nil, err
+	}
+	packet, _, err := s.readAuthResponse(sess)
+	if err != nil {
+		sess.close()
+		return nil, err
+	}
+	if packet.Id != id {
+		sess.close()
+		return nil, errors.New("authentication failed")
+	}
+	return sess, nil
+}
+
+// readAuthResponse reads until the SERVERDATA_AUTH_RESPONSE and reports if an
+// empty SERVERDATA_RESPONSE_VALUE came before it.
+func (s *Suite) readAuthResponse(sess *session) (grcon.Packet, bool, error) {
+	emptyResponse := false
+	for {
+		packet, err := sess.next(s.Timeout)
+		if err != nil {
+			return grcon.Packet{}, emptyResponse, err
+		}
+		if packet.Type == grcon.SERVERDATA_AUTH_RESPONSE {
+			return packet, emptyResponse, nil
+		}
+		if packet.Type == grcon.SERVERDATA_RESPONSE_VALUE && len(packet.Body) == 0 {
+			emptyResponse = true
+		}
+	}
+}
+
+// session reads the packets of a connection in the background to read them with a timeout.
+type session struct {
+	console util.RemoteConsole
+	packets chan grcon.Packet
+	// done is closed after the first read error that is stored in err.
+	done chan struct{}
+	err  error
+	// stop ends the reading after the session was closed.
+	stop chan struct{}
+}
+
+func (sess *session) read() {
+	for {
+		packet, err := sess.console.Read()
+		if err != nil {
+			sess.err = err
+			close(sess.done)
+			return
+		}
+		select {
+		case sess.packets <- packet:
+		case <-sess.stop:
+			return
+		}
+	}
+}
+
+// next returns the next packet. It returns errNoResponse if no packet arrived
+// within the timeout and the read error if the connection ended.
+func (sess *session) next(timeout time.Duration) (grcon.Packet, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case packet := <-sess.packets:
+		return packet, nil
+	case <-sess.done:
+		// packets that were read before the error come first.
+		select {
+		case packet := <-sess.packets:
+			return packet, nil
+		default:
+			return grcon.Packet{}, sess.err
+		}
+	case <-timer.C:
+		return grcon.Packet{}, errNoResponse
+	}
+}
+
+// collect reads packets until no packet arrived for the idle timeout.
+// The first packet may take up to the timeout. The error is the read error if the connection ended.
+func (sess *session) collect(timeout, idle time.Duration) ([]grcon.Packet, error) {
+	packets := []grcon.Packet{}
+	for {
+		packet, err := sess.next(timeout)
+		if err == errNoResponse {
+			return packets, nil
+		}
+		if err != nil {
+			return packets, err
+		}
+		packets = append(packets, packet)
+		timeout = idle
+	}
+}
+
+// close stops the reading and closes the connection if possible.
+func (sess *session) close() {
+	close(sess.stop)
+	switch console := sess.console.(type) {
+	case io.Closer:
+		console.Close()
+	case *grcon.RemoteConsole:
+		console.Conn.Close()
+	}
+}
@@ -0,0 +1,134 @@
+package conformance_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/conformance"
+	"github.com/hamburghammer/grcon/emulator"
+)
+
+// longResponse is split into two packets of the grcon.MaxBody.
+var longResponse = strings.Repeat("0123456789", 500)
+
+// newSuite serves the emulator on a loopback address and returns a suite for it.
+func newSuite(t *testing.T, serve func(l net.Listener) error, close func() error, password string) *conformance.Suite {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go serve(l)
+	t.Cleanup(func() { close() })
+
+	suite := conformance.New(conformance.Dialer(client.TCPDialer(l.Addr().String(), time.Second)), password)
+	suite.LongCommand = "dump"
+	suite.Timeout = time.Second
+	suite.IdleTimeout = 50 * time.Millisecond
+	return suite
+}
+
+func statuses(report conformance.Report) string {
+	parts := []string{}
+	for _, result := range report.Results {
+		parts = append(parts, result.Scenario+"="+result.Status.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestSuite_Source(t *testing.T) {
+	src := emulator.NewSource("secret")
+	src.HandleFunc("dump", func(args string) string { return longResponse })
+	report := newSuite(t, src.Serve, src.Close, "secret").Run()
+
+	expected := "auth=PASS,auth failure=PASS,empty command=PASS,max-size request=PASS,multi-packet response=PASS,mirrored delimiter=PASS,pipelined requests=PASS"
+	if got := statuses(report); got != expected {
+		t.Errorf("statuses did not match:\nexpected: %s\ngot: %s\n%s", expected, got, report)
+	}
+
+	expectedQuirks := conformance.Quirks{
+		AuthEmptyResponse:    true,
+		EmptyCommandResponse: true,
+		MaxCommandLength:     int(grcon.MaxBody),
+		ResponsePackets:      2,
+		MaxResponseBody:      int(grcon.MaxBody),
+		MirrorsResponseValue: true,
+		DelimiterTrailer:     true,
+		Pipelining:           true,
+		PipeliningInOrder:    true,
+	}
+	if report.Quirks != expectedQuirks {
+		t.Errorf("quirks did not match:\nexpected: %+v\ngot: %+v\n", expectedQuirks, report.Quirks)
+	}
+	if report.Dialect() != client.DialectSource || report.MaxCommandLength() != 0 || report.Failed() {
+		t.Errorf("recommendation did not match:\nexpected: source 0 false\ngot: %s %d %t\n", report.Dialect(), report.MaxCommandLength(), report.Failed())
+	}
+}
+
+func TestSuite_Minecraft(t *testing.T) {
+	t.Run("vanilla fragments", func(t *testing.T) {
+		mc := emulator.NewMinecraft("secret")
+		mc.HandleFunc("dump", func(args string) string { return longResponse })
+		report := newSuite(t, mc.Serve, mc.Close, "secret").Run()
+
+		expected := "auth=QUIRK,auth failure=PASS,empty command=PASS,max-size request=QUIRK,multi-packet response=FAIL,mirrored delimiter=QUIRK,pipelined requests=PASS"
+		if got := statuses(report); got != expected {
+			t.Errorf("statuses did not match:\nexpected: %s\ngot: %s\n%s", expected, got, report)
+		}
+		q := report.Quirks
+		if q.AuthEmptyResponse || q.MaxCommandLength != emulator.MinecraftMaxCommandLength || !q.OversizedCommandClose ||
+			!q.OversizedResponse || q.MirrorsResponseValue || q.ResponseValueAnswer != "Unknown request 0" {
+			t.Errorf("quirks did not match: %+v\n", q)
+		}
+		if report.Dialect() != client.DialectMinecraft || report.MaxCommandLength() != 0 || !report.Failed() {
+			t.Errorf("recommendation did not match:\nexpected: minecraft 0 true\ngot: %s %d %t\n", report.Dialect(), report.MaxCommandLength(), report.Failed())
+		}
+	})
+
+	t.Run("fragments of the grcon.MaxBody", func(t *testing.T) {
+		mc := emulator.NewMinecraft("secret")
+		mc.FragmentSize = int(grcon.MaxBody)
+		mc.HandleFunc("dump", func(args string) string { return longResponse })
+		report := newSuite(t, mc.Serve, mc.Close, "secret").Run()
+
+		if report.Failed() || report.Quirks.ResponsePackets != 2 {
+			t.Errorf("expected the multi-packet response to pass:\n%s", report)
+		}
+	})
+}
+
+func TestSuite_WrongPassword(t *testing.T) {
+	src := emulator.NewSource("secret")
+	report := newSuite(t, src.Serve, src.Close, "wrong").Run()
+
+	expected := "auth=FAIL,auth failure=PASS,empty command=SKIP,max-size request=SKIP,multi-packet response=SKIP,mirrored delimiter=SKIP,pipelined requests=SKIP"
+	if got := statuses(report); got != expected {
+		t.Errorf("statuses did not match:\nexpected: %s\ngot: %s\n", expected, got)
+	}
+	if report.Dialect() != "" || !report.Failed() {
+		t.Errorf("expected no dialect and a failed report but got '%s' %t\n", report.Dialect(), report.Failed())
+	}
+}
+
+func TestReport_String(t *testing.T) {
+	report := conformance.Report{
+		Results: []conformance.Result{
+			{Scenario: "max-size request", Status: conformance.Quirk, Detail: "accepts commands up to 1000 bytes and ignores longer ones"},
+			{Scenario: "mirrored delimiter", Status: conformance.Pass, Detail: "mirrored after the response"},
+		},
+		Quirks: conformance.Quirks{MaxCommandLength: 1000, MirrorsResponseValue: true},
+	}
+
+	expected := `QUIRK  max-size request       accepts commands up to 1000 bytes and ignores longer ones
+PASS   mirrored delimiter     mirrored after the response
+
+dialect: simple
+max_command_length: 1000
+`
+	if got := report.String(); got != expected {
+		t.Errorf("report did not match:\nexpected: %s\ngot: %s\n", expected, got)
+	}
+}
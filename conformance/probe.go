@@ -0,0 +1,196 @@
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hamburghammer/grcon/client"
+)
+
+// ProbeCommand is the Command of the Probe.
+// Factorio broadcasts commands without a slash as chat message, other servers answer it as unknown command.
+const ProbeCommand = "/help"
+
+// Game is a server software that the Probe tells apart.
+type Game string
+
+// Detected games.
+const (
+	GameSource     Game = "source"
+	GameMinecraft  Game = "minecraft"
+	GameFactorio   Game = "factorio"
+	GameSquad      Game = "squad"
+	GameRustLegacy Game = "rust-legacy"
+)
+
+var (
+	// factorioVersion matches the response of the /version command of Factorio.
+	factorioVersion = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+	// rustLegacyPlayers matches the players line of the status of Rust Legacy.
+	// Source servers list humans and bots instead.
+	rustLegacyPlayers = regexp.MustCompile(`(?m)^players\s*:\s*\d+ \(\d+ max\)`)
+	// statusVersion matches the version line of a status.
+	statusVersion = regexp.MustCompile(`(?m)^version\s*:\s*(.+)$`)
+	// exeVersion matches the version of the version command of Source servers.
+	exeVersion = regexp.MustCompile(`(?m)^Exe version (.+)$`)
+)
+
+// Detection is the result of a probe.
+type Detection struct {
+	// Game is empty if the server software is unknown.
+	Game Game
+	// Dialect is the client dialect that fits the server.
+	Dialect client.Dialect
+	// MaxCommandLength is the length of the longest answered command.
+	MaxCommandLength int
+	// Version is the version reported by the server. It is empty if the game has no version command.
+	Version string
+	// Quirks are the protocol behaviors the detection is based on.
+	Quirks Quirks
+}
+
+// Probe works out which client fits the unknown server behind the dial function and returns
+// a ReconnectingClient with its dialect and command length limit.
+// The connection of the client gets established with the first command, its Timeout is not set.
+//
+// It checks the empty packet before the auth response, the mirroring of empty
+// SERVERDATA_RESPONSE_VALUE packets and the longest accepted command and tells
+// the games apart with their version and info commands.
+func Probe(dial client.DialFunc, password string) (*client.ReconnectingClient, Detection, error) {
+	suite := New(Dialer(dial), password)
+	suite.Command = ProbeCommand
+
+	detection, err := suite.Detect()
+	if err != nil {
+		return nil, detection, err
+	}
+
+	c := client.NewReconnectingClient(dial, detection.Dialect, password)
+	c.MaxCommandLength = detection.MaxCommandLength
+	return c, detection, nil
+}
+
+// Detect runs the scenarios the dialects differ in and identifies the game.
+// The Command should not be a chat message on any server, the ProbeCommand is a safe choice.
+//
+// It returns an error if the authentication, the mirrored delimiter or the max-size request scenario fails.
+func (s *Suite) Detect() (Detection, error) {
+	q := Quirks{}
+	steps := []struct {
+		name     string
+		scenario func(*Quirks) (Status, string)
+	}{
+		{"auth", s.auth},
+		{"mirrored delimiter", s.mirroredDelimiter},
+		{"max-size request", s.maxSizeRequest},
+	}
+	for _, step := range steps {
+		if status, detail := step.scenario(&q); status == Fail {
+			return Detection{Quirks: q}, fmt.Errorf("conformance: %s: %s", step.name, detail)
+		}
+	}
+
+	detection := Detection{MaxCommandLength: q.MaxCommandLength, Quirks: q}
+	game, version, err := s.identify(q)
+	if err != nil {
+		return detection, fmt.Errorf("conformance: identify: %s", s.describe(err))
+	}
+	detection.Game = game
+	detection.Version = version
+
+	switch game {
+	case GameSource:
+		detection.Dialect = client.DialectSource
+	case GameMinecraft:
+		detection.Dialect = client.DialectMinecraft
+	case GameFactorio:
+		detection.Dialect = client.DialectFactorio
+	default:
+		// the SourceClient parses the status of Source servers, other games use the plain protocol.
+		detection.Dialect = q.dialect()
+		if detection.Dialect == client.DialectSource {
+			detection.Dialect = client.DialectSimple
+		}
+	}
+	return detection, nil
+}
+
+// identify tells the games apart by their behavior and the output of their commands.
+// Only commands that do not change the state of the server are executed.
+func (s *Suite) identify(q Quirks) (Game, string, error) {
+	if strings.HasPrefix(q.ResponseValueAnswer, "Unknown request") {
+		return GameMinecraft, "", nil
+	}
+
+	sess, err := s.authenticate()
+	if err != nil {
+		return "", "", err
+	}
+	defer sess.close()
+
+	if !q.AuthEmptyResponse {
+		// Factorio skips the empty packet before the auth response.
+		output, err := s.output(sess, "/version")
+		if err != nil {
+			return "", "", err
+		}
+		if output = strings.TrimSpace(output); factorioVersion.MatchString(output) {
+			return GameFactorio, output, nil
+		}
+		return "", "", nil
+	}
+
+	output, err := s.output(sess, "ShowServerInfo")
+	if err != nil {
+		return "", "", err
+	}
+	info := map[string]interface{}{}
+	if json.Unmarshal([]byte(output), &info) == nil {
+		if _, ok := info["ServerName_s"]; ok {
+			version, _ := info["GameVersion_s"].(string)
+			return GameSquad, version, nil
+		}
+	}
+
+	output, err = s.output(sess, "status")
+	if err != nil {
+		return "", "", err
+	}
+	if rustLegacyPlayers.MatchString(output) {
+		return GameRustLegacy, submatch(statusVersion, output), nil
+	}
+
+	output, err = s.output(sess, "version")
+	if err != nil {
+		return "", "", err
+	}
+	if strings.HasPrefix(output, "Protocol version") {
+		return GameSource, submatch(exeVersion, output), nil
+	}
+	return "", "", nil
+}
+
+// output executes the command and returns the concatenated bodies of the response.
+func (s *Suite) output(sess *session, cmd string) (string, error) {
+	id, packets, err := s.exec(sess, cmd)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, packet := range withId(packets, id) {
+		sb.Write(packet.Body)
+	}
+	return sb.String(), nil
+}
+
+// submatch returns the first group of the regex in the text.
+func submatch(regex *regexp.Regexp, text string) string {
+	match := regex.FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSpace(match[1])
+}
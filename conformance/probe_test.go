@@ -0,0 +1,160 @@
+package conformance_test
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/conformance"
+	"github.com/hamburghammer/grcon/emulator"
+	"github.com/hamburghammer/grcon/server"
+)
+
+// newProbeSuite returns a suite with the ProbeCommand for the emulator.
+func newProbeSuite(t *testing.T, serve func(l net.Listener) error, close func() error) *conformance.Suite {
+	suite := newSuite(t, serve, close, "secret")
+	suite.Command = conformance.ProbeCommand
+	return suite
+}
+
+const rustLegacyStatus = `hostname: Rust Legacy Server
+version : 1069 secure (secure mode enabled, connected to Steam3)
+map     : rust_island_2013
+players : 0 (100 max)
+
+id        name        ping  connected     ip
+`
+
+const squadServerInfo = `{"MaxPlayers":100,"GameMode_s":"RAAS","MapName_s":"Narva_RAAS_v1","GameVersion_s":"v8.0.1.371583.1006","ServerName_s":"Squad Server","PlayerCount_I":"0"}`
+
+func TestSuite_Detect(t *testing.T) {
+	t.Run("source", func(t *testing.T) {
+		src := emulator.NewSource("secret")
+		detection, err := newProbeSuite(t, src.Serve, src.Close).Detect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "source source 4086 8835751 (tf)"
+		got := fmt.Sprintf("%s %s %d %s", detection.Game, detection.Dialect, detection.MaxCommandLength, detection.Version)
+		if got != expected {
+			t.Errorf("detection did not match:\nexpected: %s\ngot: %s\n", expected, got)
+		}
+	})
+
+	t.Run("minecraft", func(t *testing.T) {
+		mc := emulator.NewMinecraft("secret")
+		detection, err := newProbeSuite(t, mc.Serve, mc.Close).Detect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "minecraft minecraft 1446 "
+		got := fmt.Sprintf("%s %s %d %s", detection.Game, detection.Dialect, detection.MaxCommandLength, detection.Version)
+		if got != expected {
+			t.Errorf("detection did not match:\nexpected: %s\ngot: %s\n", expected, got)
+		}
+	})
+
+	t.Run("squad", func(t *testing.T) {
+		src := emulator.NewSource("secret")
+		src.HandleFunc("ShowServerInfo", func(args string) string { return squadServerInfo })
+		detection, err := newProbeSuite(t, src.Serve, src.Close).Detect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "squad simple v8.0.1.371583.1006"
+		got := fmt.Sprintf("%s %s %s", detection.Game, detection.Dialect, detection.Version)
+		if got != expected {
+			t.Errorf("detection did not match:\nexpected: %s\ngot: %s\n", expected, got)
+		}
+	})
+
+	t.Run("rust legacy", func(t *testing.T) {
+		src := emulator.NewSource("secret")
+		src.HandleFunc("status", func(args string) string { return rustLegacyStatus })
+		detection, err := newProbeSuite(t, src.Serve, src.Close).Detect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "rust-legacy simple 1069 secure (secure mode enabled, connected to Steam3)"
+		got := fmt.Sprintf("%s %s %s", detection.Game, detection.Dialect, detection.Version)
+		if got != expected {
+			t.Errorf("detection did not match:\nexpected: %s\ngot: %s\n", expected, got)
+		}
+	})
+
+	t.Run("factorio", func(t *testing.T) {
+		var mutex sync.Mutex
+		commands := []string{}
+		// a server like Factorio without the empty packet before the auth response and without mirroring.
+		srv := &server.Server{
+			Auth: server.PasswordAuth("secret"),
+			Handler: server.HandlerFunc(func(w server.ResponseWriter, r *server.Request) {
+				mutex.Lock()
+				commands = append(commands, r.Command)
+				mutex.Unlock()
+				if r.Command == "/version" {
+					fmt.Fprint(w, "1.1.100\n")
+					return
+				}
+				fmt.Fprintf(w, "Unknown command \"%s\".", strings.TrimPrefix(r.Command, "/"))
+			}),
+		}
+		detection, err := newProbeSuite(t, srv.Serve, srv.Close).Detect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "factorio factorio 1.1.100"
+		got := fmt.Sprintf("%s %s %s", detection.Game, detection.Dialect, detection.Version)
+		if got != expected {
+			t.Errorf("detection did not match:\nexpected: %s\ngot: %s\n", expected, got)
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, command := range commands {
+			if !strings.HasPrefix(command, "/") {
+				t.Errorf("expected only commands with a slash but got '%s'\n", command)
+			}
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		src := emulator.NewSource("other")
+		if _, err := newProbeSuite(t, src.Serve, src.Close).Detect(); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestProbe(t *testing.T) {
+	mc := emulator.NewMinecraft("secret")
+	mc.Join("Steve")
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go mc.Serve(l)
+	defer mc.Close()
+
+	c, detection, err := conformance.Probe(client.TCPDialer(l.Addr().String(), time.Second), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.Dialect != client.DialectMinecraft || c.MaxCommandLength != 1446 || detection.Game != conformance.GameMinecraft {
+		t.Fatalf("client did not match:\nexpected: minecraft 1446\ngot: %s %d\n", c.Dialect, c.MaxCommandLength)
+	}
+
+	c.Timeout = 5 * time.Second
+	response, err := c.Exec("list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(response), "Steve") {
+		t.Errorf("response did not contain Steve: %s\n", response)
+	}
+}
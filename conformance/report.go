@@ -0,0 +1,150 @@
+package conformance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+)
+
+// Status of a scenario.
+type Status int
+
+const (
+	// Pass means that the server behaves like described by the Source RCON protocol.
+	Pass Status = iota
+	// Quirk means that the server works but deviates from the Source RCON protocol.
+	Quirk
+	// Fail means that the scenario failed.
+	Fail
+	// Skip means that the scenario did not run.
+	Skip
+)
+
+// String returns the name of the status.
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "PASS"
+	case Quirk:
+		return "QUIRK"
+	case Fail:
+		return "FAIL"
+	case Skip:
+		return "SKIP"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Result of a scenario.
+type Result struct {
+	Scenario string
+	Status   Status
+	// Detail describes the observed behavior.
+	Detail   string
+	Duration time.Duration
+}
+
+// Quirks are the observed behaviors of the server.
+type Quirks struct {
+	// AuthEmptyResponse is true if an empty SERVERDATA_RESPONSE_VALUE is sent before the auth response.
+	AuthEmptyResponse bool
+	// AuthFailureClose is true if the connection gets closed after a failed authentication.
+	AuthFailureClose bool
+	// EmptyCommandResponse is true if commands with an empty body are answered.
+	EmptyCommandResponse bool
+	// MaxCommandLength is the length of the longest answered command.
+	MaxCommandLength int
+	// OversizedCommandClose is true if the connection gets closed on longer commands.
+	OversizedCommandClose bool
+	// ResponsePackets is the number of packets of the response of the long command.
+	ResponsePackets int
+	// MaxResponseBody is the length of the longest body of those packets.
+	MaxResponseBody int
+	// OversizedResponse is true if a response packet is longer than grcon.MaxPacket.
+	OversizedResponse bool
+	// MirrorsResponseValue is true if empty SERVERDATA_RESPONSE_VALUE packets are mirrored.
+	MirrorsResponseValue bool
+	// DelimiterTrailer is true if the mirrored packet is followed by a packet with the body 0x00 0x01 0x00 0x00.
+	DelimiterTrailer bool
+	// ResponseValueAnswer is the body of the answer to an empty SERVERDATA_RESPONSE_VALUE if it is not mirrored.
+	ResponseValueAnswer string
+	// Pipelining is true if all commands that were written before reading the responses are answered.
+	Pipelining bool
+	// PipeliningInOrder is true if they are answered in the order of the commands.
+	PipeliningInOrder bool
+}
+
+// dialect returns the dialect that fits the protocol behavior.
+func (q Quirks) dialect() client.Dialect {
+	switch {
+	case q.MirrorsResponseValue && q.DelimiterTrailer:
+		return client.DialectSource
+	case q.MirrorsResponseValue:
+		return client.DialectSimple
+	case q.ResponseValueAnswer != "" || q.OversizedCommandClose:
+		return client.DialectMinecraft
+	}
+	return client.DialectFactorio
+}
+
+// Report of a suite run.
+type Report struct {
+	Results []Result
+	Quirks  Quirks
+}
+
+// Failed reports if a scenario failed.
+func (r Report) Failed() bool {
+	for _, result := range r.Results {
+		if result.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Dialect returns the client dialect that fits the observed behavior.
+// It is empty if the mirrored delimiter scenario did not succeed.
+func (r Report) Dialect() client.Dialect {
+	if !r.succeeded("mirrored delimiter") {
+		return ""
+	}
+	return r.Quirks.dialect()
+}
+
+// MaxCommandLength returns the max_command_length the inventory needs for the server.
+// It is zero if the default of the dialect fits.
+func (r Report) MaxCommandLength() int {
+	if !r.succeeded("max-size request") || r.Quirks.MaxCommandLength >= r.Dialect().MaxCommandLength() {
+		return 0
+	}
+	return r.Quirks.MaxCommandLength
+}
+
+// succeeded reports if the scenario passed or passed with a quirk.
+func (r Report) succeeded(scenario string) bool {
+	for _, result := range r.Results {
+		if result.Scenario == scenario {
+			return result.Status == Pass || result.Status == Quirk
+		}
+	}
+	return false
+}
+
+// String formats the results and the recommended settings.
+func (r Report) String() string {
+	var sb strings.Builder
+	for _, result := range r.Results {
+		fmt.Fprintf(&sb, "%-5s  %-21s  %s\n", result.Status, result.Scenario, result.Detail)
+	}
+
+	if dialect := r.Dialect(); dialect != "" {
+		fmt.Fprintf(&sb, "\ndialect: %s\n", dialect)
+	}
+	if length := r.MaxCommandLength(); length != 0 {
+		fmt.Fprintf(&sb, "max_command_length: %d\n", length)
+	}
+	return sb.String()
+}
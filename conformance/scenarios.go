@@ -0,0 +1,339 @@
+package conformance
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hamburghammer/grcon"
+)
+
+// sourceDelimiterTrailer is the body of the packet Source servers send after the mirrored delimiter packet.
+var sourceDelimiterTrailer = []byte{0x00, 0x01, 0x00, 0x00}
+
+// auth authenticates with the password.
+func (s *Suite) auth(q *Quirks) (Status, string) {
+	sess, err := s.open()
+	if err != nil {
+		return Fail, "dial failed: " + err.Error()
+	}
+	defer sess.close()
+
+	id := s.nextId()
+	if err := sess.console.Write(grcon.Packet{Id: id, Type: grcon.SERVERDATA_AUTH, Body: []byte(s.Password)}); err != nil {
+		return Fail, "write failed: " + err.Error()
+	}
+	packet, emptyResponse, err := s.readAuthResponse(sess)
+	q.AuthEmptyResponse = emptyResponse
+	if err != nil {
+		return Fail, s.describe(err)
+	}
+
+	switch {
+	case packet.Id == -1:
+		return Fail, "the password was rejected"
+	case packet.Id != id:
+		return Fail, fmt.Sprintf("the auth response has the id %d instead of %d", packet.Id, id)
+	case !emptyResponse:
+		return Quirk, "no empty SERVERDATA_RESPONSE_VALUE before the auth response"
+	}
+	return Pass, "empty SERVERDATA_RESPONSE_VALUE followed by the auth response"
+}
+
+// authFailure authenticates with a wrong password.
+func (s *Suite) authFailure(q *Quirks) (Status, string) {
+	password := "grcon-conformance-invalid"
+	if password == s.Password {
+		password += "-password"
+	}
+
+	sess, err := s.open()
+	if err != nil {
+		return Fail, "dial failed: " + err.Error()
+	}
+	defer sess.close()
+
+	if err := sess.console.Write(grcon.Packet{Id: s.nextId(), Type: grcon.SERVERDATA_AUTH, Body: []byte(password)}); err != nil {
+		return Fail, "write failed: " + err.Error()
+	}
+	packet, _, err := s.readAuthResponse(sess)
+	if err == errNoResponse {
+		return Fail, s.describe(err)
+	}
+	if err != nil {
+		q.AuthFailureClose = true
+		return Quirk, "the connection was closed without an auth response"
+	}
+	if packet.Id != -1 {
+		return Fail, fmt.Sprintf("the auth response has the id %d instead of -1", packet.Id)
+	}
+
+	if _, err := sess.next(s.IdleTimeout); err != nil && err != errNoResponse {
+		q.AuthFailureClose = true
+		return Pass, "auth response with the id -1, then the connection was closed"
+	}
+	return Pass, "auth response with the id -1"
+}
+
+// emptyCommand executes a command with an empty body and checks that the connection still works.
+func (s *Suite) emptyCommand(q *Quirks) (Status, string) {
+	sess, err := s.authenticate()
+	if err != nil {
+		return Fail, s.describe(err)
+	}
+	defer sess.close()
+
+	id, packets, err := s.exec(sess, "")
+	if err != nil {
+		return Fail, s.describe(err)
+	}
+	answered := len(withId(packets, id))
+	q.EmptyCommandResponse = answered > 0
+
+	id, packets, err = s.exec(sess, s.Command)
+	if err != nil {
+		return Fail, "the following command failed: " + s.describe(err)
+	}
+	if len(withId(packets, id)) == 0 {
+		return Fail, "the following command was not answered"
+	}
+
+	if answered == 0 {
+		return Quirk, "the empty command was not answered"
+	}
+	return Pass, fmt.Sprintf("answered with %d packet(s)", answered)
+}
+
+// maxSizeRequest searches the longest command the server answers.
+// Every attempt uses a new connection because servers close the connection on too long commands.
+func (s *Suite) maxSizeRequest(q *Quirks) (Status, string) {
+	accepted, _, err := s.probe(int(grcon.MaxBody))
+	if err != nil {
+		return Fail, s.describe(err)
+	}
+	if accepted {
+		q.MaxCommandLength = int(grcon.MaxBody)
+		return Pass, fmt.Sprintf("accepts commands with grcon.MaxBody (%d) bytes", grcon.MaxBody)
+	}
+
+	// lower is answered and upper is not.
+	lower, upper := len(s.Command), int(grcon.MaxBody)
+	accepted, _, err = s.probe(lower)
+	if err != nil {
+		return Fail, s.describe(err)
+	}
+	if !accepted {
+		return Fail, fmt.Sprintf("the command '%s' was not answered", s.Command)
+	}
+	closes := false
+	for upper-lower > 1 {
+		length := (lower + upper) / 2
+		accepted, closed, err := s.probe(length)
+		if err != nil {
+			return Fail, s.describe(err)
+		}
+		if accepted {
+			lower = length
+		} else {
+			upper = length
+			closes = closed
+		}
+	}
+
+	q.MaxCommandLength = lower
+	q.OversizedCommandClose = closes
+	if closes {
+		return Quirk, fmt.Sprintf("accepts commands up to %d bytes and closes the connection on longer ones", lower)
+	}
+	return Quirk, fmt.Sprintf("accepts commands up to %d bytes and ignores longer ones", lower)
+}
+
+// probe executes the command padded to the length on a new connection.
+// It reports if the command was answered and if the connection was closed.
+func (s *Suite) probe(length int) (bool, bool, error) {
+	sess, err := s.authenticate()
+	if err != nil {
+		return false, false, err
+	}
+	defer sess.close()
+
+	cmd := s.Command
+	if length > len(cmd) {
+		cmd += " " + strings.Repeat("x", length-len(cmd)-1)
+	}
+	id, packets, err := s.exec(sess, cmd)
+	return len(withId(packets, id)) > 0, err != nil, nil
+}
+
+// multiPacketResponse executes the LongCommand and reads all packets of the response.
+func (s *Suite) multiPacketResponse(q *Quirks) (Status, string) {
+	if s.LongCommand == "" {
+		return Skip, "no long command configured"
+	}
+
+	sess, err := s.authenticate()
+	if err != nil {
+		return Fail, s.describe(err)
+	}
+	defer sess.close()
+
+	id, packets, err := s.exec(sess, s.LongCommand)
+	responses := withId(packets, id)
+	q.ResponsePackets = len(responses)
+	for _, packet := range responses {
+		if len(packet.Body) > q.MaxResponseBody {
+			q.MaxResponseBody = len(packet.Body)
+		}
+	}
+
+	var tooLong grcon.ResponseTooLongError
+	if errors.As(err, &tooLong) {
+		q.OversizedResponse = true
+		return Fail, fmt.Sprintf("a response packet is longer than grcon.MaxPacket (%d bytes)", grcon.MaxPacket)
+	}
+	if err != nil {
+		return Fail, s.describe(err)
+	}
+
+	switch len(responses) {
+	case 0:
+		return Fail, "the command was not answered"
+	case 1:
+		return Skip, "the response fits into a single packet, use a command with a longer response"
+	}
+	return Pass, fmt.Sprintf("%d packets with bodies of up to %d bytes", len(responses), q.MaxResponseBody)
+}
+
+// mirroredDelimiter sends an empty SERVERDATA_RESPONSE_VALUE after a command like the SimpleClient.
+func (s *Suite) mirroredDelimiter(q *Quirks) (Status, string) {
+	sess, err := s.authenticate()
+	if err != nil {
+		return Fail, s.describe(err)
+	}
+	defer sess.close()
+
+	cmdId := s.nextId()
+	if err := sess.console.Write(grcon.Packet{Id: cmdId, Type: grcon.SERVERDATA_EXECCOMMAND, Body: []byte(s.Command)}); err != nil {
+		return Fail, "write failed: " + err.Error()
+	}
+	delimiterId := s.nextId()
+	if err := sess.console.Write(grcon.Packet{Id: delimiterId, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte{}}); err != nil {
+		return Fail, "write failed: " + err.Error()
+	}
+
+	packets, err := sess.collect(s.Timeout, s.IdleTimeout)
+	if err != nil {
+		return Fail, s.describe(err)
+	}
+
+	responseEnd, mirrored := -1, -1
+	for i, packet := range packets {
+		switch {
+		case packet.Id == cmdId:
+			responseEnd = i
+		case packet.Id == delimiterId && len(packet.Body) == 0 && mirrored == -1:
+			mirrored = i
+		case bytes.Equal(packet.Body, sourceDelimiterTrailer):
+			q.DelimiterTrailer = true
+		case packet.Id == delimiterId:
+			q.ResponseValueAnswer = string(packet.Body)
+		}
+	}
+	q.MirrorsResponseValue = mirrored != -1
+
+	switch {
+	case responseEnd == -1:
+		return Fail, "the command was not answered"
+	case mirrored == -1 && q.ResponseValueAnswer != "":
+		return Quirk, fmt.Sprintf("answered with '%s' instead of the mirrored packet", q.ResponseValueAnswer)
+	case mirrored == -1:
+		return Quirk, "the delimiter packet was not answered"
+	case mirrored < responseEnd:
+		return Fail, "the mirrored packet arrived before the end of the response"
+	case q.DelimiterTrailer:
+		return Pass, "mirrored after the response and followed by a packet with the body 0x00 0x01 0x00 0x00"
+	}
+	return Pass, "mirrored after the response"
+}
+
+// pipelinedRequests writes multiple commands before reading the responses.
+func (s *Suite) pipelinedRequests(q *Quirks) (Status, string) {
+	sess, err := s.authenticate()
+	if err != nil {
+		return Fail, s.describe(err)
+	}
+	defer sess.close()
+
+	ids := []grcon.PacketId{s.nextId(), s.nextId(), s.nextId()}
+	for _, id := range ids {
+		if err := sess.console.Write(grcon.Packet{Id: id, Type: grcon.SERVERDATA_EXECCOMMAND, Body: []byte(s.Command)}); err != nil {
+			return Fail, "write failed: " + err.Error()
+		}
+	}
+
+	packets, err := sess.collect(s.Timeout, s.IdleTimeout)
+	if err != nil {
+		return Fail, s.describe(err)
+	}
+
+	// the index of the id of every packet has to be the same or higher than the one of the previous packet.
+	answered, current, inOrder := 0, -1, true
+	for _, packet := range packets {
+		for i, id := range ids {
+			if packet.Id != id {
+				continue
+			}
+			if i < current {
+				inOrder = false
+			}
+			if i > current {
+				answered++
+				current = i
+			}
+		}
+	}
+	q.Pipelining = answered == len(ids)
+	q.PipeliningInOrder = q.Pipelining && inOrder
+
+	switch {
+	case !q.Pipelining:
+		return Fail, fmt.Sprintf("answered %d of %d pipelined commands", answered, len(ids))
+	case !inOrder:
+		return Quirk, "the responses of the pipelined commands are out of order"
+	}
+	return Pass, fmt.Sprintf("answered %d pipelined commands in order", len(ids))
+}
+
+// exec executes the command and collects the packets until the server is idle.
+func (s *Suite) exec(sess *session, cmd string) (grcon.PacketId, []grcon.Packet, error) {
+	id := s.nextId()
+	if err := sess.console.Write(grcon.Packet{Id: id, Type: grcon.SERVERDATA_EXECCOMMAND, Body: []byte(cmd)}); err != nil {
+		return id, nil, err
+	}
+	packets, err := sess.collect(s.Timeout, s.IdleTimeout)
+	return id, packets, err
+}
+
+// describe returns the detail of an error.
+func (s *Suite) describe(err error) string {
+	switch {
+	case err == errNoResponse:
+		return fmt.Sprintf("no response within %s", s.Timeout)
+	case errors.Is(err, io.EOF):
+		return "the connection was closed"
+	}
+	return err.Error()
+}
+
+// withId returns the packets with the id.
+func withId(packets []grcon.Packet, id grcon.PacketId) []grcon.Packet {
+	filtered := []grcon.Packet{}
+	for _, packet := range packets {
+		if packet.Id == id {
+			filtered = append(filtered, packet)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,128 @@
+package emulator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/hamburghammer/grcon"
+)
+
+// ErrServerClosed is returned by Serve after the emulator was closed or stopped with the stop command.
+var ErrServerClosed = errors.New("grcon-emulator: server closed")
+
+// listener tracks the listeners and connections of an emulator to close them.
+type listener struct {
+	mutex     sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// serve accepts the connections and calls serveConn for each of them in a new goroutine.
+func (l *listener) serve(ln net.Listener, serveConn func(conn net.Conn)) error {
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		ln.Close()
+		return ErrServerClosed
+	}
+	if l.listeners == nil {
+		l.listeners = map[net.Listener]struct{}{}
+	}
+	l.listeners[ln] = struct{}{}
+	l.mutex.Unlock()
+
+	defer func() {
+		l.mutex.Lock()
+		delete(l.listeners, ln)
+		l.mutex.Unlock()
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if l.isClosed() {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		go func() {
+			if !l.track(conn) {
+				conn.Close()
+				return
+			}
+			defer l.untrack(conn)
+			defer conn.Close()
+			serveConn(conn)
+		}()
+	}
+}
+
+func (l *listener) track(conn net.Conn) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return false
+	}
+	if l.conns == nil {
+		l.conns = map[net.Conn]struct{}{}
+	}
+	l.conns[conn] = struct{}{}
+	return true
+}
+
+func (l *listener) untrack(conn net.Conn) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.conns, conn)
+}
+
+// close closes all listeners and connections.
+func (l *listener) close() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.closed = true
+	for ln := range l.listeners {
+		ln.Close()
+	}
+	for conn := range l.conns {
+		conn.Close()
+	}
+}
+
+func (l *listener) isClosed() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.closed
+}
+
+// writePacket writes the packet without the size limit of grcon.RemoteConsole.Write,
+// because game servers send bodies that are longer than grcon.MaxBody.
+func writePacket(conn net.Conn, id grcon.PacketId, packetType grcon.PacketType, body []byte) error {
+	buffer := bytes.NewBuffer(make([]byte, 0, len(body)+14))
+	binary.Write(buffer, binary.LittleEndian, int32(len(body)+10))
+	binary.Write(buffer, binary.LittleEndian, int32(id))
+	binary.Write(buffer, binary.LittleEndian, int32(packetType))
+	buffer.Write(body)
+	buffer.Write([]byte{0, 0})
+
+	_, err := conn.Write(buffer.Bytes())
+	return err
+}
+
+// fragments splits the body into parts with the maximal size.
+// An empty body results in a single empty part.
+func fragments(body []byte, size int) [][]byte {
+	parts := [][]byte{}
+	for len(body) > size {
+		parts = append(parts, body[:size])
+		body = body[size:]
+	}
+	return append(parts, body)
+}
@@ -0,0 +1,858 @@
+/*
+Package emulator implements stateful fake game servers for integration tests.
+
+The emulators answer the common commands with the same text formats and error
+messages as the real servers and reproduce their protocol quirks, so clients
+and typed command layers can be tested end to end without the game:
+
+	mc := emulator.NewMinecraft("secret")
+	mc.Join("Steve")
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	go mc.Serve(l)
+	defer mc.Close()
+
+	conn, _ := net.Dial("tcp", l.Addr().String())
+	c := client.NewMinecraftClient(grcon.NewRemoteConsoleSize(conn, client.MinecraftMaxPacket), util.GenerateRequestId)
+	c.Auth("secret")
+	c.WhitelistAdd("Steve")
+	// mc.Whitelist() == []string{"Steve"}
+
+The state can be prepared and inspected with the methods of the emulators.
+*/
+package emulator
+
+import (
+	"crypto/md5"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hamburghammer/grcon"
+)
+
+// Protocol limits of a vanilla minecraft server.
+const (
+	// MinecraftMaxCommandLength is the longest command body the server reads.
+	// The server reads packets into a buffer of 1460 bytes and closes the connection for longer packets.
+	MinecraftMaxCommandLength = 1446
+	// MinecraftFragmentSize is the number of characters the server splits long responses into.
+	// A full fragment is at least 10 bytes longer than grcon.MaxBody, the client.MinecraftClient
+	// reads them from a console with the client.MinecraftMaxPacket.
+	MinecraftFragmentSize = 4096
+)
+
+// Start values of the emulated world.
+const (
+	DefaultMinecraftMaxPlayers  = 20
+	DefaultMinecraftWorldBorder = 59999968
+)
+
+// minecraftGameRules are the default values of the emulated game rules.
+var minecraftGameRules = map[string]string{
+	"announceAdvancements":      "true",
+	"commandBlockOutput":        "true",
+	"doDaylightCycle":           "true",
+	"doFireTick":                "true",
+	"doImmediateRespawn":        "false",
+	"doInsomnia":                "true",
+	"doMobSpawning":             "true",
+	"doWeatherCycle":            "true",
+	"keepInventory":             "false",
+	"maxEntityCramming":         "24",
+	"mobGriefing":               "true",
+	"naturalRegeneration":       "true",
+	"playersSleepingPercentage": "100",
+	"randomTickSpeed":           "3",
+	"sendCommandFeedback":       "true",
+	"showDeathMessages":         "true",
+	"spawnRadius":               "10",
+}
+
+// minecraftTimes are the names that can be used with "time set".
+var minecraftTimes = map[string]int{"day": 1000, "noon": 6000, "night": 13000, "midnight": 18000}
+
+// NewMinecraft is a constructor for the Minecraft struct with the password and the default world.
+func NewMinecraft(password string) *Minecraft {
+	m := &Minecraft{
+		Password:     password,
+		MaxPlayers:   DefaultMinecraftMaxPlayers,
+		FragmentSize: MinecraftFragmentSize,
+		saving:       true,
+		known:        map[string]string{},
+		whitelist:    map[string]bool{},
+		ops:          map[string]bool{},
+		bans:         map[string]string{},
+		gameRules:    map[string]string{},
+		weather:      "clear",
+		border:       DefaultMinecraftWorldBorder,
+		commands:     map[string]func(args string) string{},
+	}
+	for rule, value := range minecraftGameRules {
+		m.gameRules[rule] = value
+	}
+	return m
+}
+
+// Minecraft emulates the RCON interface of a vanilla minecraft server.
+//
+// Like the real server it answers with the SERVERDATA_AUTH_RESPONSE only,
+// does not mirror SERVERDATA_RESPONSE_VALUE packets but answers them with "Unknown request 0",
+// splits long responses into bodies of FragmentSize characters without an end marker
+// and concatenates the lines of a response without line breaks.
+type Minecraft struct {
+	// Password for the RCON connections.
+	Password string
+	// MaxPlayers is shown by the list command.
+	MaxPlayers int
+	// FragmentSize is the maximal number of characters in the body of the response packets.
+	// Set it to int(grcon.MaxBody) to read long ASCII responses with a default grcon.RemoteConsole.
+	FragmentSize int
+
+	listener listener
+
+	mutex     sync.Mutex
+	online    []string
+	known     map[string]string
+	whitelist map[string]bool
+	enforce   bool
+	ops       map[string]bool
+	bans      map[string]string
+	gameRules map[string]string
+	dayTime   int
+	gameTime  int
+	weather   string
+	border    float64
+	saving    bool
+	saves     int
+	messages  []string
+	history   []string
+	stopped   bool
+	commands  map[string]func(args string) string
+}
+
+// Serve accepts connections on the listener and serves each of them in a new goroutine.
+// It returns ErrServerClosed after Close or the stop command.
+func (m *Minecraft) Serve(l net.Listener) error {
+	return m.listener.serve(l, m.serveConn)
+}
+
+// Close closes all listeners and connections.
+func (m *Minecraft) Close() error {
+	m.listener.close()
+	return nil
+}
+
+func (m *Minecraft) serveConn(conn net.Conn) {
+	remoteConsole := grcon.NewRemoteConsole(conn)
+	authenticated := false
+
+	for {
+		packet, err := remoteConsole.Read()
+		if err != nil {
+			return
+		}
+		if len(packet.Body) > MinecraftMaxCommandLength {
+			return
+		}
+
+		switch packet.Type {
+		case grcon.SERVERDATA_AUTH:
+			id := packet.Id
+			if m.Password == "" || string(packet.Body) != m.Password {
+				id = -1
+			}
+			authenticated = id != -1
+			err = writePacket(conn, id, grcon.SERVERDATA_AUTH_RESPONSE, []byte{})
+		case grcon.SERVERDATA_EXECCOMMAND:
+			if !authenticated {
+				err = writePacket(conn, -1, grcon.SERVERDATA_AUTH_RESPONSE, []byte{})
+				break
+			}
+			err = m.respond(conn, packet.Id, m.Exec(string(packet.Body)))
+		default:
+			err = m.respond(conn, packet.Id, fmt.Sprintf("Unknown request %x", int32(packet.Type)))
+		}
+		if err != nil {
+			return
+		}
+
+		if m.Stopped() {
+			m.Close()
+			return
+		}
+	}
+}
+
+// respond writes the response in fragments of the FragmentSize.
+// Like the server it splits the characters and encodes the fragments afterwards.
+func (m *Minecraft) respond(conn net.Conn, id grcon.PacketId, response string) error {
+	size := m.FragmentSize
+	if size <= 0 {
+		size = MinecraftFragmentSize
+	}
+	for _, body := range runeFragments(response, size) {
+		if err := writePacket(conn, id, grcon.SERVERDATA_RESPONSE_VALUE, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runeFragments splits the string into parts of the size in characters.
+func runeFragments(s string, size int) [][]byte {
+	parts := [][]byte{}
+	runes := []rune(s)
+	for len(runes) > size {
+		parts = append(parts, []byte(string(runes[:size])))
+		runes = runes[size:]
+	}
+	return append(parts, []byte(string(runes)))
+}
+
+// HandleFunc adds a command or replaces a built-in command.
+// The handler gets the arguments after the name and returns the response.
+func (m *Minecraft) HandleFunc(name string, handler func(args string) string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.commands[name] = handler
+}
+
+// Exec executes the command like it was received over RCON and returns the response.
+func (m *Minecraft) Exec(cmd string) string {
+	m.mutex.Lock()
+	m.history = append(m.history, cmd)
+	name, args := cmd, ""
+	if i := strings.IndexByte(cmd, ' '); i >= 0 {
+		name, args = cmd[:i], cmd[i+1:]
+	}
+	handler, ok := m.commands[name]
+	m.mutex.Unlock()
+	if ok {
+		return handler(args)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.exec(newMinecraftInput(cmd))
+}
+
+// exec runs a built-in command. The mutex has to be held.
+func (m *Minecraft) exec(in *minecraftInput) string {
+	switch in.word(0) {
+	case "list":
+		return m.list(in)
+	case "whitelist":
+		return m.whitelistCommand(in)
+	case "op":
+		return m.op(in, true)
+	case "deop":
+		return m.op(in, false)
+	case "kick":
+		return m.kick(in)
+	case "ban":
+		return m.ban(in)
+	case "pardon":
+		return m.pardon(in)
+	case "time":
+		return m.time(in)
+	case "weather":
+		return m.weatherCommand(in)
+	case "gamerule":
+		return m.gameRule(in)
+	case "worldborder":
+		return m.worldBorder(in)
+	case "save-all":
+		return m.saveAll(in)
+	case "save-off", "save-on":
+		return m.saveToggle(in)
+	case "say":
+		if in.rest(1) == "" {
+			return in.unknown(in.len())
+		}
+		m.messages = append(m.messages, "[Rcon] "+in.rest(1))
+		return ""
+	case "tellraw", "title":
+		return m.tell(in)
+	case "seed":
+		return "Seed: [-1589003295543891356]"
+	case "stop":
+		m.stopped = true
+		return "Stopping the server"
+	}
+	return in.unknown(0)
+}
+
+func (m *Minecraft) list(in *minecraftInput) string {
+	uuids := false
+	switch {
+	case in.count() == 2 && in.word(1) == "uuids":
+		uuids = true
+	case in.count() != 1:
+		return in.incorrect(1)
+	}
+
+	entries := make([]string, 0, len(m.online))
+	for _, name := range m.online {
+		if uuids {
+			name += " (" + m.known[name] + ")"
+		}
+		entries = append(entries, name)
+	}
+	return fmt.Sprintf("There are %d of a max of %d players online: %s", len(m.online), m.MaxPlayers, strings.Join(entries, ", "))
+}
+
+func (m *Minecraft) whitelistCommand(in *minecraftInput) string {
+	switch {
+	case in.count() == 2 && in.word(1) == "list":
+		names := sortedKeys(m.whitelist)
+		if len(names) == 0 {
+			return "There are no whitelisted players"
+		}
+		return fmt.Sprintf("There are %d whitelisted players: %s", len(names), strings.Join(names, ", "))
+	case in.count() == 2 && in.word(1) == "on":
+		if m.enforce {
+			return "Whitelist is already turned on"
+		}
+		m.enforce = true
+		return "Whitelist is now turned on"
+	case in.count() == 2 && in.word(1) == "off":
+		if !m.enforce {
+			return "Whitelist is already turned off"
+		}
+		m.enforce = false
+		return "Whitelist is now turned off"
+	case in.count() == 2 && in.word(1) == "reload":
+		return "Reloaded the whitelist"
+	case in.count() == 3 && in.word(1) == "add":
+		name, ok := m.profile(in.word(2))
+		if !ok {
+			return "That player does not exist"
+		}
+		if m.whitelist[name] {
+			return "Player is already whitelisted"
+		}
+		m.whitelist[name] = true
+		return fmt.Sprintf("Added %s to the whitelist", name)
+	case in.count() == 3 && in.word(1) == "remove":
+		name, ok := m.profile(in.word(2))
+		if !ok {
+			return "That player does not exist"
+		}
+		if !m.whitelist[name] {
+			return "Player is not whitelisted"
+		}
+		delete(m.whitelist, name)
+		return fmt.Sprintf("Removed %s from the whitelist", name)
+	case in.count() < 3 && (in.word(1) == "add" || in.word(1) == "remove"):
+		return in.unknown(in.len())
+	case in.count() == 1:
+		return in.unknown(in.len())
+	}
+	return in.incorrect(1)
+}
+
+func (m *Minecraft) op(in *minecraftInput, op bool) string {
+	if in.count() != 2 {
+		return in.unknown(in.len())
+	}
+	name, ok := m.profile(in.word(1))
+	if !ok {
+		return "That player does not exist"
+	}
+
+	if op {
+		if m.ops[name] {
+			return "Nothing changed. The player already is an operator"
+		}
+		m.ops[name] = true
+		return fmt.Sprintf("Made %s a server operator", name)
+	}
+	if !m.ops[name] {
+		return "Nothing changed. The player is not an operator"
+	}
+	delete(m.ops, name)
+	return fmt.Sprintf("Made %s no longer a server operator", name)
+}
+
+func (m *Minecraft) kick(in *minecraftInput) string {
+	if in.count() < 2 {
+		return in.unknown(in.len())
+	}
+	name, ok := m.onlinePlayer(in.word(1))
+	if !ok {
+		return "No player was found"
+	}
+
+	reason := in.rest(2)
+	if reason == "" {
+		reason = "Kicked by an operator"
+	}
+	m.leave(name)
+	return fmt.Sprintf("Kicked %s: %s", name, reason)
+}
+
+func (m *Minecraft) ban(in *minecraftInput) string {
+	if in.count() < 2 {
+		return in.unknown(in.len())
+	}
+	name, ok := m.profile(in.word(1))
+	if !ok {
+		return "That player does not exist"
+	}
+	if _, banned := m.bans[name]; banned {
+		return "Nothing changed. The player is already banned"
+	}
+
+	reason := in.rest(2)
+	if reason == "" {
+		reason = "Banned by an operator."
+	}
+	m.bans[name] = reason
+	m.leave(name)
+	return fmt.Sprintf("Banned %s: %s", name, reason)
+}
+
+func (m *Minecraft) pardon(in *minecraftInput) string {
+	if in.count() != 2 {
+		return in.unknown(in.len())
+	}
+	name, ok := m.profile(in.word(1))
+	if !ok {
+		return "That player does not exist"
+	}
+	if _, banned := m.bans[name]; !banned {
+		return "Nothing changed. The player isn't banned"
+	}
+	delete(m.bans, name)
+	return fmt.Sprintf("Unbanned %s", name)
+}
+
+func (m *Minecraft) time(in *minecraftInput) string {
+	if in.count() != 3 {
+		return in.unknown(in.len())
+	}
+
+	switch in.word(1) {
+	case "set", "add":
+		ticks, ok := minecraftTimes[in.word(2)]
+		if !ok || in.word(1) == "add" {
+			var err error
+			ticks, err = strconv.Atoi(in.word(2))
+			if err != nil {
+				return in.invalidInteger(2)
+			}
+			if ticks < 0 {
+				return fmt.Sprintf("The tick count must not be less than 0, found %d", ticks) + in.context(in.offset(2)+len(in.word(2)))
+			}
+		}
+		if in.word(1) == "add" {
+			ticks += m.dayTime
+		}
+		m.dayTime = ticks
+		return fmt.Sprintf("Set the time to %d", m.dayTime)
+	case "query":
+		switch in.word(2) {
+		case "daytime":
+			return fmt.Sprintf("The time is %d", m.dayTime%24000)
+		case "gametime":
+			return fmt.Sprintf("The time is %d", m.gameTime)
+		case "day":
+			return fmt.Sprintf("The time is %d", m.dayTime/24000)
+		}
+		return in.incorrect(2)
+	}
+	return in.incorrect(1)
+}
+
+func (m *Minecraft) weatherCommand(in *minecraftInput) string {
+	if in.count() < 2 || in.count() > 3 {
+		return in.unknown(in.len())
+	}
+	if in.count() == 3 {
+		if _, err := strconv.Atoi(in.word(2)); err != nil {
+			return in.invalidInteger(2)
+		}
+	}
+
+	switch in.word(1) {
+	case "clear":
+		m.weather = "clear"
+		return "Set the weather to clear"
+	case "rain":
+		m.weather = "rain"
+		return "Set the weather to rain"
+	case "thunder":
+		m.weather = "thunder"
+		return "Set the weather to rain & thunder"
+	}
+	return in.incorrect(1)
+}
+
+func (m *Minecraft) gameRule(in *minecraftInput) string {
+	if in.count() < 2 || in.count() > 3 {
+		return in.unknown(in.len())
+	}
+	rule := in.word(1)
+	value, ok := m.gameRules[rule]
+	if !ok {
+		return in.incorrect(1)
+	}
+	if in.count() == 2 {
+		return fmt.Sprintf("Gamerule %s is currently set to: %s", rule, value)
+	}
+
+	newValue := in.word(2)
+	if value == "true" || value == "false" {
+		if newValue != "true" && newValue != "false" {
+			return fmt.Sprintf("Invalid boolean, expected 'true' or 'false' but found '%s'", newValue) + in.context(in.offset(2))
+		}
+	} else if _, err := strconv.Atoi(newValue); err != nil {
+		return in.invalidInteger(2)
+	}
+	m.gameRules[rule] = newValue
+	return fmt.Sprintf("Gamerule %s is now set to: %s", rule, newValue)
+}
+
+func (m *Minecraft) worldBorder(in *minecraftInput) string {
+	switch {
+	case in.count() == 2 && in.word(1) == "get":
+		return fmt.Sprintf("The world border is currently %.0f block(s) wide", m.border)
+	case (in.count() == 3 || in.count() == 4) && in.word(1) == "set":
+		diameter, err := strconv.ParseFloat(in.word(2), 64)
+		if err != nil {
+			return fmt.Sprintf("Expected float but found '%s'", in.word(2)) + in.context(in.offset(2))
+		}
+		seconds := 0
+		if in.count() == 4 {
+			if seconds, err = strconv.Atoi(in.word(3)); err != nil {
+				return in.invalidInteger(3)
+			}
+		}
+
+		old := m.border
+		m.border = diameter
+		switch {
+		case diameter == old:
+			return "Nothing changed. The world border is already that size"
+		case seconds == 0:
+			return fmt.Sprintf("Set the world border to %.1f block(s) wide", diameter)
+		case diameter > old:
+			return fmt.Sprintf("Growing the world border to %.1f blocks wide over %d seconds", diameter, seconds)
+		default:
+			return fmt.Sprintf("Shrinking the world border to %.1f block(s) wide over %d second(s)", diameter, seconds)
+		}
+	case in.count() == 1:
+		return in.unknown(in.len())
+	}
+	return in.incorrect(1)
+}
+
+func (m *Minecraft) saveAll(in *minecraftInput) string {
+	if in.count() > 2 || (in.count() == 2 && in.word(1) != "flush") {
+		return in.incorrect(1)
+	}
+	m.saves++
+	return "Saving the game (this may take a moment!)Saved the game"
+}
+
+func (m *Minecraft) saveToggle(in *minecraftInput) string {
+	if in.count() != 1 {
+		return in.incorrect(1)
+	}
+	if in.word(0) == "save-off" {
+		if !m.saving {
+			return "Saving is already turned off"
+		}
+		m.saving = false
+		return "Automatic saving is now disabled"
+	}
+	if m.saving {
+		return "Saving is already turned on"
+	}
+	m.saving = true
+	return "Automatic saving is now enabled"
+}
+
+// tell handles tellraw and title by recording the JSON component for the matched players.
+func (m *Minecraft) tell(in *minecraftInput) string {
+	if in.count() < 3 {
+		return in.unknown(in.len())
+	}
+	target := in.word(1)
+	if strings.HasPrefix(target, "@") {
+		if len(m.online) == 0 {
+			return "No player was found"
+		}
+	} else if _, ok := m.onlinePlayer(target); !ok {
+		return "No player was found"
+	}
+
+	m.messages = append(m.messages, in.rest(1))
+	return ""
+}
+
+// profile returns the name of a known player with the case of the first join.
+func (m *Minecraft) profile(name string) (string, bool) {
+	for known := range m.known {
+		if strings.EqualFold(known, name) {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+func (m *Minecraft) onlinePlayer(name string) (string, bool) {
+	for _, online := range m.online {
+		if strings.EqualFold(online, name) {
+			return online, true
+		}
+	}
+	return "", false
+}
+
+// leave removes the player from the online players. The mutex has to be held.
+func (m *Minecraft) leave(name string) {
+	for i, online := range m.online {
+		if online == name {
+			m.online = append(m.online[:i], m.online[i+1:]...)
+			return
+		}
+	}
+}
+
+// Join adds the player to the online players and the known profiles.
+// The player gets the UUID of an offline mode server.
+func (m *Minecraft) Join(name string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.addProfile(name)
+	if _, ok := m.onlinePlayer(name); !ok {
+		m.online = append(m.online, name)
+	}
+}
+
+// AddProfile makes the player known without joining, so that the player can be whitelisted or banned.
+func (m *Minecraft) AddProfile(name string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.addProfile(name)
+}
+
+func (m *Minecraft) addProfile(name string) {
+	if _, ok := m.known[name]; !ok {
+		m.known[name] = OfflineUUID(name)
+	}
+}
+
+// Leave removes the player from the online players.
+func (m *Minecraft) Leave(name string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.leave(name)
+}
+
+// Players returns the online players.
+func (m *Minecraft) Players() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]string{}, m.online...)
+}
+
+// Whitelist returns the sorted names of the whitelisted players.
+func (m *Minecraft) Whitelist() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return sortedKeys(m.whitelist)
+}
+
+// Ops returns the sorted names of the operators.
+func (m *Minecraft) Ops() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return sortedKeys(m.ops)
+}
+
+// Bans returns the banned players with their reasons.
+func (m *Minecraft) Bans() map[string]string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	bans := map[string]string{}
+	for name, reason := range m.bans {
+		bans[name] = reason
+	}
+	return bans
+}
+
+// GameRule returns the value of the game rule.
+func (m *Minecraft) GameRule(rule string) (string, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	value, ok := m.gameRules[rule]
+	return value, ok
+}
+
+// Time returns the time of the day in ticks.
+func (m *Minecraft) Time() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.dayTime
+}
+
+// Tick advances the game time and the time of the day.
+func (m *Minecraft) Tick(ticks int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.gameTime += ticks
+	if m.gameRules["doDaylightCycle"] == "true" {
+		m.dayTime += ticks
+	}
+}
+
+// Weather returns the weather: clear, rain or thunder.
+func (m *Minecraft) Weather() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.weather
+}
+
+// WorldBorder returns the diameter of the world border.
+func (m *Minecraft) WorldBorder() float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.border
+}
+
+// Saving reports if the automatic saving is enabled and returns the number of save-all commands.
+func (m *Minecraft) Saving() (bool, int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.saving, m.saves
+}
+
+// Messages returns the messages of say, tellraw and title in the order they were sent.
+func (m *Minecraft) Messages() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]string{}, m.messages...)
+}
+
+// History returns all executed commands.
+func (m *Minecraft) History() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]string{}, m.history...)
+}
+
+// Stopped reports if the stop command was executed.
+func (m *Minecraft) Stopped() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.stopped
+}
+
+// OfflineUUID returns the UUID that a server in offline mode assigns to the player name.
+func OfflineUUID(name string) string {
+	sum := md5.Sum([]byte("OfflinePlayer:" + name))
+	sum[6] = sum[6]&0x0f | 0x30
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// minecraftInput is a command split into words with their offsets for the error messages.
+type minecraftInput struct {
+	input   string
+	words   []string
+	offsets []int
+}
+
+func newMinecraftInput(input string) *minecraftInput {
+	in := &minecraftInput{input: input}
+	start := -1
+	for i := 0; i <= len(input); i++ {
+		if i == len(input) || input[i] == ' ' {
+			if start >= 0 {
+				in.words = append(in.words, input[start:i])
+				in.offsets = append(in.offsets, start)
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	return in
+}
+
+func (in *minecraftInput) count() int {
+	return len(in.words)
+}
+
+func (in *minecraftInput) len() int {
+	return len(in.input)
+}
+
+func (in *minecraftInput) word(i int) string {
+	if i >= len(in.words) {
+		return ""
+	}
+	return in.words[i]
+}
+
+func (in *minecraftInput) offset(i int) int {
+	if i >= len(in.offsets) {
+		return len(in.input)
+	}
+	return in.offsets[i]
+}
+
+// rest returns the input starting at the word.
+func (in *minecraftInput) rest(i int) string {
+	if i >= len(in.offsets) {
+		return ""
+	}
+	return in.input[in.offsets[i]:]
+}
+
+// context returns the position of an error like "...e add Ste<--[HERE]".
+// Like the real server the context shows the 10 characters before the cursor and the rest of the input.
+func (in *minecraftInput) context(cursor int) string {
+	if cursor > len(in.input) {
+		cursor = len(in.input)
+	}
+	start := cursor - 10
+	prefix := "..."
+	if start <= 0 {
+		start = 0
+		prefix = ""
+	}
+	return prefix + in.input[start:] + "<--[HERE]"
+}
+
+// unknown returns the error for an unknown or incomplete command at the cursor.
+func (in *minecraftInput) unknown(cursor int) string {
+	return "Unknown or incomplete command, see below for error" + in.context(cursor)
+}
+
+// incorrect returns the error for an incorrect argument at the word.
+func (in *minecraftInput) incorrect(i int) string {
+	return "Incorrect argument for command" + in.context(in.offset(i))
+}
+
+func (in *minecraftInput) invalidInteger(i int) string {
+	return fmt.Sprintf("Invalid integer '%s'", in.word(i)) + in.context(in.offset(i))
+}
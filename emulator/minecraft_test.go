@@ -0,0 +1,364 @@
+package emulator_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/emulator"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// listen serves the emulator on a loopback address until the test ends.
+func listen(t *testing.T, serve func(l net.Listener) error, close func() error) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go serve(l)
+	t.Cleanup(func() { close() })
+	return l.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *grcon.RemoteConsole {
+	return dialSize(t, addr, int(grcon.MaxPacket))
+}
+
+// dialSize connects a console that reads packets up to the maxPacket size.
+func dialSize(t *testing.T, addr string, maxPacket int) *grcon.RemoteConsole {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	t.Cleanup(func() { conn.Close() })
+	return grcon.NewRemoteConsoleSize(conn, maxPacket)
+}
+
+func startMinecraft(t *testing.T) (*emulator.Minecraft, client.MinecraftClient, string) {
+	mc := emulator.NewMinecraft("secret")
+	addr := listen(t, mc.Serve, mc.Close)
+
+	minecraftClient := client.NewMinecraftClient(dial(t, addr), util.GenerateRequestId)
+	if err := minecraftClient.Auth("secret"); err != nil {
+		t.Fatal(err)
+	}
+	return mc, minecraftClient, addr
+}
+
+func TestMinecraft_Client(t *testing.T) {
+	mc, c, _ := startMinecraft(t)
+	mc.Join("Steve")
+	mc.Join("Alex")
+	mc.AddProfile("Herobrine")
+
+	list, err := c.ListPlayers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Online != 2 || list.Max != 20 || list.Players[0].UUID != emulator.OfflineUUID("Steve") {
+		t.Errorf("list did not match: %+v\n", list)
+	}
+
+	t.Run("whitelist", func(t *testing.T) {
+		for _, name := range []string{"Steve", "Herobrine", "Steve"} {
+			if err := c.WhitelistAdd(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := c.WhitelistAdd("Notch"); err == nil {
+			t.Error("expected an error for the unknown player")
+		} else if _, ok := err.(client.PlayerNotFoundError); !ok {
+			t.Errorf("expected: PlayerNotFoundError\ngot: %T\n", err)
+		}
+
+		got, err := c.WhitelistList()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(got, ",") != "Herobrine,Steve" || strings.Join(mc.Whitelist(), ",") != "Herobrine,Steve" {
+			t.Errorf("whitelist did not match:\nexpected: %s\ngot: %v\n", "Herobrine,Steve", got)
+		}
+		if err := c.WhitelistRemove("herobrine"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("ops and moderation", func(t *testing.T) {
+		if err := c.Op("Alex"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Op("Alex"); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(mc.Ops(), ",") != "Alex" {
+			t.Errorf("ops did not match: %v\n", mc.Ops())
+		}
+		if err := c.Deop("Alex"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := c.Kick("Alex", "afk"); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := c.Kick("Alex", "").(client.PlayerNotFoundError); !ok {
+			t.Error("expected a PlayerNotFoundError for the offline player")
+		}
+		if err := c.Ban("Herobrine", ""); err != nil {
+			t.Fatal(err)
+		}
+		if reason := mc.Bans()["Herobrine"]; reason != "Banned by an operator." {
+			t.Errorf("reason did not match:\nexpected: %s\ngot: %s\n", "Banned by an operator.", reason)
+		}
+		if strings.Join(mc.Players(), ",") != "Steve" {
+			t.Errorf("players did not match: %v\n", mc.Players())
+		}
+	})
+
+	t.Run("world", func(t *testing.T) {
+		ticks, err := c.SetTime("night")
+		if err != nil || ticks != 13000 {
+			t.Errorf("time did not match: %d %v\n", ticks, err)
+		}
+		mc.Tick(24000)
+		if day, err := c.QueryTime("day"); err != nil || day != 1 {
+			t.Errorf("day did not match: %d %v\n", day, err)
+		}
+
+		if err := c.SetWeather(client.MinecraftWeatherThunder, time.Minute); err != nil || mc.Weather() != "thunder" {
+			t.Errorf("weather did not match: %s %v\n", mc.Weather(), err)
+		}
+
+		if err := c.SetGameRule("keepInventory", "true"); err != nil {
+			t.Fatal(err)
+		}
+		if value, err := c.GameRule("keepInventory"); err != nil || value != "true" {
+			t.Errorf("gamerule did not match: %s %v\n", value, err)
+		}
+		if _, err := c.GameRule("unknownRule"); err == nil {
+			t.Error("expected an error for the unknown game rule")
+		} else if _, ok := err.(client.UnknownCommandError); !ok {
+			t.Error("expected an UnknownCommandError for the unknown game rule")
+		}
+
+		if err := c.SetWorldBorder(1000, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SetWorldBorder(1000, 0); err != nil {
+			t.Fatal(err)
+		}
+		if diameter, err := c.WorldBorder(); err != nil || diameter != 1000 {
+			t.Errorf("world border did not match: %f %v\n", diameter, err)
+		}
+	})
+
+	t.Run("saving", func(t *testing.T) {
+		for _, step := range []func() error{c.SaveOff, c.SaveOff, c.SaveAll, c.SaveOn} {
+			if err := step(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if saving, saves := mc.Saving(); !saving || saves != 1 {
+			t.Errorf("saving did not match: %t %d\n", saving, saves)
+		}
+	})
+}
+
+func TestMinecraft_Errors(t *testing.T) {
+	mc := emulator.NewMinecraft("secret")
+
+	tests := map[string]string{
+		"foo":                "Unknown or incomplete command, see below for errorfoo<--[HERE]",
+		"whitelist add":      "Unknown or incomplete command, see below for error...telist add<--[HERE]",
+		"time set soon":      "Invalid integer 'soon'time set soon<--[HERE]",
+		"gamerule foo":       "Incorrect argument for commandgamerule foo<--[HERE]",
+		"gamerule pvp1 true": "Incorrect argument for commandgamerule pvp1 true<--[HERE]",
+		"gamerule keepInventory yes": "Invalid boolean, expected 'true' or 'false' but found 'yes'" +
+			"...Inventory yes<--[HERE]",
+	}
+	for cmd, expect := range tests {
+		if got := mc.Exec(cmd); got != expect {
+			t.Errorf("response of %q did not match:\nexpected: %s\ngot: %s\n", cmd, expect, got)
+		}
+	}
+}
+
+func TestMinecraft_Protocol(t *testing.T) {
+	t.Run("auth failed", func(t *testing.T) {
+		mc := emulator.NewMinecraft("secret")
+		addr := listen(t, mc.Serve, mc.Close)
+
+		c := client.NewMinecraftClient(dial(t, addr), util.GenerateRequestId)
+		if _, ok := c.Auth("wrong").(client.AuthFailedError); !ok {
+			t.Error("expected an AuthFailedError")
+		}
+	})
+
+	t.Run("no mirrored empty packet", func(t *testing.T) {
+		_, c, _ := startMinecraft(t)
+
+		if err := c.Write(grcon.Packet{Id: 7, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte{}}); err != nil {
+			t.Fatal(err)
+		}
+		packet, err := c.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(packet.Body) != "Unknown request 0" {
+			t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", "Unknown request 0", string(packet.Body))
+		}
+	})
+
+	t.Run("command too long", func(t *testing.T) {
+		_, c, _ := startMinecraft(t)
+
+		if _, err := c.Exec("say " + strings.Repeat("a", emulator.MinecraftMaxCommandLength-4)); err != nil {
+			t.Fatal(err)
+		}
+		_, err := c.Exec("say " + strings.Repeat("a", emulator.MinecraftMaxCommandLength-3))
+		if err != io.EOF {
+			t.Errorf("expected the connection to be closed but got %v\n", err)
+		}
+	})
+
+	t.Run("fragmentation", func(t *testing.T) {
+		// the fragments are 4096 characters long, so multi-byte characters make them longer than 4096 bytes.
+		tests := []struct {
+			response string
+			sizes    []int32
+		}{
+			// auth response, 4096 and 904 bytes of body.
+			{response: strings.Repeat("a", 5000), sizes: []int32{10, 4106, 914}},
+			// auth response, 8192 and 1808 bytes of body.
+			{response: strings.Repeat("é", 5000), sizes: []int32{10, 8202, 1818}},
+		}
+		for _, tt := range tests {
+			tt := tt
+			mc := emulator.NewMinecraft("secret")
+			mc.HandleFunc("dump", func(args string) string { return tt.response })
+			addr := listen(t, mc.Serve, mc.Close)
+
+			conn, err := net.Dial("tcp", addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+			remoteConsole := grcon.NewRemoteConsole(conn)
+			remoteConsole.Write(grcon.Packet{Id: 1, Type: grcon.SERVERDATA_AUTH, Body: []byte("secret")})
+			remoteConsole.Write(grcon.Packet{Id: 2, Type: grcon.SERVERDATA_EXECCOMMAND, Body: []byte("dump")})
+
+			// the fragments are longer than grcon.MaxBody and get read without grcon.RemoteConsole.
+			var sizes []int32
+			var body []byte
+			for len(sizes) < len(tt.sizes) {
+				var size int32
+				if err := binary.Read(conn, binary.LittleEndian, &size); err != nil {
+					t.Fatal(err)
+				}
+				packet := make([]byte, size)
+				if _, err := io.ReadFull(conn, packet); err != nil {
+					t.Fatal(err)
+				}
+				if len(sizes) > 0 {
+					// without the id, type and the two null bytes.
+					body = append(body, packet[8:size-2]...)
+				}
+				sizes = append(sizes, size)
+			}
+			if fmt.Sprint(sizes) != fmt.Sprint(tt.sizes) {
+				t.Errorf("packet sizes did not match:\nexpected: %v\ngot: %v\n", tt.sizes, sizes)
+			}
+			if string(body) != tt.response {
+				t.Errorf("expected the whole response with %d bytes but got %d\n", len(tt.response), len(body))
+			}
+		}
+	})
+
+	t.Run("fragment size of grcon", func(t *testing.T) {
+		mc := emulator.NewMinecraft("secret")
+		mc.FragmentSize = int(grcon.MaxBody)
+		mc.HandleFunc("dump", func(args string) string { return strings.Repeat("a", 5000) })
+		addr := listen(t, mc.Serve, mc.Close)
+
+		c := client.NewMinecraftClient(dial(t, addr), util.GenerateRequestId)
+		if err := c.Auth("secret"); err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.Exec("dump")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a fragment shorter than 4096 bytes is the whole response for the client.
+		if string(got) != strings.Repeat("a", int(grcon.MaxBody)) {
+			t.Errorf("expected the first fragment with %d bytes but got %d\n", grcon.MaxBody, len(got))
+		}
+	})
+
+	t.Run("fragmented response", func(t *testing.T) {
+		// a response of exactly one or two fragments has no shorter fragment that ends it.
+		// The fragments of multi-byte characters are longer than 4096 bytes, up to the client.MinecraftMaxPacket.
+		responses := []string{
+			strings.Repeat("a", 5000),
+			strings.Repeat("a", 4096),
+			strings.Repeat("a", 8192),
+			strings.Repeat("é", 5000),
+			strings.Repeat("𝄞", 4096),
+		}
+		for _, response := range responses {
+			response := response
+			mc := emulator.NewMinecraft("secret")
+			mc.HandleFunc("dump", func(args string) string { return response })
+			addr := listen(t, mc.Serve, mc.Close)
+
+			c := client.NewMinecraftClient(dialSize(t, addr, client.MinecraftMaxPacket), util.GenerateRequestId)
+			if err := c.Auth("secret"); err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.Exec("dump")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != response {
+				t.Errorf("expected the whole response with %d bytes but got %d\n", len(response), len(got))
+			}
+			// the connection is in sync after the fragments.
+			if got, err := c.Exec("list"); err != nil || !strings.HasPrefix(string(got), "There are 0") {
+				t.Errorf("response after the fragments did not match: %q %v\n", got, err)
+			}
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		mc := emulator.NewMinecraft("secret")
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		served := make(chan error, 1)
+		go func() { served <- mc.Serve(l) }()
+
+		c := client.NewMinecraftClient(dial(t, l.Addr().String()), util.GenerateRequestId)
+		if err := c.Auth("secret"); err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.Exec("stop")
+		if err != nil || string(got) != "Stopping the server" {
+			t.Errorf("response did not match: %q %v\n", got, err)
+		}
+		select {
+		case err := <-served:
+			if err != emulator.ErrServerClosed {
+				t.Errorf("expected: ErrServerClosed\ngot: %v\n", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("expected Serve to return after the stop command")
+		}
+	})
+}
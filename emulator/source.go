@@ -0,0 +1,507 @@
+package emulator
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+)
+
+// SourceTrailer is the body of the packet a SRCDS sends after the mirrored empty SERVERDATA_RESPONSE_VALUE.
+var SourceTrailer = []byte{0x00, 0x01, 0x00, 0x00}
+
+// Start values of the emulated Source server.
+const (
+	DefaultSourceHostname   = "grcon emulator"
+	DefaultSourceVersion    = "8835751/24 8835751 secure"
+	DefaultSourceMap        = "cp_badlands"
+	DefaultSourceMaxPlayers = 24
+)
+
+// SourcePlayer is a player or bot of the emulated Source server.
+type SourcePlayer struct {
+	UserID int
+	Name   string
+	// SteamID is generated from the user id if it is empty.
+	SteamID   string
+	Connected time.Duration
+	Ping      int
+	Loss      int
+	Address   string
+	Bot       bool
+}
+
+// SourceCvar is a console variable of the emulated Source server.
+type SourceCvar struct {
+	Value   string
+	Default string
+	Flags   []string
+	Help    string
+}
+
+// sourceCvars are the cvars of a new emulator.
+var sourceCvars = map[string]SourceCvar{
+	"sv_cheats":       {Value: "0", Flags: []string{"notify", "replicated"}, Help: "Allow cheats on server"},
+	"sv_password":     {Value: "", Flags: []string{"notify", "protected", "norecord", "server_can_execute"}, Help: "Server password for entry into multiplayer games"},
+	"sv_gravity":      {Value: "800", Flags: []string{"notify", "replicated"}, Help: "World gravity."},
+	"sv_tags":         {Value: "", Flags: []string{"notify"}, Help: "Server tags. Used to provide extra information to clients when they're browsing for servers. Separate tags with a comma."},
+	"mp_timelimit":    {Value: "30", Flags: []string{"notify", "replicated"}, Help: "game time per map in minutes"},
+	"mp_friendlyfire": {Value: "0", Flags: []string{"notify", "replicated"}, Help: "Allows team members to injure other members of their team"},
+	"mp_maxrounds":    {Value: "0", Flags: []string{"notify", "replicated"}, Help: "max number of rounds to play before server changes maps"},
+	"sv_region":       {Value: "-1", Help: "The region of the world to report this server in."},
+	"tf_bot_quota":    {Value: "0", Flags: []string{"game"}, Help: "Determines the total number of tf bots in the game."},
+}
+
+// NewSource is a constructor for the Source struct with the password and the default cvars and maps.
+func NewSource(password string) *Source {
+	s := &Source{
+		Password:     password,
+		Hostname:     DefaultSourceHostname,
+		Version:      DefaultSourceVersion,
+		MaxPlayers:   DefaultSourceMaxPlayers,
+		Maps:         []string{"cp_badlands", "cp_granary", "ctf_2fort", "koth_harvest_final", "pl_badwater"},
+		FragmentSize: int(grcon.MaxBody),
+		level:        DefaultSourceMap,
+		cvars:        map[string]*SourceCvar{},
+		nextUserID:   2,
+		started:      time.Now(),
+		commands:     map[string]func(args string) string{},
+	}
+	for name, cvar := range sourceCvars {
+		cvar := cvar
+		cvar.Default = cvar.Value
+		s.cvars[name] = &cvar
+	}
+	return s
+}
+
+// Source emulates the RCON interface of a Source dedicated server (SRCDS) like TF2.
+//
+// Like the real server it sends an empty SERVERDATA_RESPONSE_VALUE before the SERVERDATA_AUTH_RESPONSE,
+// splits long responses into multiple packets and answers an empty SERVERDATA_RESPONSE_VALUE with
+// the mirrored empty packet followed by a packet with the SourceTrailer body.
+// Commands of unauthenticated connections close the connection.
+type Source struct {
+	// Password for the RCON connections.
+	Password string
+	// Hostname, Version and MaxPlayers are shown by the status command.
+	Hostname   string
+	Version    string
+	MaxPlayers int
+	// Maps that are installed on the server.
+	Maps []string
+	// FragmentSize is the maximal body size of the response packets.
+	FragmentSize int
+
+	listener listener
+
+	mutex      sync.Mutex
+	level      string
+	cvars      map[string]*SourceCvar
+	players    []SourcePlayer
+	nextUserID int
+	connects   int
+	mapChanges int
+	started    time.Time
+	messages   []string
+	history    []string
+	stopped    bool
+	commands   map[string]func(args string) string
+}
+
+// Serve accepts connections on the listener and serves each of them in a new goroutine.
+// It returns ErrServerClosed after Close or the quit command.
+func (s *Source) Serve(l net.Listener) error {
+	return s.listener.serve(l, s.serveConn)
+}
+
+// Close closes all listeners and connections.
+func (s *Source) Close() error {
+	s.listener.close()
+	return nil
+}
+
+func (s *Source) serveConn(conn net.Conn) {
+	remoteConsole := grcon.NewRemoteConsole(conn)
+	authenticated := false
+
+	for {
+		packet, err := remoteConsole.Read()
+		if err != nil {
+			return
+		}
+
+		switch packet.Type {
+		case grcon.SERVERDATA_AUTH:
+			id := packet.Id
+			if s.Password == "" || string(packet.Body) != s.Password {
+				id = -1
+			}
+			authenticated = id != -1
+			err = writePacket(conn, packet.Id, grcon.SERVERDATA_RESPONSE_VALUE, []byte{})
+			if err == nil {
+				err = writePacket(conn, id, grcon.SERVERDATA_AUTH_RESPONSE, []byte{})
+			}
+		case grcon.SERVERDATA_EXECCOMMAND:
+			if !authenticated {
+				return
+			}
+			err = s.respond(conn, packet.Id, s.Exec(string(packet.Body)))
+		case grcon.SERVERDATA_RESPONSE_VALUE:
+			if !authenticated {
+				return
+			}
+			err = writePacket(conn, packet.Id, grcon.SERVERDATA_RESPONSE_VALUE, []byte{})
+			if err == nil {
+				err = writePacket(conn, packet.Id, grcon.SERVERDATA_RESPONSE_VALUE, SourceTrailer)
+			}
+		default:
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		if s.Stopped() {
+			s.Close()
+			return
+		}
+	}
+}
+
+// respond writes the response in fragments of the FragmentSize.
+func (s *Source) respond(conn net.Conn, id grcon.PacketId, response string) error {
+	size := s.FragmentSize
+	if size <= 0 || size > int(grcon.MaxBody) {
+		size = int(grcon.MaxBody)
+	}
+	for _, body := range fragments([]byte(response), size) {
+		if err := writePacket(conn, id, grcon.SERVERDATA_RESPONSE_VALUE, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleFunc adds a command or replaces a built-in command.
+// The handler gets the arguments after the name and returns the response.
+func (s *Source) HandleFunc(name string, handler func(args string) string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.commands[name] = handler
+}
+
+// Exec executes the command like it was received over RCON and returns the response.
+// Multiple commands can be separated by semicolons.
+func (s *Source) Exec(cmd string) string {
+	var response strings.Builder
+	for _, single := range strings.Split(cmd, ";") {
+		single = strings.TrimSpace(single)
+		if single == "" {
+			continue
+		}
+		response.WriteString(s.exec(single))
+	}
+	return response.String()
+}
+
+func (s *Source) exec(cmd string) string {
+	s.mutex.Lock()
+	s.history = append(s.history, cmd)
+	name, args := cmd, ""
+	if i := strings.IndexAny(cmd, " \t"); i >= 0 {
+		name, args = cmd[:i], strings.TrimSpace(cmd[i+1:])
+	}
+	handler, ok := s.commands[name]
+	s.mutex.Unlock()
+	if ok {
+		return handler(args)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch name {
+	case "status":
+		return s.status()
+	case "stats":
+		return s.stats()
+	case "cvarlist":
+		return s.cvarList(args)
+	case "maps":
+		return s.mapList(args)
+	case "changelevel":
+		return s.changeLevel(args)
+	case "kick":
+		return s.kick(unquote(args))
+	case "kickid":
+		return s.kickID(args)
+	case "say":
+		s.messages = append(s.messages, "Console: "+unquote(args))
+		return ""
+	case "echo":
+		return unquote(args) + "\n"
+	case "version":
+		return s.version()
+	case "quit", "exit":
+		s.stopped = true
+		return ""
+	}
+
+	if cvar, ok := s.cvars[name]; ok {
+		if args == "" {
+			return s.formatCvar(name, cvar)
+		}
+		cvar.Value = unquote(args)
+		return ""
+	}
+	if name == "hostname" {
+		if args == "" {
+			return fmt.Sprintf("\"hostname\" = \"%s\"\n - Hostname for server.\n", s.Hostname)
+		}
+		s.Hostname = unquote(args)
+		return ""
+	}
+
+	return fmt.Sprintf("Unknown command \"%s\"\n", name)
+}
+
+func (s *Source) status() string {
+	humans, bots := 0, 0
+	for _, player := range s.players {
+		if player.Bot {
+			bots++
+		} else {
+			humans++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "hostname: %s\n", s.Hostname)
+	fmt.Fprintf(&b, "version : %s\n", s.Version)
+	b.WriteString("udp/ip  : 0.0.0.0:27015  (public ip: 203.0.113.10)\n")
+	b.WriteString("steamid : [G:1:1234567] (85568392921234567)\n")
+	b.WriteString("account : not logged in  (No account specified)\n")
+	fmt.Fprintf(&b, "map     : %s at: 0 x, 0 y, 0 z\n", s.level)
+	fmt.Fprintf(&b, "tags    : %s\n", s.cvars["sv_tags"].Value)
+	fmt.Fprintf(&b, "players : %d humans, %d bots (%d max)\n", humans, bots, s.MaxPlayers)
+	b.WriteString("edicts  : 426 used of 2048 max\n")
+	b.WriteString("# userid name                uniqueid            connected ping loss state  adr\n")
+	for _, player := range s.players {
+		name := fmt.Sprintf("%q", player.Name)
+		if player.Bot {
+			fmt.Fprintf(&b, "#%7d %-19s %-19s %38s\n", player.UserID, name, "BOT", "active")
+			continue
+		}
+		fmt.Fprintf(&b, "#%7d %-19s %-19s %9s %4d %4d active %s\n",
+			player.UserID, name, player.SteamID, formatSourceDuration(player.Connected), player.Ping, player.Loss, player.Address)
+	}
+	return b.String()
+}
+
+func (s *Source) version() string {
+	build := strings.SplitN(s.Version, "/", 2)[0]
+	return fmt.Sprintf("Protocol version 24\nExe version %s (tf)\nExe build: 17:09:37 Feb 22 2024 (%s) (440)\n", build, build)
+}
+
+func (s *Source) stats() string {
+	uptime := int(time.Since(s.started).Minutes())
+	return fmt.Sprintf("CPU    In (KB/s)  Out (KB/s)  Uptime  Map changes  FPS      Players  Connects\n"+
+		"%-6.2f %-10.2f %-11.2f %-7d %-12d %-8.2f %-8d %d\n",
+		1.5, 2.25, 8.75, uptime, s.mapChanges, 66.67, len(s.players), s.connects)
+}
+
+func (s *Source) formatCvar(name string, cvar *SourceCvar) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\"%s\" = \"%s\" ( def. \"%s\" )\n", name, cvar.Value, cvar.Default)
+	if len(cvar.Flags) > 0 {
+		b.WriteString(" " + strings.Join(cvar.Flags, " ") + "\n")
+	}
+	if cvar.Help != "" {
+		b.WriteString(" - " + cvar.Help + "\n")
+	}
+	return b.String()
+}
+
+func (s *Source) cvarList(prefix string) string {
+	names := make([]string, 0, len(s.cvars))
+	for name := range s.cvars {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("cvar list\n--------------\n")
+	for _, name := range names {
+		cvar := s.cvars[name]
+		flags := ""
+		for _, flag := range cvar.Flags {
+			flags += fmt.Sprintf(", %q", flag)
+		}
+		fmt.Fprintf(&b, "%-40s : %-8s : %-28s : %s\n", name, cvar.Value, flags, cvar.Help)
+	}
+	fmt.Fprintf(&b, "--------------\n%4d convars/concommands for [%s]\n", len(names), prefix)
+	return b.String()
+}
+
+func (s *Source) mapList(filter string) string {
+	if filter == "" {
+		return "A map name filter is required, use * for all maps.\n"
+	}
+
+	var b strings.Builder
+	b.WriteString("-------------\n")
+	for _, name := range s.Maps {
+		if filter == "*" || strings.Contains(name, filter) {
+			fmt.Fprintf(&b, "PENDING:   (fs) %s.bsp\n", name)
+		}
+	}
+	return b.String()
+}
+
+func (s *Source) changeLevel(name string) string {
+	for _, m := range s.Maps {
+		if m == name {
+			s.level = name
+			s.mapChanges++
+			return ""
+		}
+	}
+	return fmt.Sprintf("changelevel failed: %s not found\n", name)
+}
+
+func (s *Source) kick(name string) string {
+	for _, player := range s.players {
+		if player.Name == name {
+			s.remove(player.UserID)
+			return ""
+		}
+	}
+	return fmt.Sprintf("Can't kick %s, no such player.\n", name)
+}
+
+func (s *Source) kickID(args string) string {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return "Usage:  kickid < userid | uniqueid > { message }\n"
+	}
+	for _, player := range s.players {
+		if fmt.Sprint(player.UserID) == fields[0] || player.SteamID == fields[0] {
+			s.remove(player.UserID)
+			return ""
+		}
+	}
+	return fmt.Sprintf("kickid:  no user found with userid %s\n", fields[0])
+}
+
+// remove removes the player. The mutex has to be held.
+func (s *Source) remove(userID int) {
+	for i, player := range s.players {
+		if player.UserID == userID {
+			s.players = append(s.players[:i], s.players[i+1:]...)
+			return
+		}
+	}
+}
+
+// Join adds the player with the next user id and returns it.
+// An empty SteamID and Address are generated.
+func (s *Source) Join(player SourcePlayer) SourcePlayer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	player.UserID = s.nextUserID
+	s.nextUserID++
+	if player.Bot {
+		player.SteamID = "BOT"
+	} else {
+		if player.SteamID == "" {
+			player.SteamID = fmt.Sprintf("[U:1:%d]", 1000+player.UserID)
+		}
+		if player.Address == "" {
+			player.Address = fmt.Sprintf("198.51.100.%d:27005", player.UserID%256)
+		}
+		s.connects++
+	}
+	s.players = append(s.players, player)
+	return player
+}
+
+// Players returns the players and bots.
+func (s *Source) Players() []SourcePlayer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]SourcePlayer{}, s.players...)
+}
+
+// Map returns the current map.
+func (s *Source) Map() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.level
+}
+
+// Cvar returns the cvar.
+func (s *Source) Cvar(name string) (SourceCvar, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	cvar, ok := s.cvars[name]
+	if !ok {
+		return SourceCvar{}, false
+	}
+	return *cvar, true
+}
+
+// SetCvar adds or changes the cvar. An empty Default is set to the Value.
+func (s *Source) SetCvar(name string, cvar SourceCvar) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if cvar.Default == "" {
+		cvar.Default = cvar.Value
+	}
+	s.cvars[name] = &cvar
+}
+
+// Messages returns the messages of the say command.
+func (s *Source) Messages() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.messages...)
+}
+
+// History returns all executed commands.
+func (s *Source) History() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.history...)
+}
+
+// Stopped reports if the quit command was executed.
+func (s *Source) Stopped() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.stopped
+}
+
+// formatSourceDuration formats the duration like the status command as [hh:]mm:ss.
+func formatSourceDuration(d time.Duration) string {
+	seconds := int(d.Seconds())
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+// unquote removes the quotes around an argument.
+func unquote(arg string) string {
+	if len(arg) >= 2 && arg[0] == '"' && arg[len(arg)-1] == '"' {
+		return arg[1 : len(arg)-1]
+	}
+	return arg
+}
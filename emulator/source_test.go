@@ -0,0 +1,207 @@
+package emulator_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/emulator"
+	"github.com/hamburghammer/grcon/util"
+)
+
+func startSource(t *testing.T) (*emulator.Source, client.SourceClient, string) {
+	src := emulator.NewSource("secret")
+	addr := listen(t, src.Serve, src.Close)
+
+	sourceClient := client.NewSourceClient(dial(t, addr), util.GenerateRequestId)
+	if err := sourceClient.Auth("secret"); err != nil {
+		t.Fatal(err)
+	}
+	return src, sourceClient, addr
+}
+
+func TestSource_Client(t *testing.T) {
+	src, c, _ := startSource(t)
+	src.Join(emulator.SourcePlayer{Name: `Player "One"`, Connected: 312 * time.Second, Ping: 67})
+	src.Join(emulator.SourcePlayer{Name: "Heavy", Bot: true})
+
+	t.Run("status", func(t *testing.T) {
+		status, err := c.Status()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Hostname != emulator.DefaultSourceHostname || status.Map != "cp_badlands" ||
+			status.Humans != 1 || status.Bots != 1 || status.MaxPlayers != 24 {
+			t.Errorf("status did not match: %+v\n", status)
+		}
+		if len(status.Players) != 2 {
+			t.Fatalf("expected 2 players but got %d\n", len(status.Players))
+		}
+		expected := client.SourcePlayer{
+			UserID: 2, Name: `Player \"One\"`, SteamID: "[U:1:1002]", Connected: 312 * time.Second,
+			Ping: 67, State: "active", Address: "198.51.100.2:27005",
+		}
+		if status.Players[0] != expected {
+			t.Errorf("player did not match:\nexpected: %+v\ngot: %+v\n", expected, status.Players[0])
+		}
+		if !status.Players[1].Bot || status.Players[1].Name != "Heavy" {
+			t.Errorf("bot did not match: %+v\n", status.Players[1])
+		}
+	})
+
+	t.Run("cvars", func(t *testing.T) {
+		if err := c.SetCvar("sv_gravity", "400"); err != nil {
+			t.Fatal(err)
+		}
+		cvar, err := c.GetCvar("sv_gravity")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cvar.Value != "400" || cvar.Default != "800" || strings.Join(cvar.Flags, ",") != "notify,replicated" || cvar.Description != "World gravity." {
+			t.Errorf("cvar did not match: %+v\n", cvar)
+		}
+		if stored, _ := src.Cvar("sv_gravity"); stored.Value != "400" {
+			t.Errorf("stored value did not match:\nexpected: 400\ngot: %s\n", stored.Value)
+		}
+
+		if _, ok := c.SetCvar("sv_unknown", "1").(client.UnknownCommandError); !ok {
+			t.Error("expected an UnknownCommandError for the unknown cvar")
+		}
+
+		list, err := c.CvarList("mp_")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 3 || list[0].Name != "mp_friendlyfire" || list[0].Flags[0] != "notify" {
+			t.Errorf("cvar list did not match: %+v\n", list)
+		}
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		stats, err := c.Stats()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Players != 2 || stats.Connects != 1 || stats.FPS != 66.67 {
+			t.Errorf("stats did not match: %+v\n", stats)
+		}
+	})
+
+	t.Run("maps", func(t *testing.T) {
+		maps, err := c.Maps("cp_")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(maps, ",") != "cp_badlands,cp_granary" {
+			t.Errorf("maps did not match: %v\n", maps)
+		}
+
+		if _, err := c.Exec("changelevel ctf_2fort"); err != nil {
+			t.Fatal(err)
+		}
+		if src.Map() != "ctf_2fort" {
+			t.Errorf("map did not match:\nexpected: ctf_2fort\ngot: %s\n", src.Map())
+		}
+	})
+
+	t.Run("kick", func(t *testing.T) {
+		if _, err := c.Exec("kickid 2 bye"); err != nil {
+			t.Fatal(err)
+		}
+		if players := src.Players(); len(players) != 1 || !players[0].Bot {
+			t.Errorf("players did not match: %+v\n", players)
+		}
+	})
+}
+
+func TestSource_Protocol(t *testing.T) {
+	t.Run("pre auth response", func(t *testing.T) {
+		src := emulator.NewSource("secret")
+		addr := listen(t, src.Serve, src.Close)
+		remoteConsole := dial(t, addr)
+
+		remoteConsole.Write(grcon.Packet{Id: 5, Type: grcon.SERVERDATA_AUTH, Body: []byte("wrong")})
+		expected := []grcon.Packet{
+			{Id: 5, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte{}},
+			{Id: -1, Type: grcon.SERVERDATA_AUTH_RESPONSE, Body: []byte{}},
+		}
+		for _, expect := range expected {
+			packet, err := remoteConsole.Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if packet.Id != expect.Id || packet.Type != expect.Type || len(packet.Body) != 0 {
+				t.Errorf("packet did not match:\nexpected: %+v\ngot: %+v\n", expect, packet)
+			}
+		}
+	})
+
+	t.Run("mirrored empty packet with trailer", func(t *testing.T) {
+		_, c, _ := startSource(t)
+
+		if err := c.Write(grcon.Packet{Id: 9, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte{}}); err != nil {
+			t.Fatal(err)
+		}
+		for _, body := range [][]byte{{}, emulator.SourceTrailer} {
+			packet, err := c.Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if packet.Id != 9 || !bytes.Equal(packet.Body, body) {
+				t.Errorf("packet did not match:\nexpected: %v\ngot: %+v\n", body, packet)
+			}
+		}
+	})
+
+	t.Run("multi packet response", func(t *testing.T) {
+		src, c, _ := startSource(t)
+		src.HandleFunc("dump", func(args string) string { return strings.Repeat("a", 10000) })
+
+		// the trailers of the delimiters must not break the following commands.
+		for i := 0; i < 3; i++ {
+			got, err := c.Exec("dump")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 10000 {
+				t.Errorf("response length did not match:\nexpected: 10000\ngot: %d\n", len(got))
+			}
+		}
+	})
+
+	t.Run("command before auth", func(t *testing.T) {
+		src := emulator.NewSource("secret")
+		addr := listen(t, src.Serve, src.Close)
+		remoteConsole := dial(t, addr)
+
+		remoteConsole.Write(grcon.Packet{Id: 1, Type: grcon.SERVERDATA_EXECCOMMAND, Body: []byte("status")})
+		if _, err := remoteConsole.Read(); err == nil {
+			t.Error("expected the connection to be closed")
+		}
+	})
+}
+
+func TestSource_Exec(t *testing.T) {
+	src := emulator.NewSource("secret")
+
+	tests := map[string]string{
+		"foo":                  "Unknown command \"foo\"\n",
+		"echo a; echo b":       "a\nb\n",
+		"changelevel de_dust2": "changelevel failed: de_dust2 not found\n",
+		`sv_cheats`:            "\"sv_cheats\" = \"0\" ( def. \"0\" )\n notify replicated\n - Allow cheats on server\n",
+		`hostname "My Server"`: "",
+		"kickid 42":            "kickid:  no user found with userid 42\n",
+		"version":              "Protocol version 24\nExe version 8835751 (tf)\nExe build: 17:09:37 Feb 22 2024 (8835751) (440)\n",
+	}
+	for cmd, expect := range tests {
+		if got := src.Exec(cmd); got != expect {
+			t.Errorf("response of %q did not match:\nexpected: %q\ngot: %q\n", cmd, expect, got)
+		}
+	}
+	if src.Hostname != "My Server" {
+		t.Errorf("hostname did not match:\nexpected: My Server\ngot: %s\n", src.Hostname)
+	}
+}
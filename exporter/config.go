@@ -0,0 +1,148 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hamburghammer/grcon/inventory"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// Config of the exporter binary. The servers are taken from an inventory file (see the inventory package).
+//
+//	{
+//		"listen": ":9150",
+//		"inventory": "grcon.json",
+//		"interval": "15s",
+//		"collectors": [
+//			{"servers": "game=minecraft", "parser": "minecraft_list"},
+//			{"servers": "game=cs2", "parser": "source_status"},
+//			{"servers": "name=modded", "command": "forge tps", "regex": "Mean TPS: (?P<tps>[0-9.]+)"}
+//		]
+//	}
+type Config struct {
+	// Listen is the address of the HTTP server.
+	Listen string `json:"listen"`
+	// Inventory is the path of the inventory file. Relative paths are resolved
+	// against the directory of the config file.
+	Inventory  string            `json:"inventory"`
+	Interval   util.Duration     `json:"interval"`
+	Collectors []CollectorConfig `json:"collectors"`
+}
+
+// CollectorConfig describes a command that is polled on the selected servers.
+type CollectorConfig struct {
+	// Servers is a selector like "tag=eu,game=cs2".
+	Servers string `json:"servers"`
+	// Parser is the name of one of the Builtins. It is not needed if a Regex is set.
+	Parser string `json:"parser"`
+	// Command overrides the command of the Parser.
+	Command string `json:"command"`
+	// Regex extracts a gauge for every named group.
+	Regex string `json:"regex"`
+}
+
+// LoadConfig reads and validates the JSON config file.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, fmt.Errorf("parsing config '%s': %w", path, err)
+	}
+	if config.Inventory != "" && !filepath.IsAbs(config.Inventory) {
+		config.Inventory = filepath.Join(filepath.Dir(path), config.Inventory)
+	}
+
+	return config, config.Validate()
+}
+
+// Validate checks that all required values are set and the parsers exist.
+func (c Config) Validate() error {
+	if c.Inventory == "" {
+		return fmt.Errorf("inventory is missing")
+	}
+	for i, collector := range c.Collectors {
+		if _, err := collector.collector(); err != nil {
+			return fmt.Errorf("collector %d: %w", i, err)
+		}
+		if _, err := inventory.ParseSelector(collector.Servers); err != nil {
+			return fmt.Errorf("collector %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// collector builds the Collector of the config.
+func (cc CollectorConfig) collector() (Collector, error) {
+	if cc.Regex != "" {
+		if cc.Command == "" {
+			return Collector{}, fmt.Errorf("command is missing")
+		}
+		parse, err := RegexParser(cc.Regex)
+		if err != nil {
+			return Collector{}, err
+		}
+		return Collector{Command: cc.Command, Parse: parse}, nil
+	}
+
+	builtin, ok := Builtins[cc.Parser]
+	if !ok {
+		return Collector{}, fmt.Errorf("unknown parser '%s'", cc.Parser)
+	}
+	command := cc.Command
+	if command == "" {
+		command = builtin.Command
+	}
+	if command == "" {
+		return Collector{}, fmt.Errorf("command is missing")
+	}
+	return Collector{Command: command, Parse: builtin.Parse}, nil
+}
+
+// NewExporter loads the inventory and creates the exporter with one ReconnectingClient per server.
+func (c Config) NewExporter() (*Exporter, error) {
+	inv, err := inventory.Load(c.Inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	collectors := map[string][]Collector{}
+	for _, collectorConfig := range c.Collectors {
+		collector, err := collectorConfig.collector()
+		if err != nil {
+			return nil, err
+		}
+		names, err := inv.Select(collectorConfig.Servers)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			collectors[name] = append(collectors[name], collector)
+		}
+	}
+
+	targets := []Target{}
+	for _, name := range inv.Names() {
+		if len(collectors[name]) == 0 {
+			continue
+		}
+		rc, err := inv.Client(name)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, Target{Name: name, Client: rc, Collectors: collectors[name]})
+	}
+
+	e := New(targets, nil)
+	if c.Interval > 0 {
+		e.Interval = time.Duration(c.Interval)
+	}
+	return e, nil
+}
@@ -0,0 +1,269 @@
+/*
+Package exporter polls RCON servers and serves the results in the Prometheus text format.
+
+Every server has collectors that execute a command in a fixed interval and extract gauges
+from the response with a ParseFunc. Besides the parsed gauges the exporter exposes the
+health of the RCON connections: the last poll result, the command latency and the
+connection counters of a client.ReconnectingClient.
+*/
+package exporter
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+)
+
+// DefaultInterval is the time between two polls.
+const DefaultInterval = 15 * time.Second
+
+// Collector executes a command and parses the response.
+type Collector struct {
+	Command string
+	Parse   ParseFunc
+}
+
+// Target is a server with its collectors.
+type Target struct {
+	Name string
+	// Client of the server. The connection counters are exported if it is a *client.ReconnectingClient.
+	Client     client.Client
+	Collectors []Collector
+}
+
+// New is a constructor for the Exporter struct.
+func New(targets []Target, logger *log.Logger) *Exporter {
+	return &Exporter{Targets: targets, Interval: DefaultInterval, Logger: logger}
+}
+
+// Exporter polls the targets and serves the metrics of the last poll.
+// It implements the http.Handler interface to serve the metrics.
+type Exporter struct {
+	Targets  []Target
+	Interval time.Duration
+	// Logger for failed polls. Nothing is logged if it is nil.
+	Logger *log.Logger
+
+	mutex  sync.Mutex
+	states map[string]*targetState
+}
+
+// reservedNames are the metrics of the exporter itself. Parsed gauges with these names are skipped.
+var reservedNames = map[string]bool{
+	"grcon_up":                          true,
+	"grcon_last_poll_timestamp_seconds": true,
+	"grcon_command_duration_seconds":    true,
+	"grcon_command_errors_total":        true,
+	"grcon_connected":                   true,
+	"grcon_auth_success_total":          true,
+	"grcon_auth_failures_total":         true,
+	"grcon_dial_failures_total":         true,
+	"grcon_reconnects_total":            true,
+	"grcon_connection_errors_total":     true,
+}
+
+// targetState is the result of the polls of a target.
+type targetState struct {
+	up       bool
+	lastPoll time.Time
+	// gauges are keyed by the metric name.
+	gauges map[string]float64
+	// durations and errors are keyed by the command.
+	durations map[string]float64
+	errors    map[string]int
+}
+
+// statsClient is implemented by the client.ReconnectingClient.
+type statsClient interface {
+	Stats() client.ConnectionStats
+}
+
+// Run polls the targets immediately and then in the Interval until the context is done.
+func (e *Exporter) Run(ctx context.Context) {
+	interval := e.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		e.Poll()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll executes the collectors of all targets once. The targets are polled concurrently.
+func (e *Exporter) Poll() {
+	var wg sync.WaitGroup
+	for _, target := range e.Targets {
+		target := target
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.poll(target)
+		}()
+	}
+	wg.Wait()
+}
+
+// poll executes the collectors of the target one after another.
+// Parsed gauges that collide with the reserved names or with other gauges are skipped and logged.
+func (e *Exporter) poll(target Target) {
+	up := true
+	gauges := map[string]float64{}
+	durations := map[string]float64{}
+	failed := []string{}
+
+	for _, collector := range target.Collectors {
+		start := time.Now()
+		response, err := target.Client.Exec(collector.Command)
+		durations[collector.Command] = time.Since(start).Seconds()
+
+		if err == nil {
+			var parsed map[string]float64
+			parsed, err = collector.Parse(string(response))
+			for _, name := range sortedKeys(parsed) {
+				metricName := MetricName(name)
+				if _, ok := gauges[metricName]; ok || reservedNames[metricName] {
+					e.logf("server=%q command=%q error=%q", target.Name, collector.Command, "metric name "+metricName+" is already used")
+					continue
+				}
+				gauges[metricName] = parsed[name]
+			}
+		}
+		if err != nil {
+			up = false
+			failed = append(failed, collector.Command)
+			e.logf("server=%q command=%q error=%q", target.Name, collector.Command, err.Error())
+		}
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.states == nil {
+		e.states = map[string]*targetState{}
+	}
+	state, ok := e.states[target.Name]
+	if !ok {
+		state = &targetState{errors: map[string]int{}}
+		e.states[target.Name] = state
+	}
+	state.up = up
+	state.lastPoll = time.Now()
+	state.gauges = gauges
+	state.durations = durations
+	for _, cmd := range failed {
+		state.errors[cmd]++
+	}
+}
+
+// Metrics returns the metrics of the last poll.
+func (e *Exporter) Metrics() []Metric {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	up := Metric{Name: "grcon_up", Help: "Whether all commands of the last poll succeeded.", Type: TypeGauge}
+	lastPoll := Metric{Name: "grcon_last_poll_timestamp_seconds", Help: "Unix time of the last poll.", Type: TypeGauge}
+	durations := Metric{Name: "grcon_command_duration_seconds", Help: "Latency of the command in the last poll.", Type: TypeGauge}
+	errors := Metric{Name: "grcon_command_errors_total", Help: "Number of polls in which the command or its parser failed.", Type: TypeCounter}
+	health := []Metric{
+		{Name: "grcon_connected", Help: "Whether a RCON connection is open.", Type: TypeGauge},
+		{Name: "grcon_auth_success_total", Help: "Number of successfully authenticated RCON connections.", Type: TypeCounter},
+		{Name: "grcon_auth_failures_total", Help: "Number of RCON connections that failed to authenticate.", Type: TypeCounter},
+		{Name: "grcon_dial_failures_total", Help: "Number of RCON connections that could not be opened.", Type: TypeCounter},
+		{Name: "grcon_reconnects_total", Help: "Number of RCON connections opened after the first one.", Type: TypeCounter},
+		{Name: "grcon_connection_errors_total", Help: "Number of commands that broke the RCON connection.", Type: TypeCounter},
+	}
+	gauges := map[string]*Metric{}
+
+	for _, target := range e.Targets {
+		server := Label{Name: "server", Value: target.Name}
+
+		if c, ok := target.Client.(statsClient); ok {
+			stats := c.Stats()
+			connected := 0.0
+			if stats.Connected {
+				connected = 1
+			}
+			values := []float64{connected, float64(stats.Connects), float64(stats.AuthFailures),
+				float64(stats.DialFailures), float64(stats.Reconnects()), float64(stats.ConnectionErrors)}
+			for i, value := range values {
+				health[i].Samples = append(health[i].Samples, Sample{Labels: []Label{server}, Value: value})
+			}
+		}
+
+		state, ok := e.states[target.Name]
+		if !ok {
+			continue
+		}
+
+		up.Samples = append(up.Samples, Sample{Labels: []Label{server}, Value: boolValue(state.up)})
+		lastPoll.Samples = append(lastPoll.Samples, Sample{Labels: []Label{server}, Value: float64(state.lastPoll.UnixNano()) / 1e9})
+		for _, cmd := range sortedKeys(state.durations) {
+			labels := []Label{server, {Name: "command", Value: cmd}}
+			durations.Samples = append(durations.Samples, Sample{Labels: labels, Value: state.durations[cmd]})
+			errors.Samples = append(errors.Samples, Sample{Labels: labels, Value: float64(state.errors[cmd])})
+		}
+
+		for _, metricName := range sortedKeys(state.gauges) {
+			gauge, ok := gauges[metricName]
+			if !ok {
+				gauge = &Metric{Name: metricName, Help: "Parsed from the response of a polled command.", Type: TypeGauge}
+				gauges[metricName] = gauge
+			}
+			gauge.Samples = append(gauge.Samples, Sample{Labels: []Label{server}, Value: state.gauges[metricName]})
+		}
+	}
+
+	metrics := append([]Metric{up, lastPoll, durations, errors}, health...)
+	for _, gauge := range gauges {
+		metrics = append(metrics, *gauge)
+	}
+	return metrics
+}
+
+// ServeHTTP writes the metrics of the last poll.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	WriteText(w, e.Metrics())
+}
+
+func (e *Exporter) logf(format string, args ...interface{}) {
+	if e.Logger != nil {
+		e.Logger.Printf(format, args...)
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
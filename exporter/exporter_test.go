@@ -0,0 +1,181 @@
+package exporter_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hamburghammer/grcon/exporter"
+)
+
+// MockClient answers with the Responses and fails on unknown commands.
+type MockClient struct {
+	Responses map[string]string
+}
+
+func (m *MockClient) Auth(password string) error {
+	return nil
+}
+
+func (m *MockClient) Exec(cmd string) ([]byte, error) {
+	response, ok := m.Responses[cmd]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	return []byte(response), nil
+}
+
+func TestExporter(t *testing.T) {
+	list := exporter.Collector{Command: "list", Parse: exporter.ParseMinecraftList}
+	e := exporter.New([]exporter.Target{
+		{
+			Name:       "survival",
+			Client:     &MockClient{Responses: map[string]string{"list": "There are 2 of a max of 20 players online: alice, bob"}},
+			Collectors: []exporter.Collector{list},
+		},
+		{Name: "creative", Client: &MockClient{}, Collectors: []exporter.Collector{list}},
+	}, nil)
+
+	e.Poll()
+	e.Poll()
+
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status did not match:\nexpected: %d\ngot: %d\n", http.StatusOK, recorder.Code)
+	}
+	body := recorder.Body.String()
+
+	for _, line := range []string{
+		"# TYPE grcon_players_online gauge",
+		`grcon_players_online{server="survival"} 2`,
+		`grcon_players_max{server="survival"} 20`,
+		`grcon_up{server="survival"} 1`,
+		`grcon_up{server="creative"} 0`,
+		`grcon_command_errors_total{server="creative",command="list"} 2`,
+		`grcon_command_errors_total{server="survival",command="list"} 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("metrics did not contain: %s\ngot:\n%s\n", line, body)
+		}
+	}
+	if strings.Contains(body, `grcon_players_online{server="creative"}`) {
+		t.Errorf("failed server has gauges:\n%s\n", body)
+	}
+}
+
+func TestExporter_ReservedNames(t *testing.T) {
+	parse := func(response string) (map[string]float64, error) {
+		return map[string]float64{"up": 0, "players.online": 1, "players_online": 2, "tps": 20}, nil
+	}
+	var logs bytes.Buffer
+	e := exporter.New([]exporter.Target{{
+		Name:       "survival",
+		Client:     &MockClient{Responses: map[string]string{"stats": ""}},
+		Collectors: []exporter.Collector{{Command: "stats", Parse: parse}},
+	}}, log.New(&logs, "", 0))
+
+	e.Poll()
+
+	var body bytes.Buffer
+	exporter.WriteText(&body, e.Metrics())
+	for _, line := range []string{
+		`grcon_up{server="survival"} 1`,
+		`grcon_players_online{server="survival"} 1`,
+		`grcon_tps{server="survival"} 20`,
+	} {
+		if !strings.Contains(body.String(), line+"\n") {
+			t.Errorf("metrics did not contain: %s\ngot:\n%s\n", line, body.String())
+		}
+	}
+	if strings.Count(body.String(), "grcon_up{") != 1 || strings.Count(body.String(), "grcon_players_online{") != 1 {
+		t.Errorf("colliding gauges were exported:\n%s\n", body.String())
+	}
+	if !strings.Contains(logs.String(), "metric name grcon_up is already used") {
+		t.Errorf("expected the collision to be logged but got: %s\n", logs.String())
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	err := exporter.WriteText(&buf, []exporter.Metric{
+		{Name: "grcon_b", Type: exporter.TypeCounter, Samples: []exporter.Sample{{Value: 1.5}}},
+		{Name: "grcon_a", Help: "A\nB", Type: exporter.TypeGauge, Samples: []exporter.Sample{
+			{Labels: []exporter.Label{{Name: "server", Value: `say "hi"\`}}, Value: 3},
+		}},
+		{Name: "grcon_empty", Type: exporter.TypeGauge},
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	expected := "# HELP grcon_a A\\nB\n# TYPE grcon_a gauge\ngrcon_a{server=\"say \\\"hi\\\"\\\\\"} 3\n" +
+		"# TYPE grcon_b counter\ngrcon_b 1.5\n"
+	if buf.String() != expected {
+		t.Errorf("text did not match:\nexpected: %s\ngot: %s\n", expected, buf.String())
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	writeFile("grcon.json", `{"servers": {
+		"survival": {"address": "127.0.0.1:25575", "dialect": "minecraft", "password": "secret", "game": "minecraft"},
+		"cs2": {"address": "127.0.0.1:27015", "dialect": "source", "password": "secret", "game": "cs2"}
+	}}`)
+
+	t.Run("valid config", func(t *testing.T) {
+		path := writeFile("exporter.json", `{
+			"inventory": "grcon.json",
+			"interval": "30s",
+			"collectors": [
+				{"servers": "game=minecraft", "parser": "minecraft_list"},
+				{"servers": "game=minecraft", "command": "forge tps", "regex": "TPS: (?P<tps>[0-9.]+)"}
+			]
+		}`)
+
+		config, err := exporter.LoadConfig(path)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		e, err := config.NewExporter()
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if len(e.Targets) != 1 || e.Targets[0].Name != "survival" || len(e.Targets[0].Collectors) != 2 {
+			t.Errorf("targets did not match: %+v\n", e.Targets)
+		}
+		if e.Targets[0].Collectors[1].Command != "forge tps" {
+			t.Errorf("command did not match:\nexpected: %s\ngot: %s\n", "forge tps", e.Targets[0].Collectors[1].Command)
+		}
+	})
+
+	t.Run("invalid collectors", func(t *testing.T) {
+		for _, collector := range []string{
+			`{"parser": "unknown"}`,
+			`{"regex": "(?P<tps>\\d+)"}`,
+			`{"parser": "json"}`,
+			`{"parser": "minecraft_list", "servers": "tag"}`,
+		} {
+			path := writeFile("invalid.json", `{"inventory": "grcon.json", "collectors": [`+collector+`]}`)
+			if _, err := exporter.LoadConfig(path); err == nil {
+				t.Errorf("expected an error for the collector: %s\n", collector)
+			}
+		}
+	})
+}
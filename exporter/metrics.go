@@ -0,0 +1,84 @@
+package exporter
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metric types of the Prometheus text format.
+const (
+	TypeGauge   = "gauge"
+	TypeCounter = "counter"
+)
+
+// Metric is a metric family of the Prometheus text format.
+type Metric struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Sample is a single value of a metric.
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// Label is a name value pair of a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// MetricName converts the name into a valid metric name with the "grcon_" prefix.
+// Invalid characters are replaced by underscores.
+func MetricName(name string) string {
+	return "grcon_" + invalidNameChars.ReplaceAllString(name, "_")
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// WriteText writes the metrics sorted by name in the Prometheus text exposition format.
+// Metrics without samples are skipped.
+func WriteText(w io.Writer, metrics []Metric) error {
+	sorted := append([]Metric{}, metrics...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	buf := bufio.NewWriter(w)
+	for _, metric := range sorted {
+		if len(metric.Samples) == 0 {
+			continue
+		}
+		if metric.Help != "" {
+			buf.WriteString("# HELP " + metric.Name + " " + helpEscaper.Replace(metric.Help) + "\n")
+		}
+		if metric.Type != "" {
+			buf.WriteString("# TYPE " + metric.Name + " " + metric.Type + "\n")
+		}
+
+		for _, sample := range metric.Samples {
+			buf.WriteString(metric.Name)
+			if len(sample.Labels) > 0 {
+				buf.WriteByte('{')
+				for i, label := range sample.Labels {
+					if i > 0 {
+						buf.WriteByte(',')
+					}
+					buf.WriteString(label.Name + `="` + labelValueEscaper.Replace(label.Value) + `"`)
+				}
+				buf.WriteByte('}')
+			}
+			buf.WriteString(" " + strconv.FormatFloat(sample.Value, 'g', -1, 64) + "\n")
+		}
+	}
+
+	return buf.Flush()
+}
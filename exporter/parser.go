@@ -0,0 +1,164 @@
+package exporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+)
+
+// ParseFunc extracts gauges from the response of a command.
+// The keys are the metric names without the "grcon_" prefix.
+// Names of the metrics of the exporter itself, like "up", are skipped.
+type ParseFunc func(response string) (map[string]float64, error)
+
+// Builtin is a parser with the command it expects.
+type Builtin struct {
+	// Command is polled if the collector has no own command.
+	Command string
+	Parse   ParseFunc
+}
+
+// factorioCommand prints the gauges of a factorio server as JSON object.
+const factorioCommand = "/silent-command rcon.print(game.table_to_json({" +
+	"players_online = #game.connected_players, players_total = #game.players, tick = game.tick}))"
+
+// Builtins are the parsers that can be selected by name.
+//	- minecraft_list: players_online and players_max of "list"
+//	- source_status: players_online, bots_online and players_max of "status"
+//	- source_stats: cpu_percent, net_in_kilobytes_per_second, net_out_kilobytes_per_second,
+//	  uptime_seconds, map_changes, fps, players_online and connects of "stats"
+//	- factorio: players_online, players_total and tick from a Lua script
+//	- json: all numbers and booleans of a flat JSON object printed by a custom command
+var Builtins = map[string]Builtin{
+	"minecraft_list": {Command: "list", Parse: ParseMinecraftList},
+	"source_status":  {Command: "status", Parse: ParseSourceStatus},
+	"source_stats":   {Command: "stats", Parse: ParseSourceStats},
+	"factorio":       {Command: factorioCommand, Parse: ParseJSON},
+	"json":           {Parse: ParseJSON},
+}
+
+// ParseMinecraftList extracts the player counts from the response of the list command.
+func ParseMinecraftList(response string) (map[string]float64, error) {
+	list, err := client.ParseMinecraftPlayerList(strings.TrimSpace(response))
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]float64{
+		"players_online": float64(list.Online),
+		"players_max":    float64(list.Max),
+	}, nil
+}
+
+// ParseSourceStatus extracts the player counts from the response of the status command.
+func ParseSourceStatus(response string) (map[string]float64, error) {
+	status, err := client.ParseSourceStatus(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]float64{
+		"players_online": float64(status.Humans),
+		"bots_online":    float64(status.Bots),
+		"players_max":    float64(status.MaxPlayers),
+	}, nil
+}
+
+// ParseSourceStats extracts the performance values from the response of the stats command.
+func ParseSourceStats(response string) (map[string]float64, error) {
+	stats, err := client.ParseSourceStats(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]float64{
+		"cpu_percent":                  stats.CPU,
+		"net_in_kilobytes_per_second":  stats.NetIn,
+		"net_out_kilobytes_per_second": stats.NetOut,
+		"uptime_seconds":               stats.Uptime.Seconds(),
+		"map_changes":                  float64(stats.MapChanges),
+		"fps":                          stats.FPS,
+		"players_online":               float64(stats.Players),
+		"connects":                     float64(stats.Connects),
+	}, nil
+}
+
+// ParseJSON extracts the numbers and booleans of a flat JSON object. Booleans become 0 or 1.
+// Other values are ignored.
+func ParseJSON(response string) (map[string]float64, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(response)), &object); err != nil {
+		return nil, fmt.Errorf("parsing JSON response: %w", err)
+	}
+
+	gauges := map[string]float64{}
+	for key, value := range object {
+		switch value := value.(type) {
+		case float64:
+			gauges[key] = value
+		case bool:
+			gauges[key] = 0
+			if value {
+				gauges[key] = 1
+			}
+		}
+	}
+	return gauges, nil
+}
+
+// RegexParser returns a parser that extracts a gauge for every named group of the
+// regular expression, e.g. `Mean TPS: (?P<tps>[0-9.]+)`.
+// Durations like "1m30s" are converted to seconds.
+func RegexParser(expr string) (ParseFunc, error) {
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	named := false
+	for _, name := range regex.SubexpNames() {
+		if name != "" {
+			named = true
+		}
+	}
+	if !named {
+		return nil, fmt.Errorf("regex '%s' has no named group", expr)
+	}
+
+	return func(response string) (map[string]float64, error) {
+		matches := regex.FindStringSubmatch(response)
+		if matches == nil {
+			return nil, errors.New("regex did not match the response")
+		}
+
+		gauges := map[string]float64{}
+		for i, name := range regex.SubexpNames() {
+			if name == "" || matches[i] == "" {
+				continue
+			}
+			value, err := parseValue(matches[i])
+			if err != nil {
+				return nil, fmt.Errorf("group '%s': %w", name, err)
+			}
+			gauges[name] = value
+		}
+		return gauges, nil
+	}, nil
+}
+
+func parseValue(s string) (float64, error) {
+	value, err := strconv.ParseFloat(s, 64)
+	if err == nil {
+		return value, nil
+	}
+	if d, durationErr := time.ParseDuration(s); durationErr == nil {
+		return d.Seconds(), nil
+	}
+	return 0, err
+}
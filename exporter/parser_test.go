@@ -0,0 +1,96 @@
+package exporter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hamburghammer/grcon/exporter"
+)
+
+func TestBuiltins(t *testing.T) {
+	tests := []struct {
+		parser   string
+		response string
+		expect   map[string]float64
+	}{
+		{
+			parser:   "minecraft_list",
+			response: "There are 2 of a max of 20 players online: alice, bob\n",
+			expect:   map[string]float64{"players_online": 2, "players_max": 20},
+		},
+		{
+			parser:   "source_status",
+			response: "hostname: test\nplayers : 5 humans, 2 bots (24 max)\n",
+			expect:   map[string]float64{"players_online": 5, "bots_online": 2, "players_max": 24},
+		},
+		{
+			parser: "source_stats",
+			response: "CPU    In (KB/s)  Out (KB/s)  Uptime  Map changes  FPS      Players  Connects\n" +
+				"12.50  1.25       3.50        2       1            66.5     5        17\n",
+			expect: map[string]float64{
+				"cpu_percent": 12.5, "net_in_kilobytes_per_second": 1.25, "net_out_kilobytes_per_second": 3.5,
+				"uptime_seconds": 120, "map_changes": 1, "fps": 66.5, "players_online": 5, "connects": 17,
+			},
+		},
+		{
+			parser:   "factorio",
+			response: `{"players_online":3,"players_total":12,"tick":216000}`,
+			expect:   map[string]float64{"players_online": 3, "players_total": 12, "tick": 216000},
+		},
+		{
+			parser:   "json",
+			response: `{"tps": 19.5, "paused": true, "motd": "hello"}`,
+			expect:   map[string]float64{"tps": 19.5, "paused": 1},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.parser, func(t *testing.T) {
+			got, err := exporter.Builtins[test.parser].Parse(test.response)
+			if err != nil {
+				t.Error(err)
+				t.FailNow()
+			}
+			if !reflect.DeepEqual(got, test.expect) {
+				t.Errorf("gauges did not match:\nexpected: %v\ngot: %v\n", test.expect, got)
+			}
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		if _, err := exporter.Builtins["minecraft_list"].Parse("Unknown or incomplete command"); err == nil {
+			t.Error("expected an error for the unknown format")
+		}
+	})
+}
+
+func TestRegexParser(t *testing.T) {
+	t.Run("named groups", func(t *testing.T) {
+		parse, err := exporter.RegexParser(`Mean tick time: (?P<tick_ms>[0-9.]+) ms\. Mean TPS: (?P<tps>[0-9.]+) \(up (?P<uptime_seconds>\w+)\)`)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		got, err := parse("Overall: Mean tick time: 12.5 ms. Mean TPS: 20.000 (up 1h2m)")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		expected := map[string]float64{"tick_ms": 12.5, "tps": 20, "uptime_seconds": 3720}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("gauges did not match:\nexpected: %v\ngot: %v\n", expected, got)
+		}
+
+		if _, err := parse("Unknown command"); err == nil {
+			t.Error("expected an error if the regex does not match")
+		}
+	})
+
+	t.Run("without named group", func(t *testing.T) {
+		if _, err := exporter.RegexParser(`TPS: ([0-9.]+)`); err == nil {
+			t.Error("expected an error for the regex without named group")
+		}
+	})
+}
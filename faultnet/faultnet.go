@@ -0,0 +1,299 @@
+/*
+Package faultnet wraps connections to inject network faults in tests.
+
+A Conn splits reads and writes at random byte boundaries, delays them with
+latency and jitter, throttles the bandwidth and injects resets, half-closes
+and timeouts after a number of bytes, e.g. in the middle of a packet:
+
+	conn, _ := net.Dial("tcp", addr)
+	faulty := faultnet.Wrap(conn, faultnet.Config{
+		MaxChunk: 3,
+		Latency:  time.Millisecond,
+		Faults:   []faultnet.Fault{{Direction: faultnet.Read, After: 20, Kind: faultnet.Reset}},
+	})
+	c := client.NewSimpleClient(grcon.NewRemoteConsole(faulty), util.GenerateRequestId)
+
+The randomness is seeded by the Config, so failing runs can be reproduced.
+*/
+package faultnet
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Direction of the data a fault applies to.
+type Direction int
+
+const (
+	// Read is the data read from the connection.
+	Read Direction = iota
+	// Write is the data written to the connection.
+	Write
+)
+
+// Kind of an injected fault.
+type Kind int
+
+const (
+	// Reset closes the connection like a TCP reset. All following reads and writes fail with ErrReset.
+	Reset Kind = iota
+	// HalfClose closes one direction: reads return io.EOF like the peer closed its side,
+	// writes fail with ErrHalfClosed and the peer reads EOF.
+	HalfClose
+	// Timeout fails a single read or write with os.ErrDeadlineExceeded like an expired deadline.
+	Timeout
+)
+
+var (
+	// ErrReset is returned after an injected Reset.
+	ErrReset = errors.New("faultnet: connection reset")
+	// ErrHalfClosed is returned for writes after an injected HalfClose of the write direction.
+	ErrHalfClosed = errors.New("faultnet: write after half-close")
+)
+
+// Fault is injected after the number of bytes were read or written in the direction.
+type Fault struct {
+	Direction Direction
+	// After is the number of bytes that get through before the fault.
+	After int64
+	Kind  Kind
+}
+
+// Config of the injected faults. The zero value passes everything through unchanged.
+type Config struct {
+	// MaxChunk splits every read and write into chunks of 1 to MaxChunk bytes.
+	// Zero disables the splitting.
+	MaxChunk int
+	// Latency delays every chunk.
+	Latency time.Duration
+	// Jitter adds a random delay between zero and Jitter to every chunk.
+	Jitter time.Duration
+	// Bandwidth limits the throughput of each direction in bytes per second. Zero is unlimited.
+	Bandwidth int
+	// Faults to inject. Each fault is injected once.
+	Faults []Fault
+	// Seed of the random chunk sizes and jitter.
+	Seed int64
+}
+
+// Wrap returns a Conn that injects the faults of the config into the connection.
+func Wrap(conn net.Conn, config Config) *Conn {
+	return &Conn{
+		Conn:   conn,
+		config: config,
+		random: rand.New(rand.NewSource(config.Seed)),
+		faults: append([]Fault{}, config.Faults...),
+	}
+}
+
+// Conn is a net.Conn that injects the faults of its Config.
+type Conn struct {
+	net.Conn
+
+	config Config
+
+	// readMutex and writeMutex serialize the reads and the writes like a real connection.
+	readMutex  sync.Mutex
+	writeMutex sync.Mutex
+
+	mutex     sync.Mutex
+	random    *rand.Rand
+	faults    []Fault
+	read      int64
+	written   int64
+	reset     bool
+	readEOF   bool
+	writeShut bool
+}
+
+// Read reads at most one chunk and fails at the byte position of a fault.
+func (c *Conn) Read(p []byte) (int, error) {
+	c.readMutex.Lock()
+	defer c.readMutex.Unlock()
+
+	n, err := c.before(Read, len(p))
+	if err != nil || n == 0 {
+		return 0, err
+	}
+
+	n, err = c.Conn.Read(p[:n])
+	c.mutex.Lock()
+	c.read += int64(n)
+	c.mutex.Unlock()
+	if err == nil {
+		c.throttle(n)
+	}
+	return n, err
+}
+
+// Write writes the data in chunks and fails at the byte position of a fault.
+// The peer receives the bytes before the fault.
+func (c *Conn) Write(p []byte) (int, error) {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	written := 0
+	for written < len(p) {
+		n, err := c.before(Write, len(p)-written)
+		if err != nil {
+			return written, err
+		}
+
+		n, err = c.Conn.Write(p[written : written+n])
+		written += n
+		c.mutex.Lock()
+		c.written += int64(n)
+		c.mutex.Unlock()
+		if err != nil {
+			return written, err
+		}
+		c.throttle(n)
+	}
+	return written, nil
+}
+
+// before delays the chunk and returns its size.
+// It returns the error of a fault that is reached.
+func (c *Conn) before(direction Direction, size int) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.reset {
+		return 0, ErrReset
+	}
+	if direction == Read && c.readEOF {
+		return 0, io.EOF
+	}
+	if direction == Write && c.writeShut {
+		return 0, ErrHalfClosed
+	}
+
+	done := c.read
+	if direction == Write {
+		done = c.written
+	}
+
+	for i, fault := range c.faults {
+		if fault.Direction != direction || fault.After > done {
+			continue
+		}
+		c.faults = append(c.faults[:i], c.faults[i+1:]...)
+		return 0, c.inject(fault)
+	}
+
+	if size == 0 {
+		return 0, nil
+	}
+	if c.config.MaxChunk > 0 && size > c.config.MaxChunk {
+		size = 1 + c.random.Intn(c.config.MaxChunk)
+	}
+	// stop at the next fault to inject it at the exact byte position.
+	for _, fault := range c.faults {
+		if fault.Direction == direction && fault.After-done < int64(size) {
+			size = int(fault.After - done)
+		}
+	}
+
+	delay := c.config.Latency
+	if c.config.Jitter > 0 {
+		delay += time.Duration(c.random.Int63n(int64(c.config.Jitter)))
+	}
+	if delay > 0 {
+		c.mutex.Unlock()
+		time.Sleep(delay)
+		c.mutex.Lock()
+	}
+
+	return size, nil
+}
+
+// inject applies the fault. The mutex has to be held.
+func (c *Conn) inject(fault Fault) error {
+	switch fault.Kind {
+	case Reset:
+		c.reset = true
+		if tcpConn, ok := c.Conn.(*net.TCPConn); ok {
+			// the peer gets a RST instead of a FIN.
+			tcpConn.SetLinger(0)
+		}
+		c.Conn.Close()
+		return ErrReset
+	case HalfClose:
+		if fault.Direction == Read {
+			c.readEOF = true
+			return io.EOF
+		}
+		c.writeShut = true
+		if closer, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+			closer.CloseWrite()
+		}
+		return ErrHalfClosed
+	default:
+		return os.ErrDeadlineExceeded
+	}
+}
+
+// throttle sleeps for the time the bytes take with the bandwidth.
+func (c *Conn) throttle(n int) {
+	if c.config.Bandwidth > 0 && n > 0 {
+		time.Sleep(time.Duration(n) * time.Second / time.Duration(c.config.Bandwidth))
+	}
+}
+
+// Stats returns the number of bytes that were read and written.
+func (c *Conn) Stats() (read, written int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.read, c.written
+}
+
+// Dialer wraps the connections of the dial function, e.g. a client.DialFunc.
+// Every connection gets a different seed derived from the one of the config.
+func Dialer(dial func() (net.Conn, error), config Config) func() (net.Conn, error) {
+	var mutex sync.Mutex
+	return func() (net.Conn, error) {
+		conn, err := dial()
+		if err != nil {
+			return nil, err
+		}
+
+		mutex.Lock()
+		connConfig := config
+		config.Seed++
+		mutex.Unlock()
+		return Wrap(conn, connConfig), nil
+	}
+}
+
+// Listen wraps the accepted connections of the listener to inject faults on the server side.
+// Every connection gets a different seed derived from the one of the config.
+func Listen(l net.Listener, config Config) net.Listener {
+	return &listener{Listener: l, config: config}
+}
+
+type listener struct {
+	net.Listener
+
+	mutex  sync.Mutex
+	config Config
+}
+
+// Accept wraps the accepted connection.
+func (l *listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	l.mutex.Lock()
+	config := l.config
+	l.config.Seed++
+	l.mutex.Unlock()
+	return Wrap(conn, config), nil
+}
@@ -0,0 +1,318 @@
+package faultnet_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/emulator"
+	"github.com/hamburghammer/grcon/faultnet"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// seeds is the number of seeds every robustness test runs with.
+const seeds = 20
+
+// connPair returns both ends of a loopback TCP connection.
+func connPair(t *testing.T) (net.Conn, net.Conn) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	conn.SetDeadline(deadline)
+	peer.SetDeadline(deadline)
+	t.Cleanup(func() {
+		conn.Close()
+		peer.Close()
+	})
+	return conn, peer
+}
+
+func TestConn_Split(t *testing.T) {
+	conn, peer := connPair(t)
+	data := bytes.Repeat([]byte("0123456789"), 100)
+
+	writer := faultnet.Wrap(conn, faultnet.Config{MaxChunk: 7, Seed: 1})
+	reader := faultnet.Wrap(peer, faultnet.Config{MaxChunk: 3, Seed: 2})
+	go writer.Write(data)
+
+	got := make([]byte, 0, len(data))
+	buf := make([]byte, 100)
+	for len(got) < len(data) {
+		n, err := reader.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > 3 {
+			t.Fatalf("expected reads of at most 3 bytes but got %d\n", n)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("data did not match")
+	}
+	if read, _ := reader.Stats(); read != int64(len(data)) {
+		t.Errorf("stats did not match:\nexpected: %d\ngot: %d\n", len(data), read)
+	}
+}
+
+func TestConn_Faults(t *testing.T) {
+	t.Run("reset mid write", func(t *testing.T) {
+		conn, peer := connPair(t)
+		faulty := faultnet.Wrap(conn, faultnet.Config{Faults: []faultnet.Fault{{Direction: faultnet.Write, After: 5, Kind: faultnet.Reset}}})
+
+		n, err := faulty.Write([]byte("0123456789"))
+		if n != 5 || err != faultnet.ErrReset {
+			t.Errorf("write did not match:\nexpected: 5 %v\ngot: %d %v\n", faultnet.ErrReset, n, err)
+		}
+		got, _ := io.ReadAll(peer)
+		if string(got) != "01234" {
+			t.Errorf("peer data did not match:\nexpected: 01234\ngot: %s\n", got)
+		}
+		if _, err := faulty.Read(make([]byte, 1)); err != faultnet.ErrReset {
+			t.Errorf("expected ErrReset for the following read but got %v\n", err)
+		}
+	})
+
+	t.Run("half-close of the write direction", func(t *testing.T) {
+		conn, peer := connPair(t)
+		faulty := faultnet.Wrap(conn, faultnet.Config{Faults: []faultnet.Fault{{Direction: faultnet.Write, After: 3, Kind: faultnet.HalfClose}}})
+
+		if _, err := faulty.Write([]byte("abcdef")); err != faultnet.ErrHalfClosed {
+			t.Errorf("expected ErrHalfClosed but got %v\n", err)
+		}
+		if got, err := io.ReadAll(peer); string(got) != "abc" || err != nil {
+			t.Errorf("peer did not read EOF after abc: %q %v\n", got, err)
+		}
+		// the read direction still works.
+		peer.Write([]byte("x"))
+		if n, err := faulty.Read(make([]byte, 1)); n != 1 || err != nil {
+			t.Errorf("read after the half-close failed: %d %v\n", n, err)
+		}
+	})
+
+	t.Run("half-close of the read direction", func(t *testing.T) {
+		conn, peer := connPair(t)
+		faulty := faultnet.Wrap(conn, faultnet.Config{Faults: []faultnet.Fault{{Direction: faultnet.Read, After: 2, Kind: faultnet.HalfClose}}})
+		peer.Write([]byte("abcdef"))
+
+		got, err := io.ReadAll(faulty)
+		if string(got) != "ab" || err != nil {
+			t.Errorf("read did not stop after ab: %q %v\n", got, err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		conn, peer := connPair(t)
+		faulty := faultnet.Wrap(conn, faultnet.Config{Faults: []faultnet.Fault{{Direction: faultnet.Read, After: 4, Kind: faultnet.Timeout}}})
+		peer.Write([]byte("abcdef"))
+
+		got, err := io.ReadAll(faulty)
+		var netErr net.Error
+		if string(got) != "abcd" || !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Errorf("read did not time out after abcd: %q %v\n", got, err)
+		}
+		// a timeout is injected once.
+		if n, err := faulty.Read(make([]byte, 10)); n != 2 || err != nil {
+			t.Errorf("read after the timeout failed: %d %v\n", n, err)
+		}
+	})
+
+	t.Run("latency and bandwidth", func(t *testing.T) {
+		conn, peer := connPair(t)
+		faulty := faultnet.Wrap(conn, faultnet.Config{Latency: 20 * time.Millisecond, Bandwidth: 1000})
+		go io.Copy(io.Discard, peer)
+
+		start := time.Now()
+		faulty.Write(make([]byte, 100))
+		// 20ms latency and 100ms for 100 bytes with 1000 bytes per second.
+		if elapsed := time.Since(start); elapsed < 120*time.Millisecond {
+			t.Errorf("write took %s instead of at least 120ms\n", elapsed)
+		}
+	})
+}
+
+func TestRemoteConsole(t *testing.T) {
+	packets := []grcon.Packet{
+		{Id: 1, Type: grcon.SERVERDATA_AUTH, Body: []byte("secret")},
+		{Id: 2, Type: grcon.SERVERDATA_EXECCOMMAND, Body: []byte{}},
+		{Id: 3, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: bytes.Repeat([]byte("a"), int(grcon.MaxBody))},
+		{Id: 4, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("status")},
+	}
+
+	for seed := int64(0); seed < seeds; seed++ {
+		seed := seed
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			conn, peer := connPair(t)
+			config := chunkConfig(seed)
+			writer := grcon.NewRemoteConsole(faultnet.Wrap(conn, config))
+			// the reader gets larger chunks to also read multiple packets at once.
+			config.MaxChunk *= 1000
+			reader := grcon.NewRemoteConsole(faultnet.Wrap(peer, config))
+
+			go func() {
+				for _, packet := range packets {
+					writer.Write(packet)
+				}
+			}()
+
+			for _, expect := range packets {
+				got, err := reader.Read()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Id != expect.Id || got.Type != expect.Type || !bytes.Equal(got.Body, expect.Body) {
+					t.Fatalf("packet did not match:\nexpected: %d %d %d bytes\ngot: %d %d %d bytes\n",
+						expect.Id, expect.Type, len(expect.Body), got.Id, got.Type, len(got.Body))
+				}
+			}
+		})
+	}
+}
+
+// serve serves the emulator on a listener that splits the writes of the server.
+func serve(t *testing.T, serveFunc func(l net.Listener) error, closeFunc func() error, config faultnet.Config) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go serveFunc(faultnet.Listen(l, config))
+	t.Cleanup(func() { closeFunc() })
+	return l.Addr().String()
+}
+
+func dial(t *testing.T, addr string, config faultnet.Config) *grcon.RemoteConsole {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	t.Cleanup(func() { conn.Close() })
+	return grcon.NewRemoteConsole(faultnet.Wrap(conn, config))
+}
+
+// chunkConfig splits reads and writes into chunks of up to 1 to 7 bytes depending on the seed.
+func chunkConfig(seed int64) faultnet.Config {
+	return faultnet.Config{MaxChunk: 1 + int(seed%7), Seed: seed}
+}
+
+// slowConfig adds latency and jitter to larger chunks.
+func slowConfig(seed int64) faultnet.Config {
+	return faultnet.Config{MaxChunk: 512, Latency: time.Millisecond, Jitter: 2 * time.Millisecond, Seed: seed}
+}
+
+func TestSimpleClient(t *testing.T) {
+	for seed := int64(0); seed < seeds; seed++ {
+		seed := seed
+		config := chunkConfig
+		if seed%4 == 3 {
+			config = slowConfig
+		}
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			src := emulator.NewSource("secret")
+			// the response is split into two packets.
+			src.HandleFunc("dump", func(args string) string { return strings.Repeat("0123456789", 500) })
+			addr := serve(t, src.Serve, src.Close, config(seed+1000))
+
+			c := client.NewSimpleClient(dial(t, addr, config(seed)), util.GenerateRequestId)
+			if err := c.Auth("secret"); err != nil {
+				t.Fatal(err)
+			}
+			for _, cmd := range []string{"dump", "echo hello", "dump"} {
+				got, err := c.Exec(cmd)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expect := src.Exec(cmd)
+				if string(got) != expect {
+					t.Fatalf("response of %s did not match:\nexpected: %d bytes\ngot: %d bytes\n", cmd, len(expect), len(got))
+				}
+			}
+		})
+	}
+}
+
+func TestMinecraftClient(t *testing.T) {
+	for seed := int64(0); seed < seeds; seed++ {
+		seed := seed
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			mc := emulator.NewMinecraft("secret")
+			mc.Join("Steve")
+			addr := serve(t, mc.Serve, mc.Close, chunkConfig(seed+1000))
+
+			c := client.NewMinecraftClient(dial(t, addr, chunkConfig(seed)), util.GenerateRequestId)
+			if err := c.Auth("secret"); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.WhitelistAdd("Steve"); err != nil {
+				t.Fatal(err)
+			}
+			list, err := c.ListPlayers()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list.Players) != 1 || list.Players[0].Name != "Steve" {
+				t.Fatalf("list did not match: %+v\n", list)
+			}
+		})
+	}
+}
+
+// TestFaults checks that faults in the middle of a packet end in an error instead of a hang or a wrong response.
+func TestFaults(t *testing.T) {
+	kinds := map[string]faultnet.Kind{"reset": faultnet.Reset, "half-close": faultnet.HalfClose, "timeout": faultnet.Timeout}
+	// 2 is inside the size field, 10 inside the header and 20 inside the body of the response.
+	positions := []int64{2, 10, 20}
+
+	for name, kind := range kinds {
+		for _, direction := range []faultnet.Direction{faultnet.Read, faultnet.Write} {
+			for _, after := range positions {
+				name, kind, direction, after := name, kind, direction, after
+				t.Run(fmt.Sprintf("%s of direction %d after %d bytes", name, direction, after), func(t *testing.T) {
+					src := emulator.NewSource("secret")
+					addr := serve(t, src.Serve, src.Close, faultnet.Config{})
+					remoteConsole := dial(t, addr, faultnet.Config{})
+					c := client.NewSimpleClient(remoteConsole, util.GenerateRequestId)
+					if err := c.Auth("secret"); err != nil {
+						t.Fatal(err)
+					}
+
+					// wrap the connection again to count the bytes of the fault from the command on.
+					conn := remoteConsole.Conn.(*faultnet.Conn).Conn
+					remoteConsole.Conn = faultnet.Wrap(conn, faultnet.Config{
+						MaxChunk: 4,
+						Faults:   []faultnet.Fault{{Direction: direction, After: after, Kind: kind}},
+					})
+
+					got, err := c.Exec("echo this response is longer than twenty bytes")
+					if err == nil {
+						t.Fatalf("expected an error but got the response %q\n", got)
+					}
+					var netErr net.Error
+					if kind == faultnet.Timeout && !(errors.As(err, &netErr) && netErr.Timeout()) && !errors.Is(err, os.ErrDeadlineExceeded) {
+						t.Errorf("expected a timeout but got %v\n", err)
+					}
+				})
+			}
+		}
+	}
+}
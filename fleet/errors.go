@@ -0,0 +1,26 @@
+package fleet
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeoutError occurs if a server did not respond within the timeout.
+// The command might still get executed by the server.
+type TimeoutError struct {
+	Server  string
+	Timeout time.Duration
+}
+
+func (te TimeoutError) Error() string {
+	return fmt.Sprintf("grcon-fleet: server '%s' did not respond within %s", te.Server, te.Timeout)
+}
+
+// UnknownServerError occurs if a selected server has no client.
+type UnknownServerError struct {
+	Server string
+}
+
+func (use UnknownServerError) Error() string {
+	return fmt.Sprintf("grcon-fleet: unknown server '%s'", use.Server)
+}
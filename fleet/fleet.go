@@ -0,0 +1,211 @@
+/*
+Package fleet executes commands concurrently on many servers.
+
+Failures are isolated per server: a slow or broken server does not stop the
+execution on the others and shows up as error in its Result.
+
+	f := fleet.New(clients)
+	results := f.Exec(ctx, f.Names(), "say maintenance in 10 minutes")
+	for _, group := range results.Group() {
+		fmt.Println(group.Servers, group.Response, group.Err)
+	}
+*/
+package fleet
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+)
+
+// Defaults of the Fleet.
+const (
+	DefaultConcurrency = 16
+	DefaultTimeout     = 10 * time.Second
+)
+
+// New is a constructor for the Fleet struct.
+func New(clients map[string]client.Client) *Fleet {
+	return &Fleet{Clients: clients, Concurrency: DefaultConcurrency, Timeout: DefaultTimeout}
+}
+
+// Fleet is a set of named clients.
+type Fleet struct {
+	// Clients maps the server names to the clients.
+	// The clients have to be safe for concurrent use like the client.ReconnectingClient.
+	Clients map[string]client.Client
+	// Concurrency limits the number of servers that execute a command at the same time.
+	// Zero or less means no limit.
+	Concurrency int
+	// Timeout for a command on a single server. Zero or less means no timeout.
+	// A timed out Exec of the client keeps running in the background and holds its
+	// place of the Concurrency until it ends, use a client with own timeouts to end it.
+	Timeout time.Duration
+}
+
+// Result of a command on a single server.
+type Result struct {
+	Server   string
+	Response string
+	Err      error
+	Duration time.Duration
+}
+
+// Results maps the server names to their results.
+type Results map[string]Result
+
+// Names returns the sorted names of all servers.
+func (f *Fleet) Names() []string {
+	names := make([]string, 0, len(f.Clients))
+	for name := range f.Clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Exec executes the command on the servers with the names and waits for all results.
+// Servers that did not start when the context gets canceled get the error of the context.
+func (f *Fleet) Exec(ctx context.Context, names []string, cmd string) Results {
+	results := make(Results, len(names))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	var slots chan struct{}
+	if f.Concurrency > 0 {
+		slots = make(chan struct{}, f.Concurrency)
+	}
+
+	for _, name := range names {
+		name := name
+
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				mutex.Lock()
+				results[name] = Result{Server: name, Err: ctx.Err()}
+				mutex.Unlock()
+				continue
+			}
+		}
+
+		release := func() {}
+		if slots != nil {
+			release = func() { <-slots }
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := f.execOne(ctx, name, cmd, release)
+
+			mutex.Lock()
+			results[name] = result
+			mutex.Unlock()
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// execOne executes the command on a single server and enforces the timeout.
+// release is called when the Exec of the client ended, which can be after the timeout.
+func (f *Fleet) execOne(ctx context.Context, name, cmd string, release func()) Result {
+	c, ok := f.Clients[name]
+	if !ok {
+		release()
+		return Result{Server: name, Err: UnknownServerError{Server: name}}
+	}
+
+	start := time.Now()
+	done := make(chan Result, 1)
+	go func() {
+		defer release()
+		response, err := c.Exec(cmd)
+		done <- Result{Server: name, Response: string(response), Err: err}
+	}()
+
+	var timeout <-chan time.Time
+	if f.Timeout > 0 {
+		timer := time.NewTimer(f.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var result Result
+	select {
+	case result = <-done:
+	case <-timeout:
+		result = Result{Server: name, Err: TimeoutError{Server: name, Timeout: f.Timeout}}
+	case <-ctx.Done():
+		result = Result{Server: name, Err: ctx.Err()}
+	}
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+// Failed returns the sorted names of the servers with errors.
+func (r Results) Failed() []string {
+	failed := []string{}
+	for name, result := range r {
+		if result.Err != nil {
+			failed = append(failed, name)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// Group of servers with the same output.
+type Group struct {
+	// Servers are sorted by name.
+	Servers  []string
+	Response string
+	Err      error
+}
+
+// Group groups the servers with identical responses or errors.
+// Leading and trailing whitespace of the responses is ignored.
+// The groups are sorted by size and then by the first server name.
+func (r Results) Group() []Group {
+	groups := []Group{}
+	index := map[string]int{}
+
+	for _, name := range sortedNames(r) {
+		result := r[name]
+		key := "response:" + strings.TrimSpace(result.Response)
+		if result.Err != nil {
+			key = "error:" + result.Err.Error()
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, Group{Response: result.Response, Err: result.Err})
+		}
+		groups[i].Servers = append(groups[i].Servers, name)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].Servers) > len(groups[j].Servers)
+	})
+
+	return groups
+}
+
+func sortedNames(r Results) []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,171 @@
+package fleet_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/fleet"
+)
+
+// MockClient responds after the Delay with the Response or the Err.
+// It tracks the number of concurrent executions in the Counter.
+type MockClient struct {
+	Response string
+	Err      error
+	Delay    time.Duration
+	Counter  *ConcurrencyCounter
+}
+
+func (mc MockClient) Auth(password string) error {
+	return nil
+}
+
+func (mc MockClient) Exec(cmd string) ([]byte, error) {
+	if mc.Counter != nil {
+		mc.Counter.Enter()
+		defer mc.Counter.Leave()
+	}
+	time.Sleep(mc.Delay)
+	if mc.Err != nil {
+		return []byte{}, mc.Err
+	}
+	return []byte(mc.Response), nil
+}
+
+// ConcurrencyCounter tracks the maximal number of concurrent calls.
+type ConcurrencyCounter struct {
+	mutex   sync.Mutex
+	current int
+	Max     int
+}
+
+func (cc *ConcurrencyCounter) Enter() {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	cc.current++
+	if cc.current > cc.Max {
+		cc.Max = cc.current
+	}
+}
+
+func (cc *ConcurrencyCounter) Leave() {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	cc.current--
+}
+
+func TestFleet_Exec(t *testing.T) {
+	t.Run("isolated failures", func(t *testing.T) {
+		f := fleet.New(map[string]client.Client{
+			"eu-1": MockClient{Response: "ok"},
+			"eu-2": MockClient{Err: errors.New("connection refused")},
+			"us-1": MockClient{Response: "ok"},
+			"us-2": MockClient{Response: "ok", Delay: time.Second},
+		})
+		f.Timeout = 50 * time.Millisecond
+
+		results := f.Exec(context.Background(), append(f.Names(), "missing"), "save-all")
+
+		if len(results) != 5 {
+			t.Fatalf("number of results did not match:\nexpected: %d\ngot: %d\n", 5, len(results))
+		}
+		if results["eu-1"].Response != "ok" || results["us-1"].Response != "ok" {
+			t.Errorf("responses did not match: %+v\n", results)
+		}
+		if _, ok := results["us-2"].Err.(fleet.TimeoutError); !ok {
+			t.Errorf("error did not match:\nexpected: %T\ngot: %v\n", fleet.TimeoutError{}, results["us-2"].Err)
+		}
+		if _, ok := results["missing"].Err.(fleet.UnknownServerError); !ok {
+			t.Errorf("error did not match:\nexpected: %T\ngot: %v\n", fleet.UnknownServerError{}, results["missing"].Err)
+		}
+
+		failed := results.Failed()
+		expect := []string{"eu-2", "missing", "us-2"}
+		if len(failed) != len(expect) {
+			t.Fatalf("failed servers did not match:\nexpected: %v\ngot: %v\n", expect, failed)
+		}
+		for i := range expect {
+			if failed[i] != expect[i] {
+				t.Errorf("failed servers did not match:\nexpected: %v\ngot: %v\n", expect, failed)
+			}
+		}
+	})
+
+	t.Run("concurrency limit", func(t *testing.T) {
+		counter := &ConcurrencyCounter{}
+		clients := map[string]client.Client{}
+		for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+			clients[name] = MockClient{Response: "ok", Delay: 20 * time.Millisecond, Counter: counter}
+		}
+		f := fleet.New(clients)
+		f.Concurrency = 2
+
+		results := f.Exec(context.Background(), f.Names(), "list")
+
+		if len(results.Failed()) != 0 {
+			t.Errorf("unexpected failures: %v\n", results.Failed())
+		}
+		if counter.Max > 2 {
+			t.Errorf("concurrency limit exceeded:\nexpected: <= %d\ngot: %d\n", 2, counter.Max)
+		}
+	})
+
+	t.Run("timed out servers keep their slot", func(t *testing.T) {
+		counter := &ConcurrencyCounter{}
+		f := fleet.New(map[string]client.Client{
+			"a": MockClient{Response: "ok", Delay: 200 * time.Millisecond, Counter: counter},
+			"b": MockClient{Response: "ok", Counter: counter},
+		})
+		f.Concurrency = 1
+		f.Timeout = 50 * time.Millisecond
+
+		results := f.Exec(context.Background(), f.Names(), "list")
+
+		if _, ok := results["a"].Err.(fleet.TimeoutError); !ok {
+			t.Errorf("error did not match:\nexpected: %T\ngot: %v\n", fleet.TimeoutError{}, results["a"].Err)
+		}
+		if results["b"].Response != "ok" {
+			t.Errorf("response did not match:\nexpected: ok\ngot: %+v\n", results["b"])
+		}
+		if counter.Max > 1 {
+			t.Errorf("concurrency limit exceeded:\nexpected: <= %d\ngot: %d\n", 1, counter.Max)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		f := fleet.New(map[string]client.Client{"a": MockClient{Delay: time.Second}})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results := f.Exec(ctx, f.Names(), "list")
+		if results["a"].Err != context.Canceled {
+			t.Errorf("error did not match:\nexpected: %v\ngot: %v\n", context.Canceled, results["a"].Err)
+		}
+	})
+}
+
+func TestResults_Group(t *testing.T) {
+	results := fleet.Results{
+		"a": {Server: "a", Response: "Saved the game\n"},
+		"b": {Server: "b", Err: errors.New("timeout")},
+		"c": {Server: "c", Response: "Saved the game"},
+		"d": {Server: "d", Response: "Saved the game"},
+		"e": {Server: "e", Err: errors.New("timeout")},
+	}
+
+	groups := results.Group()
+
+	if len(groups) != 2 {
+		t.Fatalf("number of groups did not match:\nexpected: %d\ngot: %d\n", 2, len(groups))
+	}
+	if len(groups[0].Servers) != 3 || groups[0].Servers[0] != "a" || groups[0].Err != nil {
+		t.Errorf("first group did not match: %+v\n", groups[0])
+	}
+	if len(groups[1].Servers) != 2 || groups[1].Servers[0] != "b" || groups[1].Err == nil {
+		t.Errorf("second group did not match: %+v\n", groups[1])
+	}
+}
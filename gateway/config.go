@@ -0,0 +1,145 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/inventory"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// Config of the gateway binary.
+//
+//	{
+//		"listen": ":8080",
+//		"inventory": "inventory.json",
+//		"servers": {
+//			"survival": {"address": "127.0.0.1:25575", "password": "secret", "dialect": "minecraft", "timeout": "5s"}
+//		},
+//		"tokens": [
+//			{"name": "ci", "token": "...", "servers": ["survival"], "commands": ["list", "say *"]}
+//		]
+//	}
+//
+// The servers can be listed inline or taken from an inventory file (see the inventory package).
+type Config struct {
+	// Listen is the address of the HTTP server.
+	Listen string `json:"listen"`
+	// Inventory is the path of an inventory file. Relative paths are resolved
+	// against the directory of the config file.
+	Inventory string                  `json:"inventory"`
+	Servers   map[string]ServerConfig `json:"servers"`
+	Tokens    []Token                 `json:"tokens"`
+}
+
+// ServerConfig describes how to connect to a RCON server.
+type ServerConfig struct {
+	Address  string         `json:"address"`
+	Password string         `json:"password"`
+	Dialect  client.Dialect `json:"dialect"`
+	// Timeout for a single command, defaults to DefaultTimeout.
+	Timeout util.Duration `json:"timeout"`
+}
+
+// DefaultTimeout is used for servers without timeout.
+const DefaultTimeout = inventory.DefaultTimeout
+
+// LoadConfig reads and validates the JSON config file.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, fmt.Errorf("parsing config '%s': %w", path, err)
+	}
+	if config.Inventory != "" && !filepath.IsAbs(config.Inventory) {
+		config.Inventory = filepath.Join(filepath.Dir(path), config.Inventory)
+	}
+
+	return config, config.Validate()
+}
+
+// Validate checks that all required values are set.
+func (c Config) Validate() error {
+	for name, server := range c.Servers {
+		if server.Address == "" {
+			return fmt.Errorf("server '%s': address is missing", name)
+		}
+		if server.Dialect == "" {
+			return fmt.Errorf("server '%s': dialect is missing", name)
+		}
+	}
+
+	for i, token := range c.Tokens {
+		if token.Name == "" {
+			return fmt.Errorf("token %d: name is missing", i)
+		}
+		if token.Token == "" {
+			return fmt.Errorf("token '%s': token is missing", token.Name)
+		}
+	}
+
+	return nil
+}
+
+// LoadInventory returns the servers of the inventory file and the inline servers.
+// Inline servers replace servers of the inventory with the same name.
+func (c Config) LoadInventory() (*inventory.Inventory, error) {
+	inv := &inventory.Inventory{Servers: map[string]inventory.Server{}}
+	if c.Inventory != "" {
+		loaded, err := inventory.Load(c.Inventory)
+		if err != nil {
+			return nil, err
+		}
+		inv = loaded
+		if inv.Servers == nil {
+			inv.Servers = map[string]inventory.Server{}
+		}
+	}
+
+	for name, server := range c.Servers {
+		inv.Servers[name] = inventory.Server{
+			Address:  server.Address,
+			Dialect:  server.Dialect,
+			Password: inventory.PasswordSource{Value: server.Password},
+			Timeout:  server.Timeout,
+		}
+	}
+
+	return inv, nil
+}
+
+// NewClients creates a ReconnectingClient for every server.
+// The connections get established with the first command.
+func (c Config) NewClients() (map[string]client.Client, error) {
+	inv, err := c.LoadInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	return inv.Clients(inv.Names())
+}
+
+// NewConsoles creates the ConsoleServer for every server.
+func (c Config) NewConsoles() (map[string]ConsoleServer, error) {
+	inv, err := c.LoadInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	consoles := make(map[string]ConsoleServer, len(inv.Servers))
+	for _, name := range inv.Names() {
+		server, err := inv.Client(name)
+		if err != nil {
+			return nil, err
+		}
+		consoles[name] = ConsoleServer{Dial: server.Dial, Dialect: server.Dialect, Password: server.Password}
+	}
+	return consoles, nil
+}
@@ -0,0 +1,88 @@
+package gateway_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/gateway"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("valid config", func(t *testing.T) {
+		path := writeFile(t, `{
+			"listen": ":9000",
+			"servers": {"survival": {"address": "127.0.0.1:25575", "password": "secret", "dialect": "minecraft", "timeout": "3s"}},
+			"tokens": [{"name": "ci", "token": "abc", "servers": ["survival"], "commands": ["list"]}]
+		}`)
+
+		got, err := gateway.LoadConfig(path)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		server := got.Servers["survival"]
+		if server.Dialect != client.DialectMinecraft || time.Duration(server.Timeout) != 3*time.Second {
+			t.Errorf("server did not match: %+v\n", server)
+		}
+		clients, err := got.NewClients()
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if _, ok := clients["survival"].(*client.ReconnectingClient); !ok {
+			t.Errorf("expected: *ReconnectingClient\ngot: %T\n", clients["survival"])
+		}
+	})
+
+	t.Run("inventory", func(t *testing.T) {
+		dir := t.TempDir()
+		inventoryPath := filepath.Join(dir, "inventory.json")
+		err := os.WriteFile(inventoryPath, []byte(`{"servers": {"cs2": {"address": "127.0.0.1:27015", "dialect": "source", "password": "secret"}}}`), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "config.json")
+		err = os.WriteFile(path, []byte(`{
+			"inventory": "inventory.json",
+			"servers": {"survival": {"address": "127.0.0.1:25575", "password": "secret", "dialect": "minecraft"}}
+		}`), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		config, err := gateway.LoadConfig(path)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		consoles, err := config.NewConsoles()
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if consoles["cs2"].Dialect != client.DialectSource || consoles["survival"].Dialect != client.DialectMinecraft {
+			t.Errorf("consoles did not match: %+v\n", consoles)
+		}
+	})
+
+	t.Run("missing address", func(t *testing.T) {
+		path := writeFile(t, `{"servers": {"survival": {"dialect": "minecraft"}}}`)
+
+		_, err := gateway.LoadConfig(path)
+		if err == nil {
+			t.Error("expected an error for the missing address")
+		}
+	})
+}
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,281 @@
+package gateway
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+)
+
+//go:embed console.html
+var consoleHTML []byte
+
+// consoleAuthTimeout limits the time to connect and authenticate a console session.
+const consoleAuthTimeout = 10 * time.Second
+
+// ConsoleServer describes how to open a dedicated connection for a console session.
+type ConsoleServer struct {
+	Dial     client.DialFunc
+	Dialect  client.Dialect
+	Password string
+}
+
+// Message types of the console WebSocket.
+const (
+	// MessageResponse is (a part of) the response to a command of the session.
+	MessageResponse = "response"
+	// MessageUnsolicited is a packet the server sent without a matching command.
+	MessageUnsolicited = "unsolicited"
+	// MessageInfo informs about the state of the session.
+	MessageInfo = "info"
+	// MessageError reports a failed command or a broken session.
+	MessageError = "error"
+)
+
+// ConsoleCommand is a message sent by the browser.
+type ConsoleCommand struct {
+	Command string `json:"command"`
+}
+
+// ConsoleMessage is a message sent to the browser.
+type ConsoleMessage struct {
+	Type string `json:"type"`
+	// User is the name of the token that opened the session.
+	User    string    `json:"user"`
+	Server  string    `json:"server"`
+	Command string    `json:"command,omitempty"`
+	Body    string    `json:"body"`
+	Time    time.Time `json:"time"`
+}
+
+// serveConsolePage serves the embedded HTML console.
+func serveConsolePage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(consoleHTML)
+}
+
+// serveConsole upgrades the request to a WebSocket and bridges it to a new RCON session.
+// Browsers can not set the Authorization header for WebSockets, so the token can also be
+// passed with the "token" query parameter.
+func (g *Gateway) serveConsole(w http.ResponseWriter, r *http.Request, name string, entry *logEntry) {
+	token, ok := authenticate(g.Tokens, r)
+	if !ok {
+		token, ok = lookupToken(g.Tokens, r.URL.Query().Get("token"))
+	}
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid or missing token")
+		return
+	}
+	entry.token = token.Name
+
+	server, ok := g.Consoles[name]
+	if !ok || !token.AllowsServer(name) {
+		writeError(w, http.StatusNotFound, "unknown server")
+		return
+	}
+
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer ws.Close(closeNormal, "")
+
+	session := &consoleSession{
+		gateway:    g,
+		ws:         ws,
+		token:      token,
+		server:     name,
+		pending:    map[grcon.PacketId]string{},
+		delimiters: map[grcon.PacketId]grcon.PacketId{},
+	}
+	session.run(server)
+}
+
+// consoleSession bridges a WebSocket to a dedicated RCON connection.
+type consoleSession struct {
+	gateway *Gateway
+	ws      *wsConn
+	token   Token
+	server  string
+
+	remoteConsole *grcon.RemoteConsole
+
+	mutex   sync.Mutex
+	nextId  grcon.PacketId
+	pending map[grcon.PacketId]string
+	// delimiters maps the ids of the empty packets sent after commands to the ids of the commands.
+	delimiters map[grcon.PacketId]grcon.PacketId
+}
+
+func (s *consoleSession) run(server ConsoleServer) {
+	conn, err := s.connect(server)
+	if err != nil {
+		s.send(ConsoleMessage{Type: MessageError, Body: "connecting failed: " + err.Error()})
+		return
+	}
+	defer conn.Close()
+
+	s.audit("opened console session")
+	defer s.audit("closed console session")
+	s.send(ConsoleMessage{Type: MessageInfo, Body: "connected to " + s.server})
+
+	go s.readPackets(server.Dialect)
+
+	for {
+		data, err := s.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var cmd ConsoleCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			s.send(ConsoleMessage{Type: MessageError, Body: "invalid JSON message: " + err.Error()})
+			continue
+		}
+		if strings.TrimSpace(cmd.Command) == "" {
+			continue
+		}
+		if !s.token.AllowsCommand(cmd.Command) {
+			s.audit(fmt.Sprintf("denied command=%q", cmd.Command))
+			s.send(ConsoleMessage{Type: MessageError, Command: cmd.Command, Body: "command not allowed"})
+			continue
+		}
+
+		s.audit(fmt.Sprintf("command=%q", cmd.Command))
+		if err := s.writeCommand(cmd.Command, server.Dialect); err != nil {
+			s.send(ConsoleMessage{Type: MessageError, Command: cmd.Command, Body: "writing command failed: " + err.Error()})
+			return
+		}
+	}
+}
+
+// connect opens and authenticates the RCON connection with the dialect client.
+func (s *consoleSession) connect(server ConsoleServer) (net.Conn, error) {
+	conn, err := server.Dial()
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Now().Add(consoleAuthTimeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	s.remoteConsole = grcon.NewRemoteConsoleSize(conn, server.Dialect.MaxPacket())
+	c, err := client.NewDialectClient(server.Dialect, s.remoteConsole, s.generateId)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := c.Auth(server.Password); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// generateId returns unique ids for the session starting at 1.
+func (s *consoleSession) generateId() grcon.PacketId {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.nextId++
+	return s.nextId
+}
+
+// writeCommand writes the command and for dialects that mirror empty packets a delimiter.
+func (s *consoleSession) writeCommand(cmd string, dialect client.Dialect) error {
+	id := s.generateId()
+	s.mutex.Lock()
+	s.pending[id] = cmd
+	s.mutex.Unlock()
+
+	err := s.remoteConsole.Write(grcon.Packet{Id: id, Type: grcon.SERVERDATA_EXECCOMMAND, Body: []byte(cmd)})
+	if err != nil {
+		return err
+	}
+
+	if !dialect.MirrorsResponseValue() {
+		return nil
+	}
+
+	delimiterId := s.generateId()
+	s.mutex.Lock()
+	s.delimiters[delimiterId] = id
+	s.mutex.Unlock()
+
+	return s.remoteConsole.Write(grcon.Packet{Id: delimiterId, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte{}})
+}
+
+// readPackets forwards all packets of the RCON connection to the WebSocket until the connection breaks.
+func (s *consoleSession) readPackets(dialect client.Dialect) {
+	defer s.ws.Close(closeNormal, "rcon connection closed")
+
+	for {
+		packet, err := s.remoteConsole.Read()
+		if err != nil {
+			s.send(ConsoleMessage{Type: MessageError, Body: "rcon connection closed: " + err.Error()})
+			return
+		}
+
+		s.mutex.Lock()
+		cmd, isResponse := s.pending[packet.Id]
+		cmdId, isDelimiter := s.delimiters[packet.Id]
+		if isDelimiter {
+			// the mirrored delimiter ends the response. Source servers send an additional
+			// packet with the id of the delimiter, which is why the delimiter is kept.
+			delete(s.pending, cmdId)
+		} else if isResponse && !dialect.MirrorsResponseValue() {
+			// all other dialects answer with a single packet.
+			delete(s.pending, packet.Id)
+		}
+		s.mutex.Unlock()
+
+		switch {
+		case isDelimiter:
+			continue
+		case isResponse:
+			s.send(ConsoleMessage{Type: MessageResponse, Command: cmd, Body: string(packet.Body)})
+		default:
+			s.send(ConsoleMessage{Type: MessageUnsolicited, Body: string(packet.Body)})
+		}
+	}
+}
+
+// send tags the message with the user, server and time and writes it to the WebSocket.
+func (s *consoleSession) send(msg ConsoleMessage) {
+	msg.User = s.token.Name
+	msg.Server = s.server
+	msg.Time = time.Now().UTC()
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	// a failed write closes the WebSocket which ends the session.
+	_ = s.ws.WriteText(data)
+}
+
+// audit writes an entry to the audit trail of the session.
+func (s *consoleSession) audit(msg string) {
+	if s.gateway.Logger != nil {
+		s.gateway.Logger.Printf("console user=%q server=%q %s", s.token.Name, s.server, msg)
+	}
+}
@@ -0,0 +1,246 @@
+package gateway_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/gateway"
+)
+
+func TestGateway_Console(t *testing.T) {
+	newServer := func() *httptest.Server {
+		g := gateway.New(nil, []gateway.Token{
+			{Name: "alice", Token: "secret", Servers: []string{"tf2"}, Commands: []string{"status", "notify"}},
+		}, nil)
+		g.Consoles = map[string]gateway.ConsoleServer{
+			"tf2": {Dial: dialFakeSourceServer, Dialect: client.DialectSource, Password: "rcon"},
+		}
+		return httptest.NewServer(g)
+	}
+
+	t.Run("stream responses and unsolicited packets", func(t *testing.T) {
+		server := newServer()
+		defer server.Close()
+
+		ws := dialWebSocket(t, server.URL, "/servers/tf2/console?token=secret")
+		defer ws.Close()
+
+		info := ws.ReadMessage(t)
+		if info.Type != gateway.MessageInfo || info.User != "alice" {
+			t.Errorf("info message did not match: %+v\n", info)
+		}
+
+		ws.WriteMessage(t, `{"command": "notify"}`)
+
+		response := ws.ReadMessage(t)
+		if response.Type != gateway.MessageResponse || response.Command != "notify" || response.Body != "echo: notify" {
+			t.Errorf("response message did not match: %+v\n", response)
+		}
+		if response.User != "alice" || response.Server != "tf2" {
+			t.Errorf("response was not tagged with the user: %+v\n", response)
+		}
+
+		unsolicited := ws.ReadMessage(t)
+		if unsolicited.Type != gateway.MessageUnsolicited || unsolicited.Body != "L 01/01/2026 - 00:00:00: server notice" {
+			t.Errorf("unsolicited message did not match: %+v\n", unsolicited)
+		}
+
+		// the mirrored delimiter and the trailing Source packet must not be forwarded.
+		ws.WriteMessage(t, `{"command": "status"}`)
+		response = ws.ReadMessage(t)
+		if response.Type != gateway.MessageResponse || response.Command != "status" {
+			t.Errorf("response message did not match: %+v\n", response)
+		}
+	})
+
+	t.Run("denied command", func(t *testing.T) {
+		server := newServer()
+		defer server.Close()
+
+		ws := dialWebSocket(t, server.URL, "/servers/tf2/console?token=secret")
+		defer ws.Close()
+		ws.ReadMessage(t)
+
+		for _, cmd := range []string{"quit", "status;quit", "STATUS\nquit", "/quit", `"quit"`, `"QUIT" now`} {
+			ws.WriteMessage(t, fmt.Sprintf(`{"command": %q}`, cmd))
+			got := ws.ReadMessage(t)
+			if got.Type != gateway.MessageError || got.Body != "command not allowed" {
+				t.Errorf("error message of %q did not match: %+v\n", cmd, got)
+			}
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		server := newServer()
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/servers/tf2/console?token=wrong")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("status did not match:\nexpected: %d\ngot: %d\n", http.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+
+	t.Run("console page", func(t *testing.T) {
+		server := newServer()
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/console")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "WebSocket") {
+			t.Errorf("console page was not served: %d\n", resp.StatusCode)
+		}
+	})
+}
+
+// dialFakeSourceServer returns a connection to an in-memory server that behaves like a Source server.
+// It echos all commands and sends an additional unsolicited log line for the "notify" command.
+func dialFakeSourceServer() (net.Conn, error) {
+	clientConn, serverConn := net.Pipe()
+
+	go func() {
+		defer serverConn.Close()
+		remoteConsole := grcon.NewRemoteConsole(serverConn)
+
+		for {
+			packet, err := remoteConsole.Read()
+			if err != nil {
+				return
+			}
+
+			var responses []grcon.Packet
+			switch packet.Type {
+			case grcon.SERVERDATA_AUTH:
+				responses = []grcon.Packet{
+					{Id: packet.Id, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte{}},
+					{Id: packet.Id, Type: grcon.SERVERDATA_AUTH_RESPONSE, Body: []byte{}},
+				}
+			case grcon.SERVERDATA_EXECCOMMAND:
+				responses = []grcon.Packet{{Id: packet.Id, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: append([]byte("echo: "), packet.Body...)}}
+				if string(packet.Body) == "notify" {
+					responses = append(responses, grcon.Packet{Id: 0, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("L 01/01/2026 - 00:00:00: server notice")})
+				}
+			case grcon.SERVERDATA_RESPONSE_VALUE:
+				responses = []grcon.Packet{
+					{Id: packet.Id, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte{}},
+					{Id: packet.Id, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte{0, 1, 0, 0}},
+				}
+			}
+
+			for _, response := range responses {
+				if err := remoteConsole.Write(response); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return clientConn, nil
+}
+
+// testWebSocket is a minimal WebSocket client for the tests.
+type testWebSocket struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialWebSocket(t *testing.T, serverURL, path string) *testWebSocket {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(serverURL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	request := "GET " + path + " HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake failed with status %d\n", resp.StatusCode)
+	}
+	// example value of RFC 6455
+	if resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("accept header did not match: %s\n", resp.Header.Get("Sec-WebSocket-Accept"))
+	}
+
+	return &testWebSocket{conn: conn, reader: reader}
+}
+
+func (ws *testWebSocket) WriteMessage(t *testing.T, msg string) {
+	t.Helper()
+
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x81, 0x80 | byte(len(msg))}
+	frame = append(frame, mask[:]...)
+	for i := 0; i < len(msg); i++ {
+		frame = append(frame, msg[i]^mask[i%4])
+	}
+	if _, err := ws.conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (ws *testWebSocket) ReadMessage(t *testing.T) gateway.ConsoleMessage {
+	t.Helper()
+
+	var header [2]byte
+	if _, err := io.ReadFull(ws.reader, header[:]); err != nil {
+		t.Fatal(err)
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(ws.reader, ext[:]); err != nil {
+			t.Fatal(err)
+		}
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		t.Fatal(err)
+	}
+	if header[0]&0x0F != 0x1 {
+		t.Fatalf("expected text frame but got opcode %d: %q\n", header[0]&0x0F, payload)
+	}
+
+	var msg gateway.ConsoleMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func (ws *testWebSocket) Close() error {
+	return ws.conn.Close()
+}
@@ -0,0 +1,223 @@
+/*
+Package gateway exposes RCON servers over HTTP.
+
+Every configured server is reachable with:
+
+	POST /servers/{name}/exec
+	Authorization: Bearer <token>
+	{"command": "list"}
+
+which responds with:
+
+	{"server": "survival", "command": "list", "response": "There are 0 of a max of 20 players online: "}
+
+Live console sessions are available as WebSocket under /servers/{name}/console.
+The browser sends {"command": "..."} messages and receives every packet of a dedicated
+RCON connection as ConsoleMessage, including packets the server sent unsolicited.
+A small HTML console is served under /console.
+
+Each Token has its own allowlist of servers and commands.
+The gateway uses long-lived clients (see client.ReconnectingClient) instead of dialing per request.
+*/
+package gateway
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+)
+
+// maxRequestBody limits the size of the JSON request body.
+const maxRequestBody = 64 * 1024
+
+// ExecRequest is the JSON body of an exec request.
+type ExecRequest struct {
+	Command string `json:"command"`
+}
+
+// ExecResponse is the JSON body of a successful exec request.
+type ExecResponse struct {
+	Server   string `json:"server"`
+	Command  string `json:"command"`
+	Response string `json:"response"`
+}
+
+// ErrorResponse is the JSON body of a failed request.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// New is a constructor for the Gateway struct.
+func New(clients map[string]client.Client, tokens []Token, logger *log.Logger) *Gateway {
+	return &Gateway{Clients: clients, Tokens: tokens, Logger: logger}
+}
+
+// Gateway is a http.Handler that executes the commands of authorized requests on the clients.
+type Gateway struct {
+	// Clients maps the server names to the clients to use.
+	// The clients have to be authenticated and safe for concurrent use.
+	Clients map[string]client.Client
+	// Tokens that grant access.
+	Tokens []Token
+	// Consoles maps the server names to the servers that are available
+	// for live console sessions over WebSocket.
+	Consoles map[string]ConsoleServer
+	// Logger for the request and audit log. Nothing is logged if it is nil.
+	Logger *log.Logger
+}
+
+// ServeHTTP routes and handles the request.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	entry := &logEntry{}
+
+	g.serve(recorder, r, entry)
+
+	if g.Logger != nil {
+		g.Logger.Printf("%s %s token=%q server=%q command=%q status=%d duration=%s",
+			r.Method, r.URL.Path, entry.token, entry.server, entry.command, recorder.status, time.Since(start))
+	}
+}
+
+// logEntry collects the information of a request for the request log.
+type logEntry struct {
+	token   string
+	server  string
+	command string
+}
+
+func (g *Gateway) serve(w http.ResponseWriter, r *http.Request, entry *logEntry) {
+	if r.URL.Path == "/console" {
+		serveConsolePage(w, r)
+		return
+	}
+
+	name, action, ok := parsePath(r.URL.Path)
+	if !ok || (action != "exec" && action != "console") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	entry.server = name
+
+	if action == "console" {
+		g.serveConsole(w, r, name, entry)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	token, ok := authenticate(g.Tokens, r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="grcon"`)
+		writeError(w, http.StatusUnauthorized, "invalid or missing bearer token")
+		return
+	}
+	entry.token = token.Name
+
+	c, ok := g.Clients[name]
+	if !ok || !token.AllowsServer(name) {
+		// unknown and forbidden servers are not distinguished to not leak the server names.
+		writeError(w, http.StatusNotFound, "unknown server")
+		return
+	}
+
+	var req ExecRequest
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxRequestBody))
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	entry.command = req.Command
+	if strings.TrimSpace(req.Command) == "" {
+		writeError(w, http.StatusBadRequest, "command is empty")
+		return
+	}
+
+	if !token.AllowsCommand(req.Command) {
+		writeError(w, http.StatusForbidden, "command not allowed")
+		return
+	}
+
+	response, err := c.Exec(req.Command)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ExecResponse{Server: name, Command: req.Command, Response: string(response)})
+}
+
+// Close closes all clients that implement io.Closer.
+func (g *Gateway) Close() error {
+	var firstErr error
+	for _, c := range g.Clients {
+		if closer, ok := c.(io.Closer); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// parsePath splits a path of the format /servers/{name}/{action}.
+func parsePath(path string) (name, action string, ok bool) {
+	if !strings.HasPrefix(path, "/servers/") {
+		return "", "", false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path, "/servers/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// the status is already written, errors can only be logged by the caller.
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, ErrorResponse{Error: msg})
+}
+
+// statusRecorder remembers the status code for the request log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack takes over the connection for WebSockets.
+func (sr *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := sr.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		sr.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
@@ -0,0 +1,120 @@
+package gateway_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/gateway"
+)
+
+func TestGateway_Exec(t *testing.T) {
+	newGateway := func() (*gateway.Gateway, *MockClient) {
+		mock := &MockClient{Responses: map[string]string{"list": "There are 0 of a max of 20 players online: "}}
+		g := gateway.New(
+			map[string]client.Client{"survival": mock, "creative": &MockClient{}},
+			[]gateway.Token{
+				{Name: "ci", Token: "secret", Servers: []string{"survival"}, Commands: []string{"list", "say *"}},
+				{Name: "admin", Token: "root", Servers: []string{"*"}, Commands: []string{"*"}},
+			},
+			nil,
+		)
+		return g, mock
+	}
+
+	t.Run("successful execution", func(t *testing.T) {
+		g, mock := newGateway()
+
+		recorder := doRequest(g, http.MethodPost, "/servers/survival/exec", "secret", `{"command": "list"}`)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status did not match:\nexpected: %d\ngot: %d\nbody: %s\n", http.StatusOK, recorder.Code, recorder.Body.String())
+		}
+
+		var got gateway.ExecResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if got.Server != "survival" || got.Response != "There are 0 of a max of 20 players online: " {
+			t.Errorf("response did not match: %+v\n", got)
+		}
+		if len(mock.Executed) != 1 || mock.Executed[0] != "list" {
+			t.Errorf("executed commands did not match: %v\n", mock.Executed)
+		}
+	})
+
+	t.Run("status codes", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			method string
+			path   string
+			token  string
+			body   string
+			expect int
+		}{
+			{name: "missing token", method: http.MethodPost, path: "/servers/survival/exec", body: `{"command": "list"}`, expect: http.StatusUnauthorized},
+			{name: "wrong token", method: http.MethodPost, path: "/servers/survival/exec", token: "wrong", body: `{"command": "list"}`, expect: http.StatusUnauthorized},
+			{name: "forbidden server", method: http.MethodPost, path: "/servers/creative/exec", token: "secret", body: `{"command": "list"}`, expect: http.StatusNotFound},
+			{name: "unknown server", method: http.MethodPost, path: "/servers/foo/exec", token: "root", body: `{"command": "list"}`, expect: http.StatusNotFound},
+			{name: "forbidden command", method: http.MethodPost, path: "/servers/survival/exec", token: "secret", body: `{"command": "stop"}`, expect: http.StatusForbidden},
+			{name: "invalid json", method: http.MethodPost, path: "/servers/survival/exec", token: "secret", body: `{`, expect: http.StatusBadRequest},
+			{name: "empty command", method: http.MethodPost, path: "/servers/survival/exec", token: "secret", body: `{"command": " "}`, expect: http.StatusBadRequest},
+			{name: "wrong method", method: http.MethodGet, path: "/servers/survival/exec", token: "secret", expect: http.StatusMethodNotAllowed},
+			{name: "unknown path", method: http.MethodPost, path: "/foo", token: "secret", expect: http.StatusNotFound},
+			{name: "rcon error", method: http.MethodPost, path: "/servers/creative/exec", token: "root", body: `{"command": "fail"}`, expect: http.StatusBadGateway},
+			{name: "allowed by wildcard", method: http.MethodPost, path: "/servers/survival/exec", token: "secret", body: `{"command": "say hello world"}`, expect: http.StatusOK},
+			{name: "allowed in upper case", method: http.MethodPost, path: "/servers/survival/exec", token: "secret", body: `{"command": "LIST"}`, expect: http.StatusOK},
+			{name: "chained command", method: http.MethodPost, path: "/servers/survival/exec", token: "secret", body: `{"command": "list;stop"}`, expect: http.StatusForbidden},
+			{name: "chained command after wildcard", method: http.MethodPost, path: "/servers/survival/exec", token: "secret", body: `{"command": "say hi; stop"}`, expect: http.StatusForbidden},
+			{name: "command after newline", method: http.MethodPost, path: "/servers/survival/exec", token: "secret", body: `{"command": "say hi\nstop"}`, expect: http.StatusForbidden},
+			{name: "forbidden command with slash", method: http.MethodPost, path: "/servers/survival/exec", token: "secret", body: `{"command": "/stop"}`, expect: http.StatusForbidden},
+			{name: "forbidden quoted command", method: http.MethodPost, path: "/servers/survival/exec", token: "secret", body: `{"command": "\"stop\""}`, expect: http.StatusForbidden},
+			{name: "allowed command with slash", method: http.MethodPost, path: "/servers/survival/exec", token: "secret", body: `{"command": "/list"}`, expect: http.StatusOK},
+			{name: "chained command with all commands", method: http.MethodPost, path: "/servers/survival/exec", token: "root", body: `{"command": "status;kickall"}`, expect: http.StatusForbidden},
+		}
+
+		for _, test := range tests {
+			test := test
+			t.Run(test.name, func(t *testing.T) {
+				g, _ := newGateway()
+				recorder := doRequest(g, test.method, test.path, test.token, test.body)
+				if recorder.Code != test.expect {
+					t.Errorf("status did not match:\nexpected: %d\ngot: %d\nbody: %s\n", test.expect, recorder.Code, recorder.Body.String())
+				}
+			})
+		}
+	})
+}
+
+func doRequest(handler http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// Mock implementations for tests
+
+type MockClient struct {
+	Responses map[string]string
+	Executed  []string
+}
+
+func (m *MockClient) Auth(password string) error {
+	return nil
+}
+
+func (m *MockClient) Exec(cmd string) ([]byte, error) {
+	m.Executed = append(m.Executed, cmd)
+	if cmd == "fail" {
+		return nil, errors.New("connection refused")
+	}
+	return []byte(m.Responses[cmd]), nil
+}
@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/hamburghammer/grcon/util"
+)
+
+// Token grants access to the gateway.
+type Token struct {
+	// Name identifies the token in the logs.
+	Name string `json:"name"`
+	// Token is the secret that has to be sent as bearer token.
+	Token string `json:"token"`
+	// Servers the token has access to. "*" allows all servers.
+	Servers []string `json:"servers"`
+	// Commands is a list of patterns for util.MatchCommand of the allowed commands.
+	// "*" allows all commands.
+	Commands []string `json:"commands"`
+}
+
+// AllowsServer reports if the token has access to the server.
+func (t Token) AllowsServer(name string) bool {
+	for _, server := range t.Servers {
+		if server == "*" || server == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsCommand reports if the token is allowed to execute the command.
+// Chained commands like "status;kickall" are never allowed, see util.MatchCommand.
+func (t Token) AllowsCommand(cmd string) bool {
+	for _, pattern := range t.Commands {
+		if util.MatchCommand(pattern, cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate returns the token that matches the bearer token of the request.
+// All tokens are compared in constant time to not leak which prefix matched.
+func authenticate(tokens []Token, r *http.Request) (Token, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return Token{}, false
+	}
+
+	return lookupToken(tokens, strings.TrimSpace(strings.TrimPrefix(header, "Bearer ")))
+}
+
+// lookupToken returns the token with the secret.
+func lookupToken(tokens []Token, secret string) (Token, bool) {
+	if secret == "" {
+		return Token{}, false
+	}
+
+	var found Token
+	ok := false
+	for _, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token.Token), []byte(secret)) == 1 && !ok {
+			found = token
+			ok = true
+		}
+	}
+	return found, ok
+}
@@ -27,6 +27,21 @@ func NewRemoteConsole(conn net.Conn) *RemoteConsole {
 	return remoteConsole
 }
 
+// NewRemoteConsoleSize creates a new RemoteConsole that reads packets up to the maxPacket size.
+// It is meant for servers that send packets longer than the MaxPacket, like minecraft servers
+// for long responses. A maxPacket smaller than the MaxPacket is ignored.
+func NewRemoteConsoleSize(conn net.Conn, maxPacket int) *RemoteConsole {
+	if maxPacket < int(MaxPacket) {
+		return NewRemoteConsole(conn)
+	}
+
+	return &RemoteConsole{
+		Conn:      conn,
+		ReadBuff:  make([]byte, size(maxPacket)+sizeField),
+		maxPacket: size(maxPacket),
+	}
+}
+
 // RemoteConsole holds the information to communicate withe remote console (server).
 // To optain a preconfigured RemoteConsole use the NewRemoteConsole() function.
 // The NewRemoteConsole() function is also the recommended way to get a *RemoteConsole.
@@ -38,11 +53,13 @@ type RemoteConsole struct {
 	Conn net.Conn
 
 	// ReadBuff should at least have the capacity for a hole packet.
-	// Capacity >= MaxPacket + 4.
+	// Capacity >= MaxPacket + 4 or the maxPacket of the NewRemoteConsoleSize + 4.
 	ReadBuff []byte
 
 	readMutex  sync.Mutex
 	queuedBuff []byte
+	// maxPacket replaces the MaxPacket if it is set.
+	maxPacket size
 }
 
 // Write writes a packet with a given id, type and body.
@@ -99,7 +116,8 @@ func (r *RemoteConsole) Write(packet Packet) error {
 
 // Read returns all the parts of the read packet.
 // Returns an ResponseTooLongError if the size of the packet is bigger
-// than the MaxPacket siz. It can also return an UnexpectedForamatError
+// than the MaxPacket size or the maxPacket of the NewRemoteConsoleSize.
+// It can also return an UnexpectedForamatError
 // if the packet size is smaller than the MinPacket size.
 func (r *RemoteConsole) Read() (Packet, error) {
 	r.readMutex.Lock()
@@ -123,7 +141,11 @@ func (r *RemoteConsole) Read() (Packet, error) {
 		return Packet{}, err
 	}
 
-	if dataSize > MaxPacket {
+	maxPacket := MaxPacket
+	if r.maxPacket > 0 {
+		maxPacket = r.maxPacket
+	}
+	if dataSize > maxPacket {
 		return Packet{}, newResponseTooLongError()
 	}
 
@@ -205,9 +227,11 @@ func (r *RemoteConsole) parsePacket(data []byte) (Packet, error) {
 	}
 
 	// the rest of the buffer is the body.
-	body := buffer.Bytes()
+	// It gets copied because the buffer is reused by the next read.
+	rest := buffer.Bytes()
 	// remove the to null terminations
-	body = body[:len(body)-2]
+	body := make([]byte, len(rest)-2)
+	copy(body, rest)
 
 	parsedPacket := Packet{
 		Id:   PacketId(requestID),
@@ -2,7 +2,15 @@ package grcon_test
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
 	"errors"
+	"math/big"
 	"net"
 	"testing"
 	"time"
@@ -222,6 +230,38 @@ func TestRemoteConsole_Read(t *testing.T) {
 
 	})
 
+	t.Run("body is not overwritten by the next read", func(t *testing.T) {
+		mockConn := &MockConn{}
+		mockConn.Receive = [][]byte{{
+			// size, id, type
+			13, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0,
+			// body "foo" with terminations
+			102, 111, 111, 0, 0,
+
+			// size, id, type
+			13, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0,
+			// body "bar" with terminations
+			98, 97, 114, 0, 0,
+		}}
+		remoteConsole := grcon.NewRemoteConsole(mockConn)
+
+		// under test
+		first, err := remoteConsole.Read()
+		if err != nil {
+			t.Errorf("an error occurred that was not expected: %s", err.Error())
+			t.FailNow()
+		}
+		_, err = remoteConsole.Read()
+		if err != nil {
+			t.Errorf("an error occurred that was not expected: %s", err.Error())
+			t.FailNow()
+		}
+
+		if string(first.Body) != "foo" {
+			t.Errorf("body of the first packet changed:\nexpected: %s\ngot: %s\n", "foo", string(first.Body))
+		}
+	})
+
 	t.Run("receive size field over slow connection", func(t *testing.T) {
 		mockConn := &MockConn{}
 		mockConn.Receive = make([][]byte, 0, 5)
@@ -320,6 +360,35 @@ func TestRemoteConsole_Read(t *testing.T) {
 		}
 	})
 
+	t.Run("long packet with a larger size", func(t *testing.T) {
+		body := bytes.Repeat([]byte("a"), 5000)
+		data := make([]byte, 12, 12+len(body)+2)
+		// size, id 1 and type 0
+		binary.LittleEndian.PutUint32(data, uint32(len(body)+10))
+		binary.LittleEndian.PutUint32(data[4:], 1)
+		data = append(append(data, body...), 0, 0)
+
+		mockConn := &MockConn{}
+		mockConn.Receive = [][]byte{data, data}
+		remoteConsole := grcon.NewRemoteConsoleSize(mockConn, 5010)
+
+		// under test
+		got, err := remoteConsole.Read()
+		if err != nil {
+			t.Errorf("an error occurred that was not expected: %s", err.Error())
+			t.FailNow()
+		}
+		if !bytes.Equal(got.Body, body) {
+			t.Errorf("body did not match:\nexpected: %d bytes\ngot: %d bytes\n", len(body), len(got.Body))
+		}
+
+		remoteConsole = grcon.NewRemoteConsoleSize(mockConn, 5009)
+		_, err = remoteConsole.Read()
+		if _, ok := err.(grcon.ResponseTooLongError); !ok {
+			t.Errorf("error did not match:\nexpected:\n%T\ngot:\n%T", grcon.ResponseTooLongError{}, err)
+		}
+	})
+
 	t.Run("too small packet", func(t *testing.T) {
 		mockConn := &MockConn{}
 		mockConn.Receive = make([][]byte, 0, 1)
@@ -347,6 +416,107 @@ func TestRemoteConsole_Read(t *testing.T) {
 	})
 }
 
+func TestRemoteConsole_TLS(t *testing.T) {
+	clientConn, serverConn := tlsConnPair(t)
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	clientConsole := grcon.NewRemoteConsole(clientConn)
+	serverConsole := grcon.NewRemoteConsole(serverConn)
+
+	packets := []grcon.Packet{
+		{Id: 1, Type: grcon.SERVERDATA_AUTH, Body: []byte("secret")},
+		{Id: 2, Type: grcon.SERVERDATA_EXECCOMMAND, Body: []byte{}},
+		{Id: 3, Type: grcon.SERVERDATA_EXECCOMMAND, Body: bytes.Repeat([]byte("a"), int(grcon.MaxBody))},
+		{Id: 4, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte("list")},
+	}
+
+	go func() {
+		for _, packet := range packets {
+			if err := clientConsole.Write(packet); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, expected := range packets {
+		got, err := serverConsole.Read()
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if !EqualPacket(expected, got) {
+			t.Errorf("packet did not match:\nexpected: id=%d type=%d size=%d\ngot: id=%d type=%d size=%d\n",
+				expected.Id, expected.Type, len(expected.Body), got.Id, got.Type, len(got.Body))
+		}
+	}
+}
+
+// tlsConnPair returns both sides of a TLS connection over loopback TCP with a generated self-signed certificate.
+func tlsConnPair(t *testing.T) (*tls.Conn, *tls.Conn) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		// the handshake has to run concurrently with the one of the client.
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if err := conn.(*tls.Conn).Handshake(); err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	clientConn, err := tls.DialWithDialer(dialer, "tcp", l.Addr().String(), &tls.Config{RootCAs: pool, ServerName: "localhost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConn, ok := <-accepted
+	if !ok {
+		t.Fatal("accepting the TLS connection failed")
+	}
+
+	clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+	serverConn.SetDeadline(time.Now().Add(5 * time.Second))
+	return clientConn, serverConn.(*tls.Conn)
+}
+
 // Helper functions
 
 func EqualPacket(expected, got grcon.Packet) bool {
@@ -0,0 +1,220 @@
+/*
+Package inventory loads the list of RCON servers shared by all grcon tools.
+
+The inventory is a JSON file:
+
+	{
+		"servers": {
+			"cs2-eu-1": {
+				"address": "10.0.0.1:27015",
+				"dialect": "source",
+				"password": {"env": "CS2_EU_1_RCON"},
+				"game": "cs2",
+				"tags": ["eu", "competitive"],
+				"labels": {"datacenter": "fra"}
+			},
+			"survival": {
+				"address": "10.0.1.1:25575",
+				"dialect": "minecraft",
+				"password": {"secret": "survival_rcon"},
+				"timeout": "5s",
+				"max_command_length": 1446
+			},
+			"tf2": {
+				"address": "tf2.example.com:27016",
+				"dialect": "source",
+				"password": {"file": "/etc/grcon/tf2"},
+				"tls": {"ca_file": "/etc/grcon/ca.pem", "pins": ["sha256/..."]}
+			}
+		},
+		"groups": {
+			"patch-day": ["cs2-eu-1", "survival"]
+		},
+		"jobs": [
+			{"name": "save", "schedule": "@every 10m", "servers": "name=survival", "commands": ["save-all"]}
+		]
+	}
+
+Servers are picked with a Selector like "tag=eu,game=cs2" and the
+ready-to-use clients are built with Clients.
+The jobs are run by the daemon of the grcon CLI.
+*/
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// DefaultTimeout is used for servers without timeout.
+const DefaultTimeout = 10 * time.Second
+
+// Inventory is the list of servers.
+type Inventory struct {
+	Servers map[string]Server `json:"servers"`
+	// Groups maps the group names to the names of their servers.
+	Groups map[string][]string `json:"groups"`
+	// SecretsDir is the directory of the Docker secrets, defaults to DefaultSecretsDir.
+	SecretsDir string `json:"secrets_dir"`
+	// Jobs are the scheduled commands of the daemon.
+	Jobs []Job `json:"jobs"`
+}
+
+// Server describes how to connect to a RCON server.
+type Server struct {
+	Address  string         `json:"address"`
+	Dialect  client.Dialect `json:"dialect"`
+	Password PasswordSource `json:"password"`
+	// Timeout for a single command, defaults to DefaultTimeout.
+	Timeout util.Duration `json:"timeout"`
+	// MaxCommandLength is the longest command the server accepts.
+	// Defaults to the limit of the dialect.
+	MaxCommandLength int `json:"max_command_length"`
+	// TLS connects with RCONS (RCON over TLS) if it is set, e.g. to a server behind stunnel.
+	TLS *client.TLSOptions `json:"tls"`
+
+	// Game, Tags and Labels are used by selectors.
+	Game   string            `json:"game"`
+	Tags   []string          `json:"tags"`
+	Labels map[string]string `json:"labels"`
+}
+
+// Load reads and validates the inventory file.
+func Load(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var inv Inventory
+	if err := json.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("parsing inventory '%s': %w", path, err)
+	}
+
+	return &inv, inv.Validate()
+}
+
+// Validate checks that all required values are set and the groups only contain known servers.
+// The passwords are not resolved.
+func (inv *Inventory) Validate() error {
+	for name, server := range inv.Servers {
+		if server.Address == "" {
+			return fmt.Errorf("server '%s': address is missing", name)
+		}
+		if server.Dialect == "" {
+			return fmt.Errorf("server '%s': dialect is missing", name)
+		}
+		if err := server.Password.validate(); err != nil {
+			return fmt.Errorf("server '%s': %w", name, err)
+		}
+	}
+	for group, names := range inv.Groups {
+		for _, name := range names {
+			if _, ok := inv.Servers[name]; !ok {
+				return fmt.Errorf("group '%s': unknown server '%s'", group, name)
+			}
+		}
+	}
+	_, err := inv.SchedulerJobs()
+	return err
+}
+
+// Names returns the sorted names of all servers.
+func (inv *Inventory) Names() []string {
+	names := make([]string, 0, len(inv.Servers))
+	for name := range inv.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select returns the sorted names of the servers that match the selector.
+func (inv *Inventory) Select(selector string) ([]string, error) {
+	parsed, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, name := range inv.Names() {
+		if parsed.Matches(name, inv.Servers[name], inv.groupsOf(name)) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// groupsOf returns the names of the groups that contain the server.
+func (inv *Inventory) groupsOf(name string) []string {
+	groups := []string{}
+	for group, names := range inv.Groups {
+		for _, member := range names {
+			if member == name {
+				groups = append(groups, group)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// Client resolves the password and creates a ReconnectingClient for the server.
+// The connection gets established with the first command.
+func (inv *Inventory) Client(name string) (*client.ReconnectingClient, error) {
+	server, ok := inv.Servers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown server '%s'", name)
+	}
+
+	secretsDir := inv.SecretsDir
+	if secretsDir == "" {
+		secretsDir = DefaultSecretsDir
+	}
+	password, err := server.Password.Resolve(secretsDir)
+	if err != nil {
+		return nil, fmt.Errorf("server '%s': %w", name, err)
+	}
+
+	timeout := time.Duration(server.Timeout)
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	maxCommandLength := server.MaxCommandLength
+	if maxCommandLength <= 0 {
+		maxCommandLength = server.Dialect.MaxCommandLength()
+	}
+
+	dial := client.TCPDialer(server.Address, timeout)
+	if server.TLS != nil {
+		config, err := server.TLS.Config(server.Address)
+		if err != nil {
+			return nil, fmt.Errorf("server '%s': %w", name, err)
+		}
+		dial = client.TLSDialer(server.Address, timeout, config)
+	}
+
+	c := client.NewReconnectingClient(dial, server.Dialect, password)
+	c.Timeout = timeout
+	c.MaxCommandLength = maxCommandLength
+	return c, nil
+}
+
+// Clients creates the clients for the servers with the names.
+func (inv *Inventory) Clients(names []string) (map[string]client.Client, error) {
+	clients := make(map[string]client.Client, len(names))
+	for _, name := range names {
+		c, err := inv.Client(name)
+		if err != nil {
+			return nil, err
+		}
+		clients[name] = c
+	}
+	return clients, nil
+}
@@ -0,0 +1,175 @@
+package inventory_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/inventory"
+	"github.com/hamburghammer/grcon/scheduler"
+)
+
+const testInventory = `{
+	"servers": {
+		"cs2-eu-1": {"address": "10.0.0.1:27015", "dialect": "source", "password": "inline", "game": "cs2", "tags": ["eu"]},
+		"cs2-eu-2": {"address": "10.0.0.2:27015", "dialect": "source", "password": {"env": "GRCON_TEST_PASSWORD"}, "game": "cs2", "tags": ["eu"], "labels": {"dc": "fra"}},
+		"cs2-us-1": {"address": "10.0.0.3:27015", "dialect": "source", "password": {"file": "%FILE%"}, "game": "cs2", "tags": ["us"]},
+		"survival": {"address": "10.0.1.1:25575", "dialect": "minecraft", "password": {"secret": "survival"}, "timeout": "3s", "tags": ["eu"]}
+	},
+	"groups": {"patch-day": ["cs2-eu-1", "survival"]},
+	"secrets_dir": "%SECRETS%"
+}`
+
+func loadTestInventory(t *testing.T) *inventory.Inventory {
+	dir := t.TempDir()
+	passwordFile := writeFile(t, dir, "password", "from-file\n")
+	secretsDir := filepath.Join(dir, "secrets")
+	if err := os.Mkdir(secretsDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, secretsDir, "survival", "from-secret\n")
+
+	content := testInventory
+	content = strings.Replace(content, "%FILE%", passwordFile, 1)
+	content = strings.Replace(content, "%SECRETS%", secretsDir, 1)
+
+	inv, err := inventory.Load(writeFile(t, dir, "inventory.json", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inv
+}
+
+func TestInventory_Client(t *testing.T) {
+	os.Setenv("GRCON_TEST_PASSWORD", "from-env")
+	defer os.Unsetenv("GRCON_TEST_PASSWORD")
+	inv := loadTestInventory(t)
+
+	tests := map[string]string{
+		"cs2-eu-1": "inline",
+		"cs2-eu-2": "from-env",
+		"cs2-us-1": "from-file",
+		"survival": "from-secret",
+	}
+	for name, expect := range tests {
+		c, err := inv.Client(name)
+		if err != nil {
+			t.Errorf("creating client of %s failed: %s\n", name, err.Error())
+			continue
+		}
+		if c.Password != expect {
+			t.Errorf("password of %s did not match:\nexpected: %s\ngot: %s\n", name, expect, c.Password)
+		}
+	}
+
+	survival, _ := inv.Client("survival")
+	if survival.Timeout != 3*time.Second || survival.MaxCommandLength != 1446 {
+		t.Errorf("limits of survival did not match: timeout %s, max command length %d\n", survival.Timeout, survival.MaxCommandLength)
+	}
+
+	os.Unsetenv("GRCON_TEST_PASSWORD")
+	if _, err := inv.Client("cs2-eu-2"); err == nil {
+		t.Error("expected an error for the missing environment variable")
+	}
+
+	server := inv.Servers["cs2-eu-1"]
+	server.TLS = &client.TLSOptions{CAFile: filepath.Join(t.TempDir(), "missing.pem")}
+	inv.Servers["cs2-eu-1"] = server
+	if _, err := inv.Client("cs2-eu-1"); err == nil {
+		t.Error("expected an error for the missing CA file")
+	}
+}
+
+func TestInventory_Select(t *testing.T) {
+	inv := loadTestInventory(t)
+
+	tests := []struct {
+		selector string
+		expect   []string
+	}{
+		{selector: "", expect: []string{"cs2-eu-1", "cs2-eu-2", "cs2-us-1", "survival"}},
+		{selector: "tag=eu,game=cs2", expect: []string{"cs2-eu-1", "cs2-eu-2"}},
+		{selector: "group=patch-day", expect: []string{"cs2-eu-1", "survival"}},
+		{selector: "name=cs2-*,tag!=us", expect: []string{"cs2-eu-1", "cs2-eu-2"}},
+		{selector: "dc=fra", expect: []string{"cs2-eu-2"}},
+		{selector: "dialect=minecraft", expect: []string{"survival"}},
+		{selector: "game=factorio", expect: []string{}},
+		{selector: "dc=FRA", expect: []string{}},
+		{selector: "name=/survival", expect: []string{}},
+	}
+	for _, test := range tests {
+		got, err := inv.Select(test.selector)
+		if err != nil {
+			t.Errorf("selector %q failed: %s\n", test.selector, err.Error())
+			continue
+		}
+		if len(got) != len(test.expect) {
+			t.Errorf("selection of %q did not match:\nexpected: %v\ngot: %v\n", test.selector, test.expect, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.expect[i] {
+				t.Errorf("selection of %q did not match:\nexpected: %v\ngot: %v\n", test.selector, test.expect, got)
+				break
+			}
+		}
+	}
+
+	for _, invalid := range []string{"eu", "=eu", "tag=", "tag=eu,"} {
+		if _, err := inv.Select(invalid); err == nil {
+			t.Errorf("expected an error for the selector %q\n", invalid)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	invalid := map[string]string{
+		"missing address":  `{"servers": {"a": {"dialect": "source", "password": "x"}}}`,
+		"missing password": `{"servers": {"a": {"address": "a:1", "dialect": "source"}}}`,
+		"two passwords":    `{"servers": {"a": {"address": "a:1", "dialect": "source", "password": {"value": "x", "env": "Y"}}}}`,
+		"unknown member":   `{"servers": {}, "groups": {"g": ["a"]}}`,
+		"invalid schedule": `{"servers": {}, "jobs": [{"name": "j", "schedule": "61 * * * *", "commands": ["list"]}]}`,
+		"invalid selector": `{"servers": {}, "jobs": [{"name": "j", "schedule": "@daily", "servers": "eu", "commands": ["list"]}]}`,
+	}
+	for name, content := range invalid {
+		if _, err := inventory.Load(writeFile(t, t.TempDir(), "inventory.json", content)); err == nil {
+			t.Errorf("expected an error for the %s\n", name)
+		}
+	}
+}
+
+func TestInventory_SchedulerJobs(t *testing.T) {
+	content := `{
+		"servers": {
+			"a": {"address": "a:1", "dialect": "minecraft", "password": "x", "tags": ["eu"]},
+			"b": {"address": "b:1", "dialect": "minecraft", "password": "x", "tags": ["us"]}
+		},
+		"jobs": [{"name": "save", "schedule": "0 * * * *", "servers": "tag=eu", "commands": ["save-all"], "jitter": "30s", "missed_run": "run_once"}]
+	}`
+	inv, err := inventory.Load(writeFile(t, t.TempDir(), "inventory.json", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := inv.SchedulerJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || len(jobs[0].Servers) != 1 || jobs[0].Servers[0] != "a" {
+		t.Fatalf("jobs did not match: %+v\n", jobs)
+	}
+	if jobs[0].Jitter != 30*time.Second || jobs[0].MissedRun != scheduler.MissedRunOnce {
+		t.Errorf("job did not match: %+v\n", jobs[0])
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
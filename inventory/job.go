@@ -0,0 +1,58 @@
+package inventory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hamburghammer/grcon/scheduler"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// Job is the config of a scheduled command sequence (see the scheduler package).
+//
+//	"jobs": [
+//		{"name": "save", "schedule": "*/10 * * * *", "servers": "game=minecraft", "commands": ["save-all"], "jitter": "30s"},
+//		{"name": "rotate", "schedule": "0 5 * * *", "servers": "tag=eu,game=cs2", "commands": ["changelevel de_inferno"], "missed_run": "run_once"}
+//	]
+type Job struct {
+	Name string `json:"name"`
+	// Schedule is a cron expression, see scheduler.ParseSchedule.
+	Schedule string `json:"schedule"`
+	// Servers is a selector of the servers to run the commands on.
+	Servers   string                    `json:"servers"`
+	Commands  []string                  `json:"commands"`
+	Jitter    util.Duration             `json:"jitter"`
+	MissedRun scheduler.MissedRunPolicy `json:"missed_run"`
+	// Timeout for a single command, defaults to the timeout of the fleet package.
+	Timeout util.Duration `json:"timeout"`
+	// Output is the path of a file the responses get appended to.
+	// They are written to the log if it is empty.
+	Output string `json:"output"`
+}
+
+// SchedulerJobs parses the schedules and selects the servers of the jobs.
+// The jobs are returned in the same order without Output.
+func (inv *Inventory) SchedulerJobs() ([]scheduler.Job, error) {
+	jobs := make([]scheduler.Job, 0, len(inv.Jobs))
+	for _, job := range inv.Jobs {
+		schedule, err := scheduler.ParseSchedule(job.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("job '%s': %w", job.Name, err)
+		}
+		servers, err := inv.Select(job.Servers)
+		if err != nil {
+			return nil, fmt.Errorf("job '%s': %w", job.Name, err)
+		}
+
+		jobs = append(jobs, scheduler.Job{
+			Name:      job.Name,
+			Schedule:  schedule,
+			Servers:   servers,
+			Commands:  job.Commands,
+			Jitter:    time.Duration(job.Jitter),
+			MissedRun: job.MissedRun,
+			Timeout:   time.Duration(job.Timeout),
+		})
+	}
+	return jobs, nil
+}
@@ -0,0 +1,98 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultSecretsDir is the directory Docker mounts the secrets to.
+const DefaultSecretsDir = "/run/secrets"
+
+// PasswordSource describes where the password of a server comes from.
+// Exactly one of the fields has to be set.
+// A plain JSON string is a shorthand for an inline Value.
+//
+//	"password": "secret"
+//	"password": {"env": "SURVIVAL_RCON_PASSWORD"}
+//	"password": {"file": "/etc/grcon/survival.password"}
+//	"password": {"secret": "survival_rcon_password"}
+type PasswordSource struct {
+	// Value is the inline password.
+	Value string `json:"value,omitempty"`
+	// Env is the name of an environment variable.
+	Env string `json:"env,omitempty"`
+	// File is the path of a file that contains the password.
+	File string `json:"file,omitempty"`
+	// Secret is the name of a Docker secret in the secrets directory.
+	Secret string `json:"secret,omitempty"`
+}
+
+// UnmarshalJSON accepts a string or an object.
+func (ps *PasswordSource) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*ps = PasswordSource{Value: value}
+		return nil
+	}
+
+	// the alias type prevents the recursion into this method.
+	type source PasswordSource
+	var s source
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*ps = PasswordSource(s)
+	return nil
+}
+
+// validate checks that exactly one source is set.
+func (ps PasswordSource) validate() error {
+	set := 0
+	for _, value := range []string{ps.Value, ps.Env, ps.File, ps.Secret} {
+		if value != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("password needs exactly one of value, env, file or secret")
+	}
+	return nil
+}
+
+// Resolve returns the password. Docker secrets are read from the secretsDir.
+// A trailing newline of files and secrets is removed.
+func (ps PasswordSource) Resolve(secretsDir string) (string, error) {
+	if err := ps.validate(); err != nil {
+		return "", err
+	}
+
+	switch {
+	case ps.Env != "":
+		password, ok := os.LookupEnv(ps.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable '%s' is not set", ps.Env)
+		}
+		return password, nil
+	case ps.File != "":
+		return readPasswordFile(ps.File)
+	case ps.Secret != "":
+		if strings.ContainsAny(ps.Secret, `/\`) {
+			return "", fmt.Errorf("invalid secret name '%s'", ps.Secret)
+		}
+		return readPasswordFile(filepath.Join(secretsDir, ps.Secret))
+	}
+
+	return ps.Value, nil
+}
+
+func readPasswordFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
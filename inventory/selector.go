@@ -0,0 +1,101 @@
+package inventory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hamburghammer/grcon/util"
+)
+
+// Selector picks servers of the inventory.
+// It is a comma separated list of conditions that all have to match:
+//
+//	tag=eu,game=cs2
+//	group=patch-day,name!=cs2-eu-3
+//
+// The keys are:
+//	- name: the name of the server
+//	- tag: one of the tags of the server
+//	- group: one of the groups that contain the server
+//	- game and dialect: the values of the server
+//	- every other key matches the label with the same name
+//
+// Values can contain the wildcards of util.MatchWildcard.
+// The empty selector and "*" match all servers.
+type Selector []Condition
+
+// Condition is a single key=value or key!=value part of a Selector.
+type Condition struct {
+	Key    string
+	Value  string
+	Negate bool
+}
+
+// ParseSelector parses the selector syntax.
+func ParseSelector(s string) (Selector, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "*" {
+		return Selector{}, nil
+	}
+
+	selector := Selector{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		i := strings.Index(part, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid selector condition '%s': expected key=value", part)
+		}
+
+		condition := Condition{Key: strings.TrimSpace(part[:i]), Value: strings.TrimSpace(part[i+1:])}
+		if strings.HasSuffix(condition.Key, "!") {
+			condition.Key = strings.TrimSpace(strings.TrimSuffix(condition.Key, "!"))
+			condition.Negate = true
+		}
+		if condition.Key == "" || condition.Value == "" {
+			return nil, fmt.Errorf("invalid selector condition '%s': expected key=value", part)
+		}
+
+		selector = append(selector, condition)
+	}
+
+	return selector, nil
+}
+
+// Matches reports if all conditions match the server.
+// The groups are the names of the groups that contain the server.
+func (s Selector) Matches(name string, server Server, groups []string) bool {
+	for _, condition := range s {
+		if condition.matches(name, server, groups) == condition.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Condition) matches(name string, server Server, groups []string) bool {
+	switch c.Key {
+	case "name":
+		return util.MatchWildcard(c.Value, name)
+	case "tag":
+		return matchAny(c.Value, server.Tags)
+	case "group":
+		return matchAny(c.Value, groups)
+	case "game":
+		return util.MatchWildcard(c.Value, server.Game)
+	case "dialect":
+		return util.MatchWildcard(c.Value, string(server.Dialect))
+	}
+
+	value, ok := server.Labels[c.Key]
+	return ok && util.MatchWildcard(c.Value, value)
+}
+
+func matchAny(pattern string, values []string) bool {
+	for _, value := range values {
+		if util.MatchWildcard(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
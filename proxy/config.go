@@ -0,0 +1,132 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/server"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// Config of the proxy binary.
+//
+//	{
+//		"listen": ":27016",
+//		"proxy_protocol": "required",
+//		"trusted_proxies": ["10.0.0.0/8"],
+//		"upstream": {"address": "127.0.0.1:27015", "password": "secret", "dialect": "source", "timeout": "5s",
+//			"tls": {"ca_file": "ca.pem", "server_name": "rcon.example.com"}},
+//		"users": [
+//			{"name": "alice", "password": "...", "allow": ["*"], "deny": ["rcon_password *", "quit"]},
+//			{"name": "bob", "password": "...", "allow": ["status", "say *"]}
+//		]
+//	}
+type Config struct {
+	// Listen is the address of the RCON server of the proxy.
+	Listen string `json:"listen"`
+	// ProxyProtocol reads the PROXY protocol header of a load balancer in front of the proxy.
+	ProxyProtocol server.ProxyProtocolMode `json:"proxy_protocol"`
+	// TrustedProxies are the CIDRs or IPs of the load balancers. They are required by the proxy_protocol.
+	TrustedProxies []string       `json:"trusted_proxies"`
+	Upstream       UpstreamConfig `json:"upstream"`
+	Users          []User         `json:"users"`
+}
+
+// UpstreamConfig describes how to connect to the upstream server.
+type UpstreamConfig struct {
+	Address  string         `json:"address"`
+	Password string         `json:"password"`
+	Dialect  client.Dialect `json:"dialect"`
+	// Timeout for a single command, defaults to DefaultTimeout.
+	Timeout util.Duration `json:"timeout"`
+	// TLS connects with RCONS (RCON over TLS) if it is set, e.g. to a server behind stunnel.
+	TLS *client.TLSOptions `json:"tls"`
+}
+
+// DefaultTimeout is used if the upstream has no timeout.
+const DefaultTimeout = 10 * time.Second
+
+// LoadConfig reads and validates the JSON config file.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, fmt.Errorf("parsing config '%s': %w", path, err)
+	}
+
+	return config, config.Validate()
+}
+
+// Validate checks that all required values are set and the user names and passwords are unique.
+func (c Config) Validate() error {
+	if c.Upstream.Address == "" {
+		return fmt.Errorf("upstream: address is missing")
+	}
+	if c.Upstream.Dialect == "" {
+		return fmt.Errorf("upstream: dialect is missing")
+	}
+	switch c.ProxyProtocol {
+	case server.ProxyProtocolOff, server.ProxyProtocolOptional, server.ProxyProtocolRequired:
+	default:
+		return fmt.Errorf("unknown proxy_protocol '%s'", c.ProxyProtocol)
+	}
+	if _, err := server.ParseTrustedProxies(c.TrustedProxies); err != nil {
+		return err
+	}
+	if c.ProxyProtocol != server.ProxyProtocolOff && len(c.TrustedProxies) == 0 {
+		return fmt.Errorf("proxy_protocol requires trusted_proxies")
+	}
+
+	names := map[string]bool{}
+	passwords := map[string]bool{}
+	for i, user := range c.Users {
+		if user.Name == "" {
+			return fmt.Errorf("user %d: name is missing", i)
+		}
+		if user.Password == "" {
+			return fmt.Errorf("user '%s': password is missing", user.Name)
+		}
+		if names[user.Name] {
+			return fmt.Errorf("user '%s': name is not unique", user.Name)
+		}
+		if passwords[user.Password] {
+			return fmt.Errorf("user '%s': password is not unique", user.Name)
+		}
+		names[user.Name] = true
+		passwords[user.Password] = true
+	}
+
+	return nil
+}
+
+// NewUpstream creates the ReconnectingClient for the upstream server.
+// The connection gets established with the first command.
+//
+// Errors:
+// Returns the errors of the TLS options.
+func (c Config) NewUpstream() (*client.ReconnectingClient, error) {
+	timeout := time.Duration(c.Upstream.Timeout)
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	dial := client.TCPDialer(c.Upstream.Address, timeout)
+	if c.Upstream.TLS != nil {
+		config, err := c.Upstream.TLS.Config(c.Upstream.Address)
+		if err != nil {
+			return nil, fmt.Errorf("upstream: %w", err)
+		}
+		dial = client.TLSDialer(c.Upstream.Address, timeout, config)
+	}
+
+	upstream := client.NewReconnectingClient(dial, c.Upstream.Dialect, c.Upstream.Password)
+	upstream.Timeout = timeout
+	return upstream, nil
+}
@@ -0,0 +1,77 @@
+package proxy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon/proxy"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("valid config", func(t *testing.T) {
+		path := writeFile(t, `{
+			"listen": ":27016",
+			"upstream": {"address": "127.0.0.1:27015", "password": "secret", "dialect": "source", "timeout": "3s"},
+			"users": [{"name": "alice", "password": "abc", "allow": ["*"], "deny": ["quit"]}]
+		}`)
+
+		got, err := proxy.LoadConfig(path)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		upstream, err := got.NewUpstream()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if upstream.Timeout != 3*time.Second || upstream.Password != "secret" {
+			t.Errorf("upstream did not match: %+v\n", upstream)
+		}
+		if len(got.Users) != 1 || !got.Users[0].AllowsCommand("status") || got.Users[0].AllowsCommand("quit") {
+			t.Errorf("users did not match: %+v\n", got.Users)
+		}
+	})
+
+	t.Run("upstream tls", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.pem")
+		path := writeFile(t, `{"upstream": {"address": "127.0.0.1:27015", "dialect": "source", "tls": {"ca_file": "`+missing+`"}}}`)
+
+		got, err := proxy.LoadConfig(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Upstream.TLS == nil || got.Upstream.TLS.CAFile != missing {
+			t.Fatalf("tls options did not match: %+v\n", got.Upstream.TLS)
+		}
+		if _, err := got.NewUpstream(); err == nil {
+			t.Error("expected an error for the missing CA file")
+		}
+	})
+
+	t.Run("invalid configs", func(t *testing.T) {
+		configs := map[string]string{
+			"missing address":    `{"upstream": {"dialect": "source"}}`,
+			"missing password":   `{"upstream": {"address": "a:1", "dialect": "source"}, "users": [{"name": "alice"}]}`,
+			"duplicate password": `{"upstream": {"address": "a:1", "dialect": "source"}, "users": [{"name": "alice", "password": "x"}, {"name": "bob", "password": "x"}]}`,
+			"proxy protocol":     `{"upstream": {"address": "a:1", "dialect": "source"}, "proxy_protocol": "v3"}`,
+			"trusted proxy":      `{"upstream": {"address": "a:1", "dialect": "source"}, "proxy_protocol": "required", "trusted_proxies": ["10.0.0.0/33"]}`,
+			"no trusted proxies": `{"upstream": {"address": "a:1", "dialect": "source"}, "proxy_protocol": "optional"}`,
+		}
+		for name, config := range configs {
+			if _, err := proxy.LoadConfig(writeFile(t, config)); err == nil {
+				t.Errorf("expected an error for the %s\n", name)
+			}
+		}
+	})
+}
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
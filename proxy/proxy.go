@@ -0,0 +1,118 @@
+/*
+Package proxy implements a RCON reverse proxy with per-user credentials.
+
+Admins authenticate to the proxy with their own passwords instead of the
+password of the upstream server. Every command gets checked against the
+allow and deny rules of the user and allowed commands are forwarded over a
+single shared connection to the upstream server:
+
+	upstream := client.NewReconnectingClient(client.TCPDialer("127.0.0.1:27015", 5*time.Second), client.DialectSource, "secret")
+	p := proxy.New(upstream, []proxy.User{
+		{Name: "alice", Password: "...", Allow: []string{"*"}, Deny: []string{"rcon_password *"}},
+	}, logger)
+	log.Fatal(p.NewServer(":27016", client.DialectSource).ListenAndServe())
+*/
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/server"
+)
+
+// New is a constructor for the Proxy struct.
+func New(upstream client.Client, users []User, logger *log.Logger) *Proxy {
+	return &Proxy{Upstream: upstream, Users: users, Logger: logger}
+}
+
+// Proxy authenticates users and forwards their allowed commands to the upstream server.
+// It implements the server.Handler interface and its Authenticate method can be used as server.AuthFunc.
+type Proxy struct {
+	// Upstream executes the commands. It has to be authenticated and safe for concurrent use
+	// like the client.ReconnectingClient.
+	Upstream client.Client
+	// Users that can authenticate.
+	Users []User
+	// Logger for the audit log. Nothing is logged if it is nil.
+	Logger *log.Logger
+}
+
+// NewServer returns a server that uses the proxy and listens on the address.
+// The server behaves like servers of the dialect so that the clients of the
+// upstream server can be used unchanged.
+// Password guessing is slowed down and banned by an AuthGuard with the defaults.
+func (p *Proxy) NewServer(addr string, dialect client.Dialect) *server.Server {
+	guard := &server.AuthGuard{}
+	if p.Logger != nil {
+		guard.OnEvent = server.LogAuthEvents(p.Logger)
+	}
+
+	return &server.Server{
+		Addr:                addr,
+		Handler:             p,
+		Auth:                p.Authenticate,
+		AuthGuard:           guard,
+		SendPreAuthResponse: dialect.MirrorsResponseValue(),
+		MirrorEmptyResponse: dialect.MirrorsResponseValue(),
+		ErrorLog:            p.Logger,
+	}
+}
+
+// Authenticate returns the name of the user with the password.
+func (p *Proxy) Authenticate(s *server.Session, password string) (string, bool) {
+	user, ok := lookupUser(p.Users, password)
+	if !ok {
+		p.audit(s, "", "authentication failed")
+		return "", false
+	}
+
+	p.audit(s, user.Name, "authenticated")
+	return user.Name, true
+}
+
+// ServeRCON checks and forwards the command.
+// Denied and failed commands get a response starting with "grcon-proxy:".
+func (p *Proxy) ServeRCON(w server.ResponseWriter, r *server.Request) {
+	user, ok := p.user(r.Session.User)
+	if !ok {
+		// the user got removed after the authentication.
+		p.audit(r.Session, r.Session.User, fmt.Sprintf("denied command=%q", r.Command))
+		io.WriteString(w, "grcon-proxy: unknown user")
+		return
+	}
+
+	if !user.AllowsCommand(r.Command) {
+		p.audit(r.Session, user.Name, fmt.Sprintf("denied command=%q", r.Command))
+		io.WriteString(w, "grcon-proxy: command not allowed")
+		return
+	}
+
+	response, err := p.Upstream.Exec(r.Command)
+	if err != nil {
+		p.audit(r.Session, user.Name, fmt.Sprintf("failed command=%q error=%q", r.Command, err.Error()))
+		io.WriteString(w, "grcon-proxy: upstream error: "+err.Error())
+		return
+	}
+
+	p.audit(r.Session, user.Name, fmt.Sprintf("command=%q", r.Command))
+	w.Write(response)
+}
+
+func (p *Proxy) user(name string) (User, bool) {
+	for _, user := range p.Users {
+		if user.Name == name {
+			return user, true
+		}
+	}
+	return User{}, false
+}
+
+// audit writes an entry to the audit log.
+func (p *Proxy) audit(s *server.Session, user, msg string) {
+	if p.Logger != nil {
+		p.Logger.Printf("addr=%s user=%q %s", s.RemoteAddr, user, msg)
+	}
+}
@@ -0,0 +1,152 @@
+package proxy_test
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/proxy"
+)
+
+// MockClient echos the commands or returns the Err.
+type MockClient struct {
+	Err error
+
+	mutex    sync.Mutex
+	Executed []string
+}
+
+func (mc *MockClient) Auth(password string) error {
+	return nil
+}
+
+func (mc *MockClient) Exec(cmd string) ([]byte, error) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	mc.Executed = append(mc.Executed, cmd)
+	if mc.Err != nil {
+		return []byte{}, mc.Err
+	}
+	return []byte("echo: " + cmd), nil
+}
+
+// startProxy serves the proxy on a loopback address and returns the address.
+func startProxy(t *testing.T, p *proxy.Proxy, dialect client.Dialect) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := p.NewServer("", dialect)
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+
+	return l.Addr().String()
+}
+
+func dial(t *testing.T, addr string, dialect client.Dialect, password string) (client.Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	t.Cleanup(func() { conn.Close() })
+
+	id := grcon.PacketId(0)
+	c, err := client.NewDialectClient(dialect, grcon.NewRemoteConsole(conn), func() grcon.PacketId {
+		id++
+		return id
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, c.Auth(password)
+}
+
+func TestProxy(t *testing.T) {
+	users := []proxy.User{
+		{Name: "alice", Password: "alice-secret", Allow: []string{"*"}, Deny: []string{"rcon_password *"}},
+		{Name: "bob", Password: "bob-secret", Allow: []string{"status", "say *"}},
+	}
+
+	t.Run("rules", func(t *testing.T) {
+		tests := []struct {
+			password string
+			command  string
+			expect   string
+		}{
+			{password: "alice-secret", command: "changelevel cp_badlands", expect: "echo: changelevel cp_badlands"},
+			{password: "alice-secret", command: "rcon_password 123", expect: "grcon-proxy: command not allowed"},
+			{password: "bob-secret", command: "say hello", expect: "echo: say hello"},
+			{password: "bob-secret", command: "kick alice", expect: "grcon-proxy: command not allowed"},
+			{password: "alice-secret", command: "RCON_PASSWORD 123", expect: "grcon-proxy: command not allowed"},
+			{password: "alice-secret", command: "rcon_password\t123", expect: "grcon-proxy: command not allowed"},
+			{password: "alice-secret", command: "say hi; rcon_password 123", expect: "grcon-proxy: command not allowed"},
+			{password: "bob-secret", command: "say hi; rcon_password 123", expect: "grcon-proxy: command not allowed"},
+			{password: "bob-secret", command: "say hi\nkick alice", expect: "grcon-proxy: command not allowed"},
+			{password: "bob-secret", command: "say hi\rkick alice", expect: "grcon-proxy: command not allowed"},
+			{password: "bob-secret", command: "SAY  hello", expect: "echo: SAY  hello"},
+			{password: "alice-secret", command: "/rcon_password 123", expect: "grcon-proxy: command not allowed"},
+			{password: "alice-secret", command: `"rcon_password" 123`, expect: "grcon-proxy: command not allowed"},
+			{password: "alice-secret", command: `"RCON_PASSWORD"123`, expect: "grcon-proxy: command not allowed"},
+			{password: "bob-secret", command: "/say hello", expect: "echo: /say hello"},
+		}
+
+		upstream := &MockClient{}
+		for _, dialect := range []client.Dialect{client.DialectSource, client.DialectMinecraft} {
+			addr := startProxy(t, proxy.New(upstream, users, nil), dialect)
+
+			for _, test := range tests {
+				c, err := dial(t, addr, dialect, test.password)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := c.Exec(test.command)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != test.expect {
+					t.Errorf("response of %q with dialect %s:\nexpected: %s\ngot: %s\n", test.command, dialect, test.expect, string(got))
+				}
+			}
+		}
+
+		for _, cmd := range upstream.Executed {
+			if lower := strings.ToLower(cmd); strings.Contains(lower, "rcon_password") || strings.Contains(lower, "kick") {
+				t.Errorf("denied command was forwarded: %s\n", cmd)
+			}
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		addr := startProxy(t, proxy.New(&MockClient{}, users, nil), client.DialectSource)
+
+		_, err := dial(t, addr, client.DialectSource, "rcon-password")
+		if _, ok := err.(client.AuthFailedError); !ok {
+			t.Errorf("error did not match:\nexpected: %T\ngot: %v\n", client.AuthFailedError{}, err)
+		}
+	})
+
+	t.Run("upstream error", func(t *testing.T) {
+		upstream := &MockClient{Err: errors.New("connection refused")}
+		addr := startProxy(t, proxy.New(upstream, users, nil), client.DialectMinecraft)
+
+		c, err := dial(t, addr, client.DialectMinecraft, "alice-secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.Exec("status")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := "grcon-proxy: upstream error: connection refused"
+		if string(got) != expect {
+			t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", expect, string(got))
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package proxy
+
+import (
+	"crypto/subtle"
+
+	"github.com/hamburghammer/grcon/util"
+)
+
+// User is an admin that authenticates to the proxy with an own password.
+type User struct {
+	// Name identifies the user in the audit log.
+	Name string `json:"name"`
+	// Password the user authenticates with.
+	Password string `json:"password"`
+	// Allow is a list of patterns for util.MatchCommand of the allowed commands.
+	Allow []string `json:"allow"`
+	// Deny is a list of patterns of forbidden commands. It takes precedence over Allow.
+	Deny []string `json:"deny"`
+}
+
+// AllowsCommand reports if a pattern of Allow and none of Deny matches the command.
+func (u User) AllowsCommand(cmd string) bool {
+	for _, pattern := range u.Deny {
+		if util.MatchCommand(pattern, cmd) {
+			return false
+		}
+	}
+	for _, pattern := range u.Allow {
+		if util.MatchCommand(pattern, cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupUser returns the user with the password.
+// All passwords are compared in constant time.
+func lookupUser(users []User, password string) (User, bool) {
+	if password == "" {
+		return User{}, false
+	}
+
+	var found User
+	ok := false
+	for _, user := range users {
+		if subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) == 1 && !ok {
+			found = user
+			ok = true
+		}
+	}
+	return found, ok
+}
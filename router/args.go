@@ -0,0 +1,142 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArgType defines how an argument gets parsed.
+type ArgType int
+
+const (
+	// String is a single word or a quoted string like "hello world".
+	String ArgType = iota
+	// Int is a base 10 integer.
+	Int
+	// Duration is a time.Duration like 90s or 1h30m.
+	Duration
+	// Text takes the rest of the command and can only be the last argument.
+	Text
+)
+
+// String returns the name of the type that is used in the usage errors.
+func (t ArgType) String() string {
+	switch t {
+	case String:
+		return "string"
+	case Int:
+		return "int"
+	case Duration:
+		return "duration"
+	case Text:
+		return "text"
+	default:
+		return fmt.Sprintf("ArgType(%d)", int(t))
+	}
+}
+
+// Arg is an argument of a command.
+type Arg struct {
+	// Name of the argument in the usage and to get its value from the Request.
+	Name string
+	// Type of the argument.
+	Type ArgType
+	// Optional arguments can be omitted. They have to follow the required arguments.
+	Optional bool
+}
+
+// usage returns the argument like <name>, [name] or <name...>.
+func (a Arg) usage() string {
+	name := a.Name
+	if a.Type == Text {
+		name += "..."
+	}
+	if a.Optional {
+		return "[" + name + "]"
+	}
+	return "<" + name + ">"
+}
+
+// parse converts the word into the value of the type.
+func (a Arg) parse(word string) (interface{}, error) {
+	switch a.Type {
+	case Int:
+		value, err := strconv.Atoi(word)
+		if err != nil {
+			return nil, fmt.Errorf("%s has to be an int but got %q", a.Name, word)
+		}
+		return value, nil
+	case Duration:
+		value, err := time.ParseDuration(word)
+		if err != nil {
+			return nil, fmt.Errorf("%s has to be a duration like 90s or 1h30m but got %q", a.Name, word)
+		}
+		return value, nil
+	default:
+		return word, nil
+	}
+}
+
+// ErrUnterminatedQuote is returned by Split for a quoted string without the closing quote.
+var ErrUnterminatedQuote = errors.New("unterminated quoted string")
+
+// Split splits the command into words like a shell.
+// Words are separated by whitespace and can be quoted with double or single quotes.
+// Inside double quotes a backslash escapes the next character.
+func Split(command string) ([]string, error) {
+	var words []string
+	for pos := 0; ; {
+		word, next, ok, err := nextWord(command, pos)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return words, nil
+		}
+		words = append(words, word)
+		pos = next
+	}
+}
+
+// nextWord returns the word that starts at or after the position and the position after it.
+// ok is false if only whitespace is left.
+func nextWord(command string, pos int) (word string, next int, ok bool, err error) {
+	var b strings.Builder
+	var quote rune
+	escaped := false
+
+	for i, r := range command[pos:] {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			b.WriteRune(r)
+		case r == '"' || r == '\'':
+			ok = true
+			quote = r
+		case isSpace(r):
+			if ok {
+				return b.String(), pos + i, true, nil
+			}
+		default:
+			ok = true
+			b.WriteRune(r)
+		}
+	}
+	if quote != 0 || escaped {
+		return "", len(command), false, ErrUnterminatedQuote
+	}
+	return b.String(), len(command), ok, nil
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
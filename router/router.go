@@ -0,0 +1,331 @@
+/*
+Package router dispatches the commands of a RCON server to handlers with typed arguments.
+
+Services that embed a server.Server get an admin surface without parsing the
+commands by hand. Every command has a name, aliases, typed arguments, a help
+text and the permission level that is required to run it:
+
+	rt := router.New()
+	rt.Level = func(s *server.Session) router.Level {
+		if s.User == "admin" {
+			return 10
+		}
+		return 0
+	}
+	rt.Handle(router.Command{
+		Name:    "kick",
+		Aliases: []string{"k"},
+		Args:    []router.Arg{{Name: "user", Type: router.String}, {Name: "reason", Type: router.Text, Optional: true}},
+		Help:    "Disconnects the user.",
+		Level:   10,
+		Handler: func(w server.ResponseWriter, r *router.Request) {
+			sessions.Kick(r.String("user"), r.String("reason"))
+			fmt.Fprintf(w, "Kicked %s", r.String("user"))
+		},
+	})
+	srv := &server.Server{Addr: ":27015", Auth: server.PasswordAuth("secret"), Handler: rt}
+
+The help command lists the commands the session is allowed to run and shows
+the usage of a single command. Invalid arguments are answered with the reason
+and the usage of the command.
+*/
+package router
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamburghammer/grcon/server"
+)
+
+// Level is a permission level. A session can run the commands with a level lower than or equal to its own.
+type Level int
+
+// HandlerFunc responds to a command with parsed arguments.
+type HandlerFunc func(w server.ResponseWriter, r *Request)
+
+// Command is a command of the Router.
+type Command struct {
+	// Name of the command. Names and aliases are case insensitive.
+	Name string
+	// Aliases are alternative names like shortcuts.
+	Aliases []string
+	// Args of the command in their order.
+	Args []Arg
+	// Help is the description in the help output.
+	Help string
+	// Level is the permission level that is required to run the command.
+	Level Level
+	// Handler runs the command.
+	Handler HandlerFunc
+}
+
+// Usage returns the name with the arguments like "kick <user> [reason...]".
+func (c Command) Usage() string {
+	usage := c.Name
+	for _, arg := range c.Args {
+		usage += " " + arg.usage()
+	}
+	return usage
+}
+
+// validate checks the definition of the command.
+func (c Command) validate() error {
+	if c.Name == "" || strings.ContainsAny(c.Name, " \t\r\n\"'") {
+		return fmt.Errorf("invalid name %q", c.Name)
+	}
+	if c.Handler == nil {
+		return fmt.Errorf("command %q has no handler", c.Name)
+	}
+
+	names := map[string]bool{}
+	optional := false
+	for i, arg := range c.Args {
+		if arg.Name == "" || names[arg.Name] {
+			return fmt.Errorf("command %q has an invalid or duplicated argument name %q", c.Name, arg.Name)
+		}
+		names[arg.Name] = true
+		if arg.Type < String || arg.Type > Text {
+			return fmt.Errorf("argument %q of command %q has the invalid type %s", arg.Name, c.Name, arg.Type)
+		}
+		if optional && !arg.Optional {
+			return fmt.Errorf("required argument %q of command %q follows an optional argument", arg.Name, c.Name)
+		}
+		if arg.Type == Text && i != len(c.Args)-1 {
+			return fmt.Errorf("text argument %q of command %q is not the last argument", arg.Name, c.Name)
+		}
+		optional = arg.Optional
+	}
+	return nil
+}
+
+// Request is a command with the parsed arguments.
+type Request struct {
+	*server.Request
+	// Name is the name or alias the command was called with.
+	Name string
+	// Args are the values of the arguments that were given by their names.
+	// The values are strings, ints or time.Durations depending on the ArgType.
+	Args map[string]interface{}
+}
+
+// Has reports if the argument was given.
+func (r *Request) Has(name string) bool {
+	_, ok := r.Args[name]
+	return ok
+}
+
+// String returns the value of a String or Text argument or "" if it was not given.
+func (r *Request) String(name string) string {
+	value, _ := r.Args[name].(string)
+	return value
+}
+
+// Int returns the value of an Int argument or 0 if it was not given.
+func (r *Request) Int(name string) int {
+	value, _ := r.Args[name].(int)
+	return value
+}
+
+// Duration returns the value of a Duration argument or 0 if it was not given.
+func (r *Request) Duration(name string) time.Duration {
+	value, _ := r.Args[name].(time.Duration)
+	return value
+}
+
+// New is a constructor for the Router struct.
+func New() *Router {
+	return &Router{}
+}
+
+// Router implements the server.Handler interface and dispatches the commands by their names.
+// The zero value is ready to use. It is safe to add commands while serving.
+type Router struct {
+	// Level returns the permission level of a session. All sessions have the level 0 if it is nil.
+	Level func(s *server.Session) Level
+
+	mutex    sync.RWMutex
+	commands map[string]*Command
+}
+
+// Handle adds the command. The built-in help command can be replaced by a command named "help".
+// It panics if the definition of the command is invalid or the name or an alias is already used.
+func (rt *Router) Handle(c Command) {
+	if err := c.validate(); err != nil {
+		panic("grcon-router: " + err.Error())
+	}
+
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	if rt.commands == nil {
+		rt.commands = map[string]*Command{}
+	}
+	names := append([]string{c.Name}, c.Aliases...)
+	for _, name := range names {
+		if _, ok := rt.commands[strings.ToLower(name)]; ok {
+			panic(fmt.Sprintf("grcon-router: command %q is already registered", name))
+		}
+	}
+	for _, name := range names {
+		rt.commands[strings.ToLower(name)] = &c
+	}
+}
+
+// ServeRCON parses the command and calls its handler.
+// Unknown commands and invalid arguments are answered with an error message.
+// Commands above the level of the session are answered like unknown commands,
+// so that they cannot be discovered, the same as they are hidden from the help.
+func (rt *Router) ServeRCON(w server.ResponseWriter, r *server.Request) {
+	name, next, ok, err := nextWord(r.Command, 0)
+	if err != nil {
+		fmt.Fprintf(w, "invalid command: %s", err.Error())
+		return
+	}
+	if !ok {
+		name = "help"
+	}
+
+	level := rt.level(r.Session)
+	c, ok := rt.lookup(name)
+	if !ok && strings.ToLower(name) == "help" {
+		rt.help(w, r, level, next)
+		return
+	}
+	if !ok || c.Level > level {
+		unknownCommand(w, name)
+		return
+	}
+
+	args, err := parseArgs(c.Args, r.Command, next)
+	if err != nil {
+		fmt.Fprintf(w, "invalid arguments: %s\nusage: %s", err.Error(), c.Usage())
+		return
+	}
+
+	c.Handler(w, &Request{Request: r, Name: name, Args: args})
+}
+
+// unknownCommand writes the answer to unknown commands and commands above the level of the session.
+func unknownCommand(w server.ResponseWriter, name string) {
+	fmt.Fprintf(w, "unknown command %q, type \"help\" for a list of commands", name)
+}
+
+// parseArgs parses the arguments of the command starting at the position.
+func parseArgs(defs []Arg, command string, pos int) (map[string]interface{}, error) {
+	args := map[string]interface{}{}
+	for _, def := range defs {
+		if def.Type == Text {
+			text := strings.TrimSpace(command[pos:])
+			if text == "" {
+				break
+			}
+			args[def.Name] = text
+			return args, nil
+		}
+
+		word, next, ok, err := nextWord(command, pos)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		pos = next
+
+		value, err := def.parse(word)
+		if err != nil {
+			return nil, err
+		}
+		args[def.Name] = value
+	}
+
+	for _, def := range defs {
+		if _, ok := args[def.Name]; !ok && !def.Optional {
+			return nil, fmt.Errorf("missing %s", def.usage())
+		}
+	}
+	if strings.TrimSpace(command[pos:]) != "" {
+		return nil, fmt.Errorf("too many arguments")
+	}
+	return args, nil
+}
+
+// help writes the usage of the commands that the session can run or the details of a single command.
+func (rt *Router) help(w server.ResponseWriter, r *server.Request, level Level, pos int) {
+	name, _, ok, _ := nextWord(r.Command, pos)
+	if ok {
+		c, found := rt.lookup(name)
+		if !found && strings.ToLower(name) == "help" {
+			c, found = helpCommand(), true
+		}
+		if !found || c.Level > level {
+			unknownCommand(w, name)
+			return
+		}
+
+		fmt.Fprintf(w, "usage: %s", c.Usage())
+		if len(c.Aliases) > 0 {
+			fmt.Fprintf(w, "\naliases: %s", strings.Join(c.Aliases, ", "))
+		}
+		if c.Help != "" {
+			fmt.Fprintf(w, "\n%s", c.Help)
+		}
+		return
+	}
+
+	commands := []Command{helpCommand()}
+	rt.mutex.RLock()
+	for key, c := range rt.commands {
+		if key == strings.ToLower(c.Name) && c.Level <= level {
+			if key == "help" {
+				commands[0] = *c
+				continue
+			}
+			commands = append(commands, *c)
+		}
+	}
+	rt.mutex.RUnlock()
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+
+	for i, c := range commands {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		io.WriteString(w, c.Usage())
+		if c.Help != "" {
+			io.WriteString(w, " - "+c.Help)
+		}
+	}
+}
+
+// helpCommand is the definition of the built-in help command for the help output.
+func helpCommand() Command {
+	return Command{
+		Name: "help",
+		Args: []Arg{{Name: "command", Type: String, Optional: true}},
+		Help: "Lists the commands or shows the usage of a command.",
+	}
+}
+
+func (rt *Router) lookup(name string) (Command, bool) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+
+	c, ok := rt.commands[strings.ToLower(name)]
+	if !ok {
+		return Command{}, false
+	}
+	return *c, true
+}
+
+func (rt *Router) level(s *server.Session) Level {
+	if rt.Level == nil {
+		return 0
+	}
+	return rt.Level(s)
+}
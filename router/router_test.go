@@ -0,0 +1,160 @@
+package router_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hamburghammer/grcon/router"
+	"github.com/hamburghammer/grcon/server"
+)
+
+func newTestRouter() *router.Router {
+	rt := router.New()
+	rt.Level = func(s *server.Session) router.Level {
+		if s.User == "admin" {
+			return 10
+		}
+		return 0
+	}
+	rt.Handle(router.Command{
+		Name:    "kick",
+		Aliases: []string{"k"},
+		Args:    []router.Arg{{Name: "user", Type: router.String}, {Name: "reason", Type: router.Text, Optional: true}},
+		Help:    "Disconnects the user.",
+		Level:   10,
+		Handler: func(w server.ResponseWriter, r *router.Request) {
+			fmt.Fprintf(w, "%s user=%s reason=%s", r.Name, r.String("user"), r.String("reason"))
+		},
+	})
+	rt.Handle(router.Command{
+		Name: "cache-flush",
+		Args: []router.Arg{{Name: "shards", Type: router.Int}, {Name: "ttl", Type: router.Duration, Optional: true}},
+		Help: "Flushes the cache.",
+		Handler: func(w server.ResponseWriter, r *router.Request) {
+			fmt.Fprintf(w, "shards=%d ttl=%s has_ttl=%t", r.Int("shards"), r.Duration("ttl"), r.Has("ttl"))
+		},
+	})
+	return rt
+}
+
+func serve(rt *router.Router, user, command string) string {
+	var buf bytes.Buffer
+	rt.ServeRCON(&buf, &server.Request{Command: command, Session: &server.Session{User: user, Authenticated: true}})
+	return buf.String()
+}
+
+func TestRouter(t *testing.T) {
+	rt := newTestRouter()
+
+	tests := []struct {
+		name    string
+		user    string
+		command string
+		expect  string
+	}{
+		{name: "typed arguments", command: "cache-flush 3 1m30s", expect: "shards=3 ttl=1m30s has_ttl=true"},
+		{name: "optional argument", command: "cache-flush 3", expect: "shards=3 ttl=0s has_ttl=false"},
+		{name: "alias and text", user: "admin", command: `K "bob smith" don't spam`, expect: "K user=bob smith reason=don't spam"},
+		{name: "invalid int", command: "cache-flush three", expect: "invalid arguments: shards has to be an int but got \"three\"\nusage: cache-flush <shards> [ttl]"},
+		{name: "invalid duration", command: "cache-flush 3 soon", expect: "invalid arguments: ttl has to be a duration like 90s or 1h30m but got \"soon\"\nusage: cache-flush <shards> [ttl]"},
+		{name: "missing argument", command: "cache-flush", expect: "invalid arguments: missing <shards>\nusage: cache-flush <shards> [ttl]"},
+		{name: "too many arguments", command: "cache-flush 3 1m x", expect: "invalid arguments: too many arguments\nusage: cache-flush <shards> [ttl]"},
+		{name: "unterminated quote", user: "admin", command: `kick "bob`, expect: "invalid arguments: unterminated quoted string\nusage: kick <user> [reason...]"},
+		{name: "unknown command", command: "reboot", expect: `unknown command "reboot", type "help" for a list of commands`},
+		{name: "permission denied", command: "kick bob", expect: `unknown command "kick", type "help" for a list of commands`},
+		{
+			name:    "help",
+			command: "help",
+			expect:  "cache-flush <shards> [ttl] - Flushes the cache.\nhelp [command] - Lists the commands or shows the usage of a command.",
+		},
+		{
+			name:    "help with permission",
+			user:    "admin",
+			command: "",
+			expect:  "cache-flush <shards> [ttl] - Flushes the cache.\nhelp [command] - Lists the commands or shows the usage of a command.\nkick <user> [reason...] - Disconnects the user.",
+		},
+		{name: "help of command", user: "admin", command: "help k", expect: "usage: kick <user> [reason...]\naliases: k\nDisconnects the user."},
+		{name: "help of hidden command", command: "help kick", expect: `unknown command "kick", type "help" for a list of commands`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if got := serve(rt, test.user, test.command); got != test.expect {
+				t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestRouter_Handle(t *testing.T) {
+	handler := func(w server.ResponseWriter, r *router.Request) {}
+
+	invalid := map[string]router.Command{
+		"empty name":          {Handler: handler},
+		"name with space":     {Name: "cache flush", Handler: handler},
+		"missing handler":     {Name: "flush"},
+		"duplicated name":     {Name: "KICK", Handler: handler},
+		"duplicated alias":    {Name: "remove", Aliases: []string{"k"}, Handler: handler},
+		"duplicated argument": {Name: "a", Args: []router.Arg{{Name: "x"}, {Name: "x"}}, Handler: handler},
+		"required after optional": {Name: "b", Args: []router.Arg{
+			{Name: "x", Optional: true}, {Name: "y"},
+		}, Handler: handler},
+		"text not last": {Name: "c", Args: []router.Arg{{Name: "x", Type: router.Text}, {Name: "y"}}, Handler: handler},
+	}
+	for name, c := range invalid {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			rt := newTestRouter()
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			rt.Handle(c)
+		})
+	}
+
+	t.Run("replace help", func(t *testing.T) {
+		rt := newTestRouter()
+		rt.Handle(router.Command{Name: "help", Handler: func(w server.ResponseWriter, r *router.Request) {
+			w.Write([]byte("custom help"))
+		}})
+		if got := serve(rt, "", "help"); got != "custom help" {
+			t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", "custom help", got)
+		}
+		if got := serve(rt, "", "help kick"); !strings.HasPrefix(got, "invalid arguments") {
+			t.Errorf("expected the usage error of the custom help\ngot: %s\n", got)
+		}
+	})
+}
+
+func TestSplit(t *testing.T) {
+	tests := map[string][]string{
+		`kick bob`:                {"kick", "bob"},
+		"  say\t hello  ":         {"say", "hello"},
+		`say "hello world"`:       {"say", "hello world"},
+		`say 'it "works"'`:        {"say", `it "works"`},
+		`say "a \"quoted\" word"`: {"say", `a "quoted" word`},
+		`give "bob"smith stone`:   {"give", "bobsmith", "stone"},
+		`set motd ""`:             {"set", "motd", ""},
+		``:                        nil,
+	}
+	for command, expect := range tests {
+		got, err := router.Split(command)
+		if err != nil {
+			t.Errorf("splitting %q failed: %s\n", command, err.Error())
+			continue
+		}
+		if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", expect) {
+			t.Errorf("words of %q did not match:\nexpected: %q\ngot: %q\n", command, expect, got)
+		}
+	}
+
+	for _, invalid := range []string{`say "hello`, `say 'hello`, `say "hello\`} {
+		if _, err := router.Split(invalid); err != router.ErrUnterminatedQuote {
+			t.Errorf("expected ErrUnterminatedQuote for %q but got %v\n", invalid, err)
+		}
+	}
+}
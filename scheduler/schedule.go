@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the activation times of a job.
+type Schedule interface {
+	// Next returns the first activation time after t or the zero time if there is none.
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule parses a cron expression with the five fields
+// minute, hour, day of month, month and day of week:
+//
+//	*/15 * * * *     every 15 minutes
+//	0 4 * * mon-fri  at 04:00 on weekdays
+//	30 3 1,15 * *    at 03:30 on the 1st and 15th
+//
+// Fields support lists, ranges, steps and the english names of months and days.
+// If both day fields are restricted, a day matches if one of them matches like in the classic cron.
+// The macros @yearly, @monthly, @weekly, @daily, @hourly and @every <duration> are supported as well.
+// The times are interpreted in the location of the time passed to Next.
+func ParseSchedule(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if strings.HasPrefix(expr, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule '%s': %w", expr, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid schedule '%s': interval has to be positive", expr)
+		}
+		return everySchedule(interval), nil
+	}
+
+	switch expr {
+	case "@yearly", "@annually":
+		expr = "0 0 1 1 *"
+	case "@monthly":
+		expr = "0 0 1 * *"
+	case "@weekly":
+		expr = "0 0 * * 0"
+	case "@daily", "@midnight":
+		expr = "0 0 * * *"
+	case "@hourly":
+		expr = "0 * * * *"
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule '%s': expected 5 fields but got %d", expr, len(fields))
+	}
+
+	var schedule cronSchedule
+	var err error
+	if schedule.minutes, _, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minute in '%s': %w", expr, err)
+	}
+	if schedule.hours, _, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hour in '%s': %w", expr, err)
+	}
+	if schedule.days, schedule.anyDay, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid day of month in '%s': %w", expr, err)
+	}
+	if schedule.months, _, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("invalid month in '%s': %w", expr, err)
+	}
+	if schedule.weekdays, schedule.anyWeekday, err = parseField(fields[4], 0, 7, weekdayNames); err != nil {
+		return nil, fmt.Errorf("invalid day of week in '%s': %w", expr, err)
+	}
+	// 7 is an alias for sunday.
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays |= 1
+	}
+
+	return schedule, nil
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// parseField parses a comma separated list of values, ranges and steps into a bit set.
+// The bool reports if the field is a single star.
+func parseField(field string, min, max int, names map[string]int) (uint64, bool, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("invalid step '%s'", part[i+1:])
+			}
+			part = part[:i]
+		}
+
+		start, end := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			i := strings.Index(part, "-")
+			var err error
+			if start, err = parseValue(part[:i], min, max, names); err != nil {
+				return 0, false, err
+			}
+			if end, err = parseValue(part[i+1:], min, max, names); err != nil {
+				return 0, false, err
+			}
+			if start > end {
+				return 0, false, fmt.Errorf("invalid range '%s'", part)
+			}
+		default:
+			value, err := parseValue(part, min, max, names)
+			if err != nil {
+				return 0, false, err
+			}
+			start = value
+			// a single value with a step runs until the end like in other cron implementations.
+			end = value
+			if step > 1 {
+				end = max
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, field == "*", nil
+}
+
+func parseValue(s string, min, max int, names map[string]int) (int, error) {
+	if value, ok := names[strings.ToLower(s)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", s)
+	}
+	if value < min || value > max {
+		return 0, fmt.Errorf("value %d is out of the range %d-%d", value, min, max)
+	}
+	return value, nil
+}
+
+// cronSchedule holds the allowed values of the fields as bit sets.
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays uint64
+	anyDay, anyWeekday                     bool
+}
+
+// maxSearch limits the search for the next activation for expressions like "0 0 30 2 *" that never match.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// Next returns the first matching minute after t.
+func (cs cronSchedule) Next(t time.Time) time.Time {
+	limit := t.Add(maxSearch)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for t.Before(limit) {
+		switch {
+		case cs.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !cs.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case cs.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case cs.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (cs cronSchedule) matchesDay(t time.Time) bool {
+	day := cs.days&(1<<uint(t.Day())) != 0
+	weekday := cs.weekdays&(1<<uint(t.Weekday())) != 0
+
+	if cs.anyDay || cs.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// everySchedule activates in a fixed interval.
+type everySchedule time.Duration
+
+func (es everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(es))
+}
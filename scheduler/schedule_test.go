@@ -0,0 +1,55 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon/scheduler"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// Monday, 2026-03-02 10:17:30 UTC
+	start := time.Date(2026, 3, 2, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr   string
+		expect time.Time
+	}{
+		{expr: "* * * * *", expect: time.Date(2026, 3, 2, 10, 18, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", expect: time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)},
+		{expr: "0 4 * * *", expect: time.Date(2026, 3, 3, 4, 0, 0, 0, time.UTC)},
+		{expr: "0 4 * * sat,sun", expect: time.Date(2026, 3, 7, 4, 0, 0, 0, time.UTC)},
+		{expr: "30 3 1,15 * *", expect: time.Date(2026, 3, 15, 3, 30, 0, 0, time.UTC)},
+		{expr: "0 0 1 jan *", expect: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 12 * * 7", expect: time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)},
+		{expr: "5-10/5 10-11 * * mon-fri", expect: time.Date(2026, 3, 2, 11, 5, 0, 0, time.UTC)},
+		// day of month or day of week
+		{expr: "0 0 13 * fri", expect: time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{expr: "@hourly", expect: time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)},
+		{expr: "@daily", expect: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{expr: "@every 90s", expect: time.Date(2026, 3, 2, 10, 19, 0, 0, time.UTC)},
+		// never matches
+		{expr: "0 0 30 2 *", expect: time.Time{}},
+	}
+
+	for _, test := range tests {
+		schedule, err := scheduler.ParseSchedule(test.expr)
+		if err != nil {
+			t.Errorf("parsing %q failed: %s\n", test.expr, err.Error())
+			continue
+		}
+
+		got := schedule.Next(start)
+		if !got.Equal(test.expect) {
+			t.Errorf("next activation of %q did not match:\nexpected: %s\ngot: %s\n", test.expr, test.expect, got)
+		}
+	}
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "* * * * foo", "@every -1s", "@every often"} {
+		if _, err := scheduler.ParseSchedule(expr); err == nil {
+			t.Errorf("expected an error for %q\n", expr)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+/*
+Package scheduler runs commands on RCON servers on cron schedules.
+
+Every Job runs its command sequence on all of its servers. A job never
+overlaps with itself: an activation is skipped while the previous run is
+still going. Jitter spreads the runs of many jobs and servers and the
+MissedRunPolicy decides what happens with activations that were missed
+because the scheduler was not running or the system was suspended.
+
+	s := scheduler.New(clients, []scheduler.Job{
+		{Name: "save", Schedule: schedule, Servers: []string{"survival"}, Commands: []string{"save-all"}},
+	}, logger)
+	err := s.Run(ctx)
+*/
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/fleet"
+)
+
+// MissedRunPolicy decides what happens with missed activations.
+type MissedRunPolicy string
+
+// Missed run policies.
+const (
+	// MissedRunSkip drops missed activations. It is the default.
+	MissedRunSkip MissedRunPolicy = "skip"
+	// MissedRunOnce runs the job once for all missed activations.
+	MissedRunOnce MissedRunPolicy = "run_once"
+)
+
+// DefaultMissedRunGrace is the lateness after which an activation counts as missed.
+const DefaultMissedRunGrace = time.Minute
+
+// Job is a command sequence that runs on a schedule.
+type Job struct {
+	// Name identifies the job in the logs and the state file. It has to be unique.
+	Name     string
+	Schedule Schedule
+	// Servers are the names of the clients to run the commands on.
+	Servers []string
+	// Commands are executed one after another on every server.
+	// The sequence stops on a server after the first error.
+	Commands []string
+	// Jitter delays every activation by a random duration up to the jitter.
+	Jitter time.Duration
+	// MissedRun is the policy for missed activations.
+	MissedRun MissedRunPolicy
+	// Timeout for a single command on a single server, defaults to fleet.DefaultTimeout.
+	Timeout time.Duration
+	// Output receives the responses of the commands. They are logged with the Logger if it is nil.
+	Output io.Writer
+}
+
+// New is a constructor for the Scheduler struct.
+func New(clients map[string]client.Client, jobs []Job, logger *log.Logger) *Scheduler {
+	return &Scheduler{Clients: clients, Jobs: jobs, Logger: logger, MissedRunGrace: DefaultMissedRunGrace}
+}
+
+// Scheduler runs the jobs.
+type Scheduler struct {
+	// Clients maps the server names to the clients.
+	// The clients should be long-lived and safe for concurrent use like the client.ReconnectingClient.
+	Clients map[string]client.Client
+	Jobs    []Job
+	// Logger for the runs of the jobs. Nothing is logged if it is nil.
+	Logger *log.Logger
+	// StatePath is the path of a JSON file that stores the last activation of every job.
+	// It is needed to detect activations that were missed while the scheduler was not running.
+	StatePath string
+	// MissedRunGrace is the lateness after which an activation counts as missed.
+	MissedRunGrace time.Duration
+
+	mutex   sync.Mutex
+	running map[string]bool
+	wg      sync.WaitGroup
+}
+
+// Run runs the jobs until the context is done and waits for the running jobs to finish.
+// Returns an error if the jobs are invalid or the state can not be read.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	state, err := s.loadState()
+	if err != nil {
+		return err
+	}
+	s.running = map[string]bool{}
+	defer s.wg.Wait()
+
+	now := time.Now()
+	next := make([]time.Time, len(s.Jobs))
+	fire := make([]time.Time, len(s.Jobs))
+	for i, job := range s.Jobs {
+		if last, ok := state[job.Name]; ok && job.MissedRun == MissedRunOnce {
+			if missed := job.Schedule.Next(last); !missed.IsZero() && missed.Before(now) {
+				s.logf("job=%q running missed activation of %s", job.Name, missed.Format(time.RFC3339))
+				s.start(ctx, job)
+				state[job.Name] = now
+			}
+		}
+		next[i] = job.Schedule.Next(now)
+		fire[i] = addJitter(next[i], job.Jitter)
+	}
+	s.saveState(state)
+
+	for {
+		earliest := time.Time{}
+		for _, t := range fire {
+			if !t.IsZero() && (earliest.IsZero() || t.Before(earliest)) {
+				earliest = t
+			}
+		}
+		if earliest.IsZero() {
+			// no job will ever run again.
+			<-ctx.Done()
+			return nil
+		}
+
+		timer := time.NewTimer(time.Until(earliest))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		for i, job := range s.Jobs {
+			if fire[i].IsZero() || fire[i].After(now) {
+				continue
+			}
+
+			if now.Sub(fire[i]) > s.missedRunGrace() && job.MissedRun != MissedRunOnce {
+				s.logf("job=%q skipped missed activation of %s", job.Name, next[i].Format(time.RFC3339))
+			} else {
+				s.start(ctx, job)
+			}
+			state[job.Name] = next[i]
+
+			// the next activation is based on now to not catch up on all missed ones.
+			next[i] = job.Schedule.Next(now)
+			fire[i] = addJitter(next[i], job.Jitter)
+		}
+		s.saveState(state)
+	}
+}
+
+// RunJob runs the command sequence of the job once and waits for it.
+// It reports if the job ran or was skipped because the previous run is still going.
+func (s *Scheduler) RunJob(ctx context.Context, job Job) bool {
+	if !s.acquire(job.Name) {
+		s.logf("job=%q skipped: previous run is still going", job.Name)
+		return false
+	}
+	defer s.release(job.Name)
+
+	s.run(ctx, job)
+	return true
+}
+
+// start runs the job in the background if it is not running already.
+func (s *Scheduler) start(ctx context.Context, job Job) {
+	if !s.acquire(job.Name) {
+		s.logf("job=%q skipped: previous run is still going", job.Name)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.release(job.Name)
+		s.run(ctx, job)
+	}()
+}
+
+func (s *Scheduler) acquire(name string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.running == nil {
+		s.running = map[string]bool{}
+	}
+	if s.running[name] {
+		return false
+	}
+	s.running[name] = true
+	return true
+}
+
+func (s *Scheduler) release(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.running, name)
+}
+
+// run executes the commands one after another on all servers of the job.
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	start := time.Now()
+	clients := make(map[string]client.Client, len(job.Servers))
+	for _, name := range job.Servers {
+		if c, ok := s.Clients[name]; ok {
+			clients[name] = c
+		}
+	}
+
+	f := fleet.New(clients)
+	if job.Timeout > 0 {
+		f.Timeout = job.Timeout
+	}
+
+	remaining := job.Servers
+	failed := 0
+	for _, cmd := range job.Commands {
+		if len(remaining) == 0 {
+			break
+		}
+
+		results := f.Exec(ctx, remaining, cmd)
+		next := []string{}
+		for _, name := range remaining {
+			result := results[name]
+			if result.Err != nil {
+				failed++
+				s.output(job, fmt.Sprintf("job=%q server=%q command=%q error=%q", job.Name, name, cmd, result.Err.Error()))
+				continue
+			}
+			s.output(job, fmt.Sprintf("job=%q server=%q command=%q response=%q", job.Name, name, cmd, strings.TrimSpace(result.Response)))
+			next = append(next, name)
+		}
+		remaining = next
+	}
+
+	s.logf("job=%q finished in %s with %d of %d servers failed", job.Name, time.Since(start).Round(time.Millisecond), failed, len(job.Servers))
+}
+
+// output writes a line to the output of the job or the logger.
+func (s *Scheduler) output(job Job, line string) {
+	if job.Output == nil {
+		s.logf("%s", line)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	fmt.Fprintf(job.Output, "%s %s\n", time.Now().Format(time.RFC3339), line)
+}
+
+func (s *Scheduler) validate() error {
+	names := map[string]bool{}
+	for i, job := range s.Jobs {
+		if job.Name == "" {
+			return fmt.Errorf("job %d: name is missing", i)
+		}
+		if names[job.Name] {
+			return fmt.Errorf("job '%s': name is not unique", job.Name)
+		}
+		names[job.Name] = true
+
+		if job.Schedule == nil {
+			return fmt.Errorf("job '%s': schedule is missing", job.Name)
+		}
+		if len(job.Commands) == 0 {
+			return fmt.Errorf("job '%s': commands are missing", job.Name)
+		}
+		switch job.MissedRun {
+		case "", MissedRunSkip, MissedRunOnce:
+		default:
+			return fmt.Errorf("job '%s': unknown missed run policy '%s'", job.Name, job.MissedRun)
+		}
+		for _, server := range job.Servers {
+			if _, ok := s.Clients[server]; !ok {
+				return fmt.Errorf("job '%s': unknown server '%s'", job.Name, server)
+			}
+		}
+	}
+	return nil
+}
+
+// loadState reads the last activations. A missing file is an empty state.
+func (s *Scheduler) loadState() (map[string]time.Time, error) {
+	state := map[string]time.Time{}
+	if s.StatePath == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(s.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing state '%s': %w", s.StatePath, err)
+	}
+	return state, nil
+}
+
+// saveState writes the last activations. Errors are only logged because the jobs keep running.
+func (s *Scheduler) saveState(state map[string]time.Time) {
+	if s.StatePath == "" {
+		return
+	}
+
+	data, err := json.Marshal(state)
+	if err == nil {
+		err = os.WriteFile(s.StatePath, data, 0o600)
+	}
+	if err != nil {
+		s.logf("saving state failed: %s", err.Error())
+	}
+}
+
+func (s *Scheduler) missedRunGrace() time.Duration {
+	if s.MissedRunGrace <= 0 {
+		return DefaultMissedRunGrace
+	}
+	return s.MissedRunGrace
+}
+
+func (s *Scheduler) logf(format string, args ...interface{}) {
+	if s.Logger != nil {
+		s.Logger.Printf(format, args...)
+	}
+}
+
+// addJitter delays the time by a random duration up to the jitter.
+func addJitter(t time.Time, jitter time.Duration) time.Time {
+	if t.IsZero() || jitter <= 0 {
+		return t
+	}
+	return t.Add(time.Duration(rand.Int63n(int64(jitter))))
+}
@@ -0,0 +1,191 @@
+package scheduler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/scheduler"
+)
+
+// MockClient records the executed commands and fails the commands in Fail.
+type MockClient struct {
+	Fail  map[string]bool
+	Delay time.Duration
+
+	mutex    sync.Mutex
+	Executed []string
+}
+
+func (mc *MockClient) Auth(password string) error {
+	return nil
+}
+
+func (mc *MockClient) Exec(cmd string) ([]byte, error) {
+	time.Sleep(mc.Delay)
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	mc.Executed = append(mc.Executed, cmd)
+	if mc.Fail[cmd] {
+		return []byte{}, errors.New("failed")
+	}
+	return []byte("ok: " + cmd), nil
+}
+
+func (mc *MockClient) Commands() []string {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	return append([]string{}, mc.Executed...)
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use.
+type syncBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	return sb.buf.String()
+}
+
+func mustParse(t *testing.T, expr string) scheduler.Schedule {
+	schedule, err := scheduler.ParseSchedule(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schedule
+}
+
+func TestScheduler_RunJob(t *testing.T) {
+	t.Run("sequence stops after an error", func(t *testing.T) {
+		healthy := &MockClient{}
+		broken := &MockClient{Fail: map[string]bool{"save-off": true}}
+		output := &syncBuffer{}
+		s := scheduler.New(map[string]client.Client{"healthy": healthy, "broken": broken}, nil, nil)
+
+		ran := s.RunJob(context.Background(), scheduler.Job{
+			Name:     "backup",
+			Schedule: mustParse(t, "@daily"),
+			Servers:  []string{"healthy", "broken"},
+			Commands: []string{"save-off", "save-all", "save-on"},
+			Output:   output,
+		})
+
+		if !ran {
+			t.Error("expected the job to run")
+		}
+		if got := strings.Join(healthy.Commands(), ","); got != "save-off,save-all,save-on" {
+			t.Errorf("commands of the healthy server did not match:\nexpected: %s\ngot: %s\n", "save-off,save-all,save-on", got)
+		}
+		if got := strings.Join(broken.Commands(), ","); got != "save-off" {
+			t.Errorf("commands of the broken server did not match:\nexpected: %s\ngot: %s\n", "save-off", got)
+		}
+		if !strings.Contains(output.String(), `job="backup" server="broken" command="save-off" error="failed"`) {
+			t.Errorf("output did not contain the error:\n%s\n", output.String())
+		}
+		if !strings.Contains(output.String(), `server="healthy" command="save-on" response="ok: save-on"`) {
+			t.Errorf("output did not contain the response:\n%s\n", output.String())
+		}
+	})
+
+	t.Run("overlap prevention", func(t *testing.T) {
+		slow := &MockClient{Delay: 200 * time.Millisecond}
+		s := scheduler.New(map[string]client.Client{"slow": slow}, nil, nil)
+		job := scheduler.Job{Name: "slow", Schedule: mustParse(t, "@hourly"), Servers: []string{"slow"}, Commands: []string{"status"}}
+
+		done := make(chan bool)
+		go func() { done <- s.RunJob(context.Background(), job) }()
+		time.Sleep(50 * time.Millisecond)
+
+		if s.RunJob(context.Background(), job) {
+			t.Error("expected the overlapping run to be skipped")
+		}
+		if !<-done {
+			t.Error("expected the first run to run")
+		}
+		if len(slow.Commands()) != 1 {
+			t.Errorf("number of executions did not match:\nexpected: %d\ngot: %d\n", 1, len(slow.Commands()))
+		}
+	})
+}
+
+func TestScheduler_Run(t *testing.T) {
+	t.Run("runs on schedule until canceled", func(t *testing.T) {
+		mock := &MockClient{}
+		s := scheduler.New(map[string]client.Client{"a": mock}, []scheduler.Job{
+			{Name: "fast", Schedule: mustParse(t, "@every 50ms"), Servers: []string{"a"}, Commands: []string{"list"}, Jitter: 10 * time.Millisecond},
+		}, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 320*time.Millisecond)
+		defer cancel()
+		if err := s.Run(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := len(mock.Commands()); got < 3 || got > 6 {
+			t.Errorf("number of runs did not match:\nexpected: 3-6\ngot: %d\n", got)
+		}
+	})
+
+	t.Run("missed run policy", func(t *testing.T) {
+		for _, policy := range []scheduler.MissedRunPolicy{scheduler.MissedRunOnce, scheduler.MissedRunSkip} {
+			statePath := filepath.Join(t.TempDir(), "state.json")
+			data, _ := json.Marshal(map[string]time.Time{"nightly": time.Now().Add(-48 * time.Hour)})
+			if err := os.WriteFile(statePath, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			mock := &MockClient{}
+			s := scheduler.New(map[string]client.Client{"a": mock}, []scheduler.Job{
+				{Name: "nightly", Schedule: mustParse(t, "@daily"), Servers: []string{"a"}, Commands: []string{"save-all"}, MissedRun: policy},
+			}, nil)
+			s.StatePath = statePath
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			err := s.Run(ctx)
+			cancel()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expect := 0
+			if policy == scheduler.MissedRunOnce {
+				expect = 1
+			}
+			if got := len(mock.Commands()); got != expect {
+				t.Errorf("runs with policy %s did not match:\nexpected: %d\ngot: %d\n", policy, expect, got)
+			}
+		}
+	})
+
+	t.Run("invalid jobs", func(t *testing.T) {
+		clients := map[string]client.Client{"a": &MockClient{}}
+		tests := map[string][]scheduler.Job{
+			"unknown server":  {{Name: "x", Schedule: mustParse(t, "@daily"), Servers: []string{"b"}, Commands: []string{"list"}}},
+			"duplicate name":  {{Name: "x", Schedule: mustParse(t, "@daily"), Commands: []string{"list"}}, {Name: "x", Schedule: mustParse(t, "@daily"), Commands: []string{"list"}}},
+			"missing command": {{Name: "x", Schedule: mustParse(t, "@daily")}},
+		}
+		for name, jobs := range tests {
+			if err := scheduler.New(clients, jobs, nil).Run(context.Background()); err == nil {
+				t.Errorf("expected an error for the %s\n", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+// Defaults of the AuthGuard.
+const (
+	DefaultMaxFailures       = 5
+	DefaultGlobalMaxFailures = 50
+	DefaultFailureWindow     = 10 * time.Minute
+	DefaultBanDuration       = 30 * time.Minute
+	DefaultAuthDelay         = 500 * time.Millisecond
+	DefaultMaxAuthDelay      = 5 * time.Second
+)
+
+// AuthEventType describes what happened to an authentication attempt.
+type AuthEventType string
+
+// Types of the AuthEvents.
+const (
+	// AuthFailed is a wrong password.
+	AuthFailed AuthEventType = "auth_failed"
+	// AuthBanned is the failure that got the IP banned.
+	AuthBanned AuthEventType = "banned"
+	// AuthRejected is an attempt of a banned IP. The password was not checked.
+	AuthRejected AuthEventType = "rejected"
+)
+
+// AuthEvent is passed to the OnEvent hook of the AuthGuard.
+type AuthEvent struct {
+	Type AuthEventType
+	// IP is the address of the client without the port.
+	IP string
+	// Failures is the number of failures of the IP within the window.
+	Failures int
+	// Until is the end of the ban of AuthBanned and AuthRejected events.
+	Until time.Time
+}
+
+// String formats the event as a single line that is easy to match for tools like fail2ban:
+//	grcon auth_failed ip=192.0.2.1 failures=3
+//	grcon banned ip=192.0.2.1 failures=5 until=2026-10-19T12:30:00Z
+func (ae AuthEvent) String() string {
+	line := fmt.Sprintf("grcon %s ip=%s failures=%d", ae.Type, ae.IP, ae.Failures)
+	if !ae.Until.IsZero() {
+		line += " until=" + ae.Until.UTC().Format(time.RFC3339)
+	}
+	return line
+}
+
+// LogAuthEvents returns an OnEvent hook that writes the events to the logger.
+func LogAuthEvents(logger *log.Logger) func(AuthEvent) {
+	return func(event AuthEvent) {
+		logger.Println(event.String())
+	}
+}
+
+// AuthGuard protects a Server against password guessing.
+// Failed authentications are tracked per IP and globally:
+//	- every attempt is answered after a delay that doubles with each failure of the IP,
+//	  successful ones included, so the response time does not reveal the result
+//	- an IP gets banned after MaxFailures within the FailureWindow
+//	- all failures get the maximal delay while the failures of all IPs exceed GlobalMaxFailures,
+//	  which slows down distributed attacks without locking out the admins
+// A successful authentication resets the failures of the IP.
+//
+// The zero value uses the defaults and can be used concurrently.
+type AuthGuard struct {
+	// MaxFailures of an IP within the FailureWindow before it gets banned.
+	MaxFailures int
+	// GlobalMaxFailures of all IPs within the FailureWindow before every failure gets the MaxDelay.
+	GlobalMaxFailures int
+	// FailureWindow is the time a failure is remembered.
+	FailureWindow time.Duration
+	// BanDuration is the time a banned IP has to wait.
+	BanDuration time.Duration
+	// Delay of the response to the first failure of an IP.
+	Delay time.Duration
+	// MaxDelay limits the growing delays.
+	MaxDelay time.Duration
+	// Disconnect closes the connections of banned IPs instead of answering
+	// with a failed SERVERDATA_AUTH_RESPONSE.
+	Disconnect bool
+	// OnEvent is called for every failure and rejected attempt, e.g. with LogAuthEvents.
+	OnEvent func(AuthEvent)
+
+	mutex    sync.Mutex
+	ips      map[string]*ipFailures
+	failures []time.Time
+}
+
+// ipFailures are the recent failures and the ban of an IP.
+type ipFailures struct {
+	times  []time.Time
+	banned time.Time
+}
+
+// Banned reports if the IP of the address is banned and returns the end of the ban.
+func (g *AuthGuard) Banned(addr net.Addr) (bool, time.Time) {
+	ip := hostOf(addr)
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	state, ok := g.ips[ip]
+	if !ok || !time.Now().Before(state.banned) {
+		return false, time.Time{}
+	}
+	return true, state.banned
+}
+
+// Reject reports an attempt of a banned IP to the hook.
+func (g *AuthGuard) Reject(addr net.Addr) {
+	banned, until := g.Banned(addr)
+	if !banned {
+		return
+	}
+
+	g.mutex.Lock()
+	failures := len(g.ips[hostOf(addr)].times)
+	g.mutex.Unlock()
+
+	g.emit(AuthEvent{Type: AuthRejected, IP: hostOf(addr), Failures: failures, Until: until})
+}
+
+// AuthAttempt is an authentication that was admitted by the AuthGuard.
+type AuthAttempt struct {
+	// Banned is true if the IP is banned. The password must not be checked then.
+	Banned bool
+	// Delay is the wait before the response. It does not depend on the result of the password check.
+	Delay time.Duration
+
+	guard *AuthGuard
+	ip    string
+	at    time.Time
+	event AuthEvent
+}
+
+// Attempt checks the ban of the address and records the attempt as failure before the
+// password is checked. Parallel attempts of an IP therefore cannot check more than
+// MaxFailures passwords before the ban. Done has to be called with the result of the check
+// unless the IP is banned, which is reported to the hook as rejected attempt.
+func (g *AuthGuard) Attempt(addr net.Addr) *AuthAttempt {
+	ip := hostOf(addr)
+	now := time.Now()
+
+	g.mutex.Lock()
+	if state, ok := g.ips[ip]; ok && now.Before(state.banned) {
+		event := AuthEvent{Type: AuthRejected, IP: ip, Failures: len(state.times), Until: state.banned}
+		g.mutex.Unlock()
+
+		g.emit(event)
+		return &AuthAttempt{Banned: true}
+	}
+	delay, event := g.record(ip, now)
+	g.mutex.Unlock()
+
+	return &AuthAttempt{Delay: delay, guard: g, ip: ip, at: now, event: event}
+}
+
+// Done reports the result of the password check. A success undoes the recorded failure
+// and resets the failures of the IP like Success.
+func (a *AuthAttempt) Done(ok bool) {
+	if a.Banned {
+		return
+	}
+	if !ok {
+		a.guard.emit(a.event)
+		return
+	}
+
+	g := a.guard
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	delete(g.ips, a.ip)
+	for i := len(g.failures) - 1; i >= 0; i-- {
+		if g.failures[i].Equal(a.at) {
+			g.failures = append(g.failures[:i], g.failures[i+1:]...)
+			break
+		}
+	}
+}
+
+// Failure records a failed authentication of the address and returns the delay for the response.
+func (g *AuthGuard) Failure(addr net.Addr) time.Duration {
+	g.mutex.Lock()
+	delay, event := g.record(hostOf(addr), time.Now())
+	g.mutex.Unlock()
+
+	g.emit(event)
+	return delay
+}
+
+// record adds a failure of the IP, bans it after too many failures and returns the delay
+// and the event of the failure. The mutex has to be held.
+func (g *AuthGuard) record(ip string, now time.Time) (time.Duration, AuthEvent) {
+	g.prune(now)
+
+	if g.ips == nil {
+		g.ips = map[string]*ipFailures{}
+	}
+	state, ok := g.ips[ip]
+	if !ok {
+		state = &ipFailures{}
+		g.ips[ip] = state
+	}
+	state.times = append(state.times, now)
+	g.failures = append(g.failures, now)
+
+	event := AuthEvent{Type: AuthFailed, IP: ip, Failures: len(state.times)}
+	if len(state.times) >= orDefault(g.MaxFailures, DefaultMaxFailures) {
+		state.banned = now.Add(orDefaultDuration(g.BanDuration, DefaultBanDuration))
+		event.Type = AuthBanned
+		event.Until = state.banned
+	}
+
+	maxDelay := orDefaultDuration(g.MaxDelay, DefaultMaxAuthDelay)
+	delay := maxDelay
+	if len(g.failures) <= orDefault(g.GlobalMaxFailures, DefaultGlobalMaxFailures) {
+		delay = orDefaultDuration(g.Delay, DefaultAuthDelay)
+		for i := 1; i < len(state.times) && delay < maxDelay; i++ {
+			delay *= 2
+		}
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+	return delay, event
+}
+
+// Success resets the failures of the address.
+func (g *AuthGuard) Success(addr net.Addr) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	delete(g.ips, hostOf(addr))
+}
+
+// Unban removes the ban and the failures of the IP.
+func (g *AuthGuard) Unban(ip string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	delete(g.ips, ip)
+}
+
+// prune forgets the failures outside of the window and expired bans. The mutex has to be held.
+func (g *AuthGuard) prune(now time.Time) {
+	since := now.Add(-orDefaultDuration(g.FailureWindow, DefaultFailureWindow))
+
+	g.failures = recent(g.failures, since)
+	for ip, state := range g.ips {
+		state.times = recent(state.times, since)
+		if len(state.times) == 0 && !now.Before(state.banned) {
+			delete(g.ips, ip)
+		}
+	}
+}
+
+func (g *AuthGuard) emit(event AuthEvent) {
+	if g.OnEvent != nil {
+		g.OnEvent(event)
+	}
+}
+
+// recent returns the sorted times after since.
+func recent(times []time.Time, since time.Time) []time.Time {
+	for i, t := range times {
+		if t.After(since) {
+			return times[i:]
+		}
+	}
+	return times[:0]
+}
+
+// hostOf returns the IP of the address without the port.
+func hostOf(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+func orDefault(value, def int) int {
+	if value <= 0 {
+		return def
+	}
+	return value
+}
+
+func orDefaultDuration(value, def time.Duration) time.Duration {
+	if value <= 0 {
+		return def
+	}
+	return value
+}
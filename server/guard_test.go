@@ -0,0 +1,276 @@
+package server_test
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/server"
+)
+
+func TestAuthGuard(t *testing.T) {
+	attacker := &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 50000}
+	admin := &net.TCPAddr{IP: net.ParseIP("192.0.2.2"), Port: 50000}
+
+	t.Run("progressive delays and ban", func(t *testing.T) {
+		events := []server.AuthEvent{}
+		guard := &server.AuthGuard{
+			MaxFailures: 4,
+			Delay:       time.Second,
+			MaxDelay:    3 * time.Second,
+			OnEvent:     func(event server.AuthEvent) { events = append(events, event) },
+		}
+
+		expected := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+		for i, expect := range expected {
+			if banned, _ := guard.Banned(attacker); banned {
+				t.Fatalf("banned after %d failures\n", i)
+			}
+			if got := guard.Failure(attacker); got != expect {
+				t.Errorf("delay of failure %d did not match:\nexpected: %s\ngot: %s\n", i+1, expect, got)
+			}
+		}
+
+		banned, until := guard.Banned(attacker)
+		if !banned || time.Until(until) < 29*time.Minute {
+			t.Errorf("expected a ban of 30 minutes but got: %t until %s\n", banned, until)
+		}
+		if banned, _ := guard.Banned(admin); banned {
+			t.Error("other IPs must not be banned")
+		}
+		// the port does not matter.
+		if banned, _ := guard.Banned(&net.TCPAddr{IP: attacker.IP, Port: 1}); !banned {
+			t.Error("expected the IP to be banned on all ports")
+		}
+
+		guard.Reject(attacker)
+		if len(events) != 5 || events[2].Type != server.AuthFailed || events[3].Type != server.AuthBanned || events[4].Type != server.AuthRejected {
+			t.Errorf("events did not match: %+v\n", events)
+		}
+		if got := events[0].String(); got != "grcon auth_failed ip=192.0.2.1 failures=1" {
+			t.Errorf("event line did not match:\nexpected: %s\ngot: %s\n", "grcon auth_failed ip=192.0.2.1 failures=1", got)
+		}
+
+		guard.Unban("192.0.2.1")
+		if banned, _ := guard.Banned(attacker); banned {
+			t.Error("expected the IP to be unbanned")
+		}
+	})
+
+	t.Run("success resets the failures", func(t *testing.T) {
+		guard := &server.AuthGuard{MaxFailures: 2, Delay: time.Millisecond}
+		guard.Failure(admin)
+		guard.Success(admin)
+		guard.Failure(admin)
+		if banned, _ := guard.Banned(admin); banned {
+			t.Error("failures before the success must not count")
+		}
+	})
+
+	t.Run("failures expire", func(t *testing.T) {
+		guard := &server.AuthGuard{MaxFailures: 2, FailureWindow: 50 * time.Millisecond, BanDuration: 50 * time.Millisecond}
+		guard.Failure(attacker)
+		time.Sleep(60 * time.Millisecond)
+		guard.Failure(attacker)
+		if banned, _ := guard.Banned(attacker); banned {
+			t.Error("expired failures must not count")
+		}
+
+		guard.Failure(attacker)
+		if banned, _ := guard.Banned(attacker); !banned {
+			t.Fatal("expected the IP to be banned")
+		}
+		time.Sleep(60 * time.Millisecond)
+		if banned, _ := guard.Banned(attacker); banned {
+			t.Error("expected the ban to expire")
+		}
+	})
+
+	t.Run("parallel attempts", func(t *testing.T) {
+		guard := &server.AuthGuard{MaxFailures: 3}
+		attempts := make(chan *server.AuthAttempt, 10)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				attempts <- guard.Attempt(attacker)
+			}()
+		}
+		wg.Wait()
+		close(attempts)
+
+		admitted := 0
+		for attempt := range attempts {
+			if !attempt.Banned {
+				admitted++
+			}
+		}
+		if admitted != 3 {
+			t.Errorf("admitted attempts did not match:\nexpected: %d\ngot: %d\n", 3, admitted)
+		}
+	})
+
+	t.Run("successful attempt", func(t *testing.T) {
+		guard := &server.AuthGuard{MaxFailures: 2, GlobalMaxFailures: 2, Delay: time.Millisecond, MaxDelay: time.Second}
+		guard.Failure(admin)
+		attempt := guard.Attempt(admin)
+		if attempt.Delay != 2*time.Millisecond {
+			t.Errorf("delay did not match:\nexpected: %s\ngot: %s\n", 2*time.Millisecond, attempt.Delay)
+		}
+		attempt.Done(true)
+		if banned, _ := guard.Banned(admin); banned {
+			t.Error("a successful attempt must not ban")
+		}
+		// the global failures only contain the failure before the attempt.
+		if got := guard.Failure(attacker); got != time.Millisecond {
+			t.Errorf("delay did not match:\nexpected: %s\ngot: %s\n", time.Millisecond, got)
+		}
+	})
+
+	t.Run("global failures", func(t *testing.T) {
+		guard := &server.AuthGuard{GlobalMaxFailures: 3, Delay: time.Millisecond, MaxDelay: time.Second}
+		for i := 0; i < 3; i++ {
+			guard.Failure(&net.TCPAddr{IP: net.IPv4(198, 51, 100, byte(i)), Port: 1})
+		}
+		if got := guard.Failure(admin); got != time.Second {
+			t.Errorf("delay did not match:\nexpected: %s\ngot: %s\n", time.Second, got)
+		}
+	})
+}
+
+func TestServer_AuthGuard(t *testing.T) {
+	// auth sends repeated SERVERDATA_AUTH packets over one connection and returns the response ids.
+	auth := func(t *testing.T, remoteConsole *grcon.RemoteConsole, passwords ...string) []grcon.PacketId {
+		ids := []grcon.PacketId{}
+		for i, password := range passwords {
+			if err := remoteConsole.Write(grcon.Packet{Id: grcon.PacketId(i + 1), Type: grcon.SERVERDATA_AUTH, Body: []byte(password)}); err != nil {
+				return ids
+			}
+			packet, err := remoteConsole.Read()
+			if err != nil {
+				return ids
+			}
+			ids = append(ids, packet.Id)
+		}
+		return ids
+	}
+
+	t.Run("banned client gets -1", func(t *testing.T) {
+		var mutex sync.Mutex
+		checked := 0
+		srv := &server.Server{
+			Auth: func(s *server.Session, password string) (string, bool) {
+				mutex.Lock()
+				checked++
+				mutex.Unlock()
+				return "alice", password == "secret"
+			},
+			Handler:   echoHandler,
+			AuthGuard: &server.AuthGuard{MaxFailures: 3, Delay: time.Millisecond},
+		}
+		remoteConsole := connect(t, srv)
+		defer remoteConsole.Conn.Close()
+
+		got := auth(t, remoteConsole, "a", "b", "c", "secret")
+		expected := []grcon.PacketId{-1, -1, -1, -1}
+		if len(got) != len(expected) || got[3] != -1 {
+			t.Errorf("response ids did not match:\nexpected: %v\ngot: %v\n", expected, got)
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		if checked != 3 {
+			t.Errorf("the password of a banned client must not be checked, checked %d passwords\n", checked)
+		}
+	})
+
+	t.Run("banned client gets disconnected", func(t *testing.T) {
+		srv := &server.Server{
+			Auth:      server.PasswordAuth("secret"),
+			Handler:   echoHandler,
+			AuthGuard: &server.AuthGuard{MaxFailures: 2, Delay: time.Millisecond, Disconnect: true},
+		}
+		remoteConsole := connect(t, srv)
+		defer remoteConsole.Conn.Close()
+
+		got := auth(t, remoteConsole, "a", "b", "secret")
+		if len(got) != 2 {
+			t.Errorf("expected the connection to be closed after 2 responses but got: %v\n", got)
+		}
+	})
+
+	t.Run("delayed responses", func(t *testing.T) {
+		srv := &server.Server{
+			Auth:      server.PasswordAuth("secret"),
+			Handler:   echoHandler,
+			AuthGuard: &server.AuthGuard{Delay: 50 * time.Millisecond},
+		}
+
+		for _, password := range []string{"a", "secret"} {
+			remoteConsole := connect(t, srv)
+			start := time.Now()
+			auth(t, remoteConsole, password)
+			// successes are delayed as well, otherwise the response time reveals the result.
+			if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+				t.Errorf("expected the response to %q to be delayed but it took %s\n", password, elapsed)
+			}
+			remoteConsole.Conn.Close()
+		}
+	})
+
+	t.Run("parallel connections", func(t *testing.T) {
+		var mutex sync.Mutex
+		checked := 0
+		srv := &server.Server{
+			Auth: func(s *server.Session, password string) (string, bool) {
+				mutex.Lock()
+				checked++
+				mutex.Unlock()
+				return "alice", password == "secret"
+			},
+			Handler:   echoHandler,
+			AuthGuard: &server.AuthGuard{MaxFailures: 3, Delay: 50 * time.Millisecond},
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			remoteConsole := connect(t, srv)
+			defer remoteConsole.Conn.Close()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				auth(t, remoteConsole, "guess")
+			}()
+		}
+		wg.Wait()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if checked != 3 {
+			t.Errorf("checked passwords did not match:\nexpected: %d\ngot: %d\n", 3, checked)
+		}
+	})
+
+	t.Run("failure ends the authentication", func(t *testing.T) {
+		srv := &server.Server{
+			Auth:      server.PasswordAuth("secret"),
+			Handler:   echoHandler,
+			AuthGuard: &server.AuthGuard{Delay: time.Millisecond},
+		}
+		remoteConsole := connect(t, srv)
+		defer remoteConsole.Conn.Close()
+
+		got := auth(t, remoteConsole, "secret", "wrong")
+		if len(got) != 2 || got[0] != 1 || got[1] != -1 {
+			t.Fatalf("response ids did not match:\nexpected: [1 -1]\ngot: %v\n", got)
+		}
+		if err := remoteConsole.Write(grcon.Packet{Id: 3, Type: grcon.SERVERDATA_EXECCOMMAND, Body: []byte("status")}); err != nil {
+			t.Fatal(err)
+		}
+		if packet, err := remoteConsole.Read(); err == nil {
+			t.Errorf("expected the connection to be closed but got: %+v\n", packet)
+		}
+	})
+}
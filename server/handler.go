@@ -0,0 +1,76 @@
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"crypto/tls"
+	"net"
+
+	"github.com/hamburghammer/grcon"
+)
+
+// Handler responds to the commands of authenticated sessions.
+//
+// The commands of a session are handled one after another,
+// but the handler is called concurrently for different sessions.
+type Handler interface {
+	ServeRCON(w ResponseWriter, r *Request)
+}
+
+// HandlerFunc is an adapter to use ordinary functions as Handler.
+type HandlerFunc func(w ResponseWriter, r *Request)
+
+// ServeRCON calls f(w, r).
+func (f HandlerFunc) ServeRCON(w ResponseWriter, r *Request) {
+	f(w, r)
+}
+
+// Request is a SERVERDATA_EXECCOMMAND packet of an authenticated session.
+type Request struct {
+	// Id of the command packet. All response packets get the same id.
+	Id grcon.PacketId
+	// Command is the body of the packet.
+	Command string
+	// Session that sent the command.
+	Session *Session
+}
+
+// ResponseWriter collects the response of a command.
+// The response gets sent after the handler returned and is split into
+// multiple SERVERDATA_RESPONSE_VALUE packets if it is too long for a single one.
+type ResponseWriter interface {
+	Write(p []byte) (int, error)
+}
+
+// responseBuffer is the ResponseWriter used by the Server.
+type responseBuffer struct {
+	bytes.Buffer
+}
+
+// Session holds the state of a connection.
+type Session struct {
+	// RemoteAddr is the address of the client.
+	// Behind a load balancer it is the address from the PROXY protocol header.
+	RemoteAddr net.Addr
+	// ProxyAddr is the address of the load balancer that sent a PROXY protocol header.
+	// It is nil for direct connections.
+	ProxyAddr net.Addr
+	// User is the name returned by the AuthFunc. It is empty until the session is authenticated.
+	User string
+	// Authenticated reports if the last SERVERDATA_AUTH packet was successful.
+	Authenticated bool
+	// TLS is the state of the TLS connection. It is nil for plain connections.
+	TLS *tls.ConnectionState
+}
+
+// AuthFunc checks the password of a SERVERDATA_AUTH packet and
+// returns the name of the authenticated user.
+type AuthFunc func(s *Session, password string) (user string, ok bool)
+
+// PasswordAuth returns an AuthFunc that accepts a single password.
+// The password is compared in constant time and the user name is empty.
+func PasswordAuth(password string) AuthFunc {
+	return func(s *Session, given string) (string, bool) {
+		return "", password != "" && subtle.ConstantTimeCompare([]byte(password), []byte(given)) == 1
+	}
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProxyProtocolMode selects if HAProxy PROXY protocol headers are parsed.
+type ProxyProtocolMode string
+
+// Modes of the PROXY protocol support.
+const (
+	// ProxyProtocolOff serves the connections without looking for a header.
+	ProxyProtocolOff ProxyProtocolMode = ""
+	// ProxyProtocolOptional parses a v1 or v2 header if the connection starts with one.
+	// This is unambiguous because a header is never the start of a valid RCON packet.
+	ProxyProtocolOptional ProxyProtocolMode = "optional"
+	// ProxyProtocolRequired closes connections without a header.
+	ProxyProtocolRequired ProxyProtocolMode = "required"
+)
+
+// proxyHeaderTimeout limits the time to receive the PROXY protocol header.
+const proxyHeaderTimeout = 10 * time.Second
+
+var (
+	proxyV1Prefix    = []byte("PROXY ")
+	proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// maxProxyV1Header is the longest v1 header including the CRLF.
+const maxProxyV1Header = 107
+
+var (
+	// ErrNoProxyHeader is returned by ReadProxyHeader if the connection does not start with a header.
+	ErrNoProxyHeader = errors.New("grcon-server: missing PROXY protocol header")
+	// ErrNoTrustedProxies is returned by Serve if the ProxyProtocol is enabled without TrustedProxies.
+	ErrNoTrustedProxies = errors.New("grcon-server: the PROXY protocol requires TrustedProxies")
+)
+
+// proxyConn is a connection with the client address of a PROXY protocol header.
+type proxyConn struct {
+	net.Conn
+	reader *bufio.Reader
+	remote net.Addr
+}
+
+func (pc *proxyConn) Read(b []byte) (int, error) {
+	return pc.reader.Read(b)
+}
+
+// RemoteAddr returns the address of the client from the header.
+func (pc *proxyConn) RemoteAddr() net.Addr {
+	return pc.remote
+}
+
+// ReadProxyHeader reads a PROXY protocol v1 or v2 header from the connection.
+// The returned connection reports the client address of the header as RemoteAddr.
+// Headers of health checks (v2 LOCAL) and v1 UNKNOWN headers keep the address of the connection.
+// Connections without header are returned with ErrNoProxyHeader and can still be used.
+// The returned connection always has to be used instead of conn because it holds the bytes
+// that were read ahead. The connection has to be closed for all other errors.
+// The header has to arrive within 10 seconds.
+func ReadProxyHeader(conn net.Conn) (net.Conn, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout)); err != nil {
+		return conn, err
+	}
+
+	reader := bufio.NewReaderSize(conn, 512)
+	// the smallest RCON packet has 14 bytes, so peeking 12 bytes never blocks a client without header.
+	start, err := reader.Peek(len(proxyV2Signature))
+	if err != nil {
+		return conn, err
+	}
+
+	var remote net.Addr
+	switch {
+	case bytes.HasPrefix(start, proxyV1Prefix):
+		remote, err = readProxyV1(reader)
+	case bytes.Equal(start, proxyV2Signature):
+		remote, err = readProxyV2(reader)
+	default:
+		err = ErrNoProxyHeader
+	}
+	if err != nil && err != ErrNoProxyHeader {
+		return conn, fmt.Errorf("grcon-server: invalid PROXY protocol header: %w", err)
+	}
+
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return conn, err
+	}
+	if remote == nil {
+		remote = conn.RemoteAddr()
+	}
+	// the reader holds the bytes after the header and has to be used from now on.
+	return &proxyConn{Conn: conn, reader: reader, remote: remote}, err
+}
+
+// readProxyV1 parses a header like "PROXY TCP4 192.0.2.1 198.51.100.1 56324 27015\r\n".
+func readProxyV1(reader *bufio.Reader) (net.Addr, error) {
+	line := make([]byte, 0, maxProxyV1Header)
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) == maxProxyV1Header {
+			return nil, errors.New("v1 header is too long")
+		}
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+	}
+
+	fields := strings.Split(strings.TrimSuffix(string(line), "\r\n"), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("invalid v1 header %q", string(line))
+	}
+
+	ip := net.ParseIP(fields[2])
+	if ip == nil || (fields[1] == "TCP4") != (ip.To4() != nil) {
+		return nil, fmt.Errorf("invalid source address %q", fields[2])
+	}
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source port %q", fields[4])
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyV2 parses the binary header with the 12 byte signature.
+func readProxyV2(reader *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+
+	version, command := header[12]>>4, header[12]&0x0f
+	if version != 2 {
+		return nil, fmt.Errorf("unsupported version %d", version)
+	}
+	family, protocol := header[13]>>4, header[13]&0x0f
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, err
+	}
+
+	switch command {
+	case 0x0:
+		// LOCAL: health checks of the balancer itself.
+		return nil, nil
+	case 0x1:
+	default:
+		return nil, fmt.Errorf("unsupported command %d", command)
+	}
+	if protocol != 0x1 {
+		// only TCP (STREAM) connections carry RCON.
+		return nil, nil
+	}
+
+	switch family {
+	case 0x1:
+		if len(payload) < 12 {
+			return nil, errors.New("IPv4 addresses are too short")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))}, nil
+	case 0x2:
+		if len(payload) < 36 {
+			return nil, errors.New("IPv6 addresses are too short")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))}, nil
+	}
+	// UNSPEC and unix sockets have no usable client address.
+	return nil, nil
+}
+
+// trusted reports if the PROXY protocol header of the address is accepted.
+// Nobody is trusted without TrustedProxies.
+func (srv *Server) trusted(addr net.Addr) bool {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	for _, network := range srv.TrustedProxies {
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies parses CIDRs like "10.0.0.0/8" or single IPs for the TrustedProxies.
+func ParseTrustedProxies(values []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy '%s'", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy '%s': %w", value, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
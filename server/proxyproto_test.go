@@ -0,0 +1,234 @@
+package server_test
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/server"
+)
+
+// proxyV2Header builds a v2 PROXY header for a TCP connection of the source address.
+func proxyV2Header(command byte, src *net.TCPAddr) []byte {
+	header := []byte("\r\n\r\n\x00\r\nQUIT\n")
+	header = append(header, 0x20|command)
+
+	var addresses []byte
+	dst := net.TCPAddr{IP: net.ParseIP("198.51.100.1"), Port: 27015}
+	if ip := src.IP.To4(); ip != nil {
+		header = append(header, 0x11)
+		addresses = append(append(addresses, ip...), dst.IP.To4()...)
+	} else {
+		header = append(header, 0x21)
+		addresses = append(append(addresses, src.IP.To16()...), dst.IP.To16()...)
+	}
+	addresses = appendUint16(addresses, uint16(src.Port))
+	addresses = appendUint16(addresses, uint16(dst.Port))
+	// a TLV that has to be skipped
+	addresses = append(addresses, 0x04, 0x00, 0x01, 0xff)
+
+	header = appendUint16(header, uint16(len(addresses)))
+	return append(header, addresses...)
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	rconPacket := []byte{10, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 0, 0}
+
+	tests := []struct {
+		name   string
+		header []byte
+		expect string
+		err    bool
+	}{
+		{name: "v1 TCP4", header: []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 27015\r\n"), expect: "192.0.2.1:56324"},
+		{name: "v1 TCP6", header: []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 27015\r\n"), expect: "[2001:db8::1]:56324"},
+		{name: "v1 UNKNOWN", header: []byte("PROXY UNKNOWN\r\n"), expect: "pipe"},
+		{name: "v2 IPv4", header: proxyV2Header(0x1, &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 56324}), expect: "192.0.2.1:56324"},
+		{name: "v2 IPv6", header: proxyV2Header(0x1, &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 56324}), expect: "[2001:db8::1]:56324"},
+		{name: "v2 LOCAL", header: proxyV2Header(0x0, &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 56324}), expect: "pipe"},
+		{name: "v1 with invalid address", header: []byte("PROXY TCP4 2001:db8::1 198.51.100.1 56324 27015\r\n"), err: true},
+		{name: "v1 without CRLF", header: append([]byte("PROXY TCP4 "), make([]byte, 120)...), err: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			clientConn, serverConn := net.Pipe()
+			defer clientConn.Close()
+			defer serverConn.Close()
+			go clientConn.Write(append(append([]byte{}, test.header...), rconPacket...))
+
+			conn, err := server.ReadProxyHeader(serverConn)
+			if test.err {
+				if err == nil {
+					t.Error("expected an error for the invalid header")
+				}
+				return
+			}
+			if err != nil {
+				t.Error(err)
+				t.FailNow()
+			}
+			if got := conn.RemoteAddr().String(); got != test.expect {
+				t.Errorf("address did not match:\nexpected: %s\ngot: %s\n", test.expect, got)
+			}
+
+			// the RCON packet after the header has to be readable.
+			packet, err := grcon.NewRemoteConsole(conn).Read()
+			if err != nil || packet.Id != 1 {
+				t.Errorf("reading the packet after the header failed: %+v %v\n", packet, err)
+			}
+		})
+	}
+
+	t.Run("no header", func(t *testing.T) {
+		clientConn, serverConn := net.Pipe()
+		defer clientConn.Close()
+		defer serverConn.Close()
+		go clientConn.Write(rconPacket)
+
+		conn, err := server.ReadProxyHeader(serverConn)
+		if err != server.ErrNoProxyHeader {
+			t.Errorf("expected: %v\ngot: %v\n", server.ErrNoProxyHeader, err)
+		}
+		got := make([]byte, len(rconPacket))
+		if _, err := io.ReadFull(conn, got); err != nil || string(got) != string(rconPacket) {
+			t.Errorf("the bytes read ahead were lost: %v %v\n", got, err)
+		}
+	})
+}
+
+func TestServer_ProxyProtocol(t *testing.T) {
+	addrHandler := server.HandlerFunc(func(w server.ResponseWriter, r *server.Request) {
+		fmt.Fprintf(w, "%s via %v", r.Session.RemoteAddr, r.Session.ProxyAddr)
+	})
+	loopback, err := server.ParseTrustedProxies([]string{"127.0.0.1", "::1/128"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := server.ParseTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// exec connects with the header and returns the response to "addr" or the error.
+	exec := func(t *testing.T, srv *server.Server, header string) (string, error) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		go srv.Serve(l)
+		t.Cleanup(func() { srv.Close() })
+
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.Write([]byte(header)); err != nil {
+			t.Fatal(err)
+		}
+
+		minecraftClient := client.NewMinecraftClient(grcon.NewRemoteConsole(conn), newIdGenerator())
+		if err := minecraftClient.Auth("secret"); err != nil {
+			return "", err
+		}
+		response, err := minecraftClient.Exec("addr")
+		return string(response), err
+	}
+
+	t.Run("real client address", func(t *testing.T) {
+		srv := &server.Server{Auth: server.PasswordAuth("secret"), Handler: addrHandler,
+			ProxyProtocol: server.ProxyProtocolRequired, TrustedProxies: loopback}
+
+		got, err := exec(t, srv, "PROXY TCP4 192.0.2.1 198.51.100.1 56324 27015\r\n")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if got[:len("192.0.2.1:56324 via 127.0.0.1:")] != "192.0.2.1:56324 via 127.0.0.1:" {
+			t.Errorf("address did not match:\nexpected: 192.0.2.1:56324 via 127.0.0.1:*\ngot: %s\n", got)
+		}
+	})
+
+	t.Run("optional header", func(t *testing.T) {
+		srv := &server.Server{Auth: server.PasswordAuth("secret"), Handler: addrHandler,
+			ProxyProtocol: server.ProxyProtocolOptional, TrustedProxies: loopback}
+
+		got, err := exec(t, srv, "")
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if got[:len("127.0.0.1:")] != "127.0.0.1:" {
+			t.Errorf("address did not match:\nexpected: 127.0.0.1:* via <nil>\ngot: %s\n", got)
+		}
+	})
+
+	t.Run("rejected connections", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			untrusted bool
+			header    string
+		}{
+			{name: "missing header", header: ""},
+			{name: "untrusted proxy", untrusted: true, header: "PROXY TCP4 192.0.2.1 198.51.100.1 56324 27015\r\n"},
+		}
+
+		for _, test := range tests {
+			test := test
+			t.Run(test.name, func(t *testing.T) {
+				srv := &server.Server{Auth: server.PasswordAuth("secret"), Handler: addrHandler,
+					ProxyProtocol: server.ProxyProtocolRequired, TrustedProxies: loopback}
+				if test.untrusted {
+					srv.TrustedProxies = other
+				}
+				if _, err := exec(t, srv, test.header); err == nil {
+					t.Error("expected the connection to be closed")
+				}
+			})
+		}
+	})
+
+	t.Run("no trusted proxies", func(t *testing.T) {
+		for _, mode := range []server.ProxyProtocolMode{server.ProxyProtocolOptional, server.ProxyProtocolRequired} {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := &server.Server{Auth: server.PasswordAuth("secret"), Handler: addrHandler, ProxyProtocol: mode}
+			if err := srv.Serve(l); err != server.ErrNoTrustedProxies {
+				t.Errorf("error of mode %s did not match:\nexpected: %v\ngot: %v\n", mode, server.ErrNoTrustedProxies, err)
+			}
+		}
+	})
+
+	t.Run("no trusted proxies with ServeConn", func(t *testing.T) {
+		srv := &server.Server{Auth: server.PasswordAuth("secret"), Handler: addrHandler, ProxyProtocol: server.ProxyProtocolOptional}
+		conn, serverConn := net.Pipe()
+		defer conn.Close()
+		go srv.ServeConn(serverConn)
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		// the header is not parsed and ends the connection as invalid packet.
+		if _, err := conn.Write([]byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 27015\r\n")); err == nil {
+			minecraftClient := client.NewMinecraftClient(grcon.NewRemoteConsole(conn), newIdGenerator())
+			if err := minecraftClient.Auth("secret"); err == nil {
+				response, _ := minecraftClient.Exec("addr")
+				if strings.HasPrefix(string(response), "192.0.2.1") {
+					t.Errorf("the spoofed address was accepted: %s\n", response)
+				}
+			}
+		}
+	})
+}
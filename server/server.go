@@ -0,0 +1,448 @@
+/*
+Package server implements the server side of the RCON protocol.
+
+A Server accepts connections, authenticates them with an AuthFunc and
+passes the commands to a Handler:
+
+	srv := &server.Server{
+		Addr: ":27015",
+		Auth: server.PasswordAuth("secret"),
+		Handler: server.HandlerFunc(func(w server.ResponseWriter, r *server.Request) {
+			fmt.Fprintf(w, "echo: %s", r.Command)
+		}),
+	}
+	log.Fatal(srv.ListenAndServe())
+
+The protocol details that differ between the games can be configured.
+The defaults behave like a Minecraft server.
+
+ListenAndServeTLS serves RCONS: the unchanged protocol over TLS. Clients can connect with
+client.TLSDialer. Mutual TLS is enabled with the ClientAuth and ClientCAs of the TLSConfig;
+the verified client certificate is available to the AuthFunc in Session.TLS.
+
+Behind HAProxy or a cloud load balancer the ProxyProtocol option reads the PROXY protocol header
+of the balancer, so Session.RemoteAddr is the address of the real client.
+*/
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+)
+
+// tlsHandshakeTimeout limits the TLS handshake of a new connection.
+const tlsHandshakeTimeout = 10 * time.Second
+
+// ErrServerClosed is returned by Serve and ListenAndServe after Close was called.
+var ErrServerClosed = errors.New("grcon-server: server closed")
+
+// Server is a RCON server.
+// The zero value is usable but denies all authentication attempts.
+type Server struct {
+	// Addr to listen on for ListenAndServe. Defaults to ":27015".
+	Addr string
+	// Handler that responds to the commands.
+	Handler Handler
+	// Auth checks the passwords. All attempts fail if it is nil.
+	Auth AuthFunc
+
+	// MaxResponseBody is the maximal body size of a response packet.
+	// Longer responses are split into multiple packets.
+	// Defaults to grcon.MaxBody.
+	MaxResponseBody int
+	// SendPreAuthResponse sends an empty SERVERDATA_RESPONSE_VALUE before
+	// the SERVERDATA_AUTH_RESPONSE like Source servers do.
+	SendPreAuthResponse bool
+	// MirrorEmptyResponse answers SERVERDATA_RESPONSE_VALUE packets with an empty one with the same id
+	// like Source servers do. Clients use them to detect the end of multi packet responses.
+	// The packets get ignored otherwise.
+	MirrorEmptyResponse bool
+
+	// TLSConfig is used by ListenAndServeTLS and ServeTLS.
+	// Set its ClientAuth and ClientCAs to require client certificates.
+	TLSConfig *tls.Config
+
+	// AuthGuard delays authentications and bans IPs that guess passwords.
+	// There is no protection if it is nil.
+	AuthGuard *AuthGuard
+
+	// ProxyProtocol enables the parsing of HAProxy PROXY protocol v1 and v2 headers
+	// to get the real client address behind a load balancer.
+	ProxyProtocol ProxyProtocolMode
+	// TrustedProxies are the networks of the load balancers whose headers are accepted.
+	// Connections of other sources are served without parsing a header or rejected if the header is required.
+	// It must not be empty if the ProxyProtocol is enabled, otherwise every client could fake its address.
+	TrustedProxies []*net.IPNet
+
+	// ErrorLog logs errors of connections. Nothing is logged if it is nil.
+	ErrorLog *log.Logger
+
+	mutex     sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// ListenAndServe listens on the TCP address Addr and serves the connections.
+// It always returns a non-nil error.
+func (srv *Server) ListenAndServe() error {
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":27015"
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return srv.Serve(l)
+}
+
+// ListenAndServeTLS listens on the TCP address Addr and serves RCON over TLS.
+// The certificate and key files are added to the certificates of the TLSConfig.
+// They can be empty if the TLSConfig already contains a certificate.
+// It always returns a non-nil error.
+func (srv *Server) ListenAndServeTLS(certFile, keyFile string) error {
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":27015"
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return srv.ServeTLS(l, certFile, keyFile)
+}
+
+// ServeTLS accepts connections on the listener and serves RCON over TLS.
+// See ListenAndServeTLS for the certificate and key files.
+// It always returns a non-nil error.
+func (srv *Server) ServeTLS(l net.Listener, certFile, keyFile string) error {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if srv.TLSConfig != nil {
+		config = srv.TLSConfig.Clone()
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			l.Close()
+			return err
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+	if len(config.Certificates) == 0 && config.GetCertificate == nil {
+		l.Close()
+		return errors.New("grcon-server: no TLS certificate configured")
+	}
+
+	return srv.serve(l, config)
+}
+
+// Serve accepts connections on the listener and serves each of them in a new goroutine.
+// The listener gets closed when Serve returns. It always returns a non-nil error.
+func (srv *Server) Serve(l net.Listener) error {
+	return srv.serve(l, nil)
+}
+
+// serve accepts the connections and wraps them in TLS if the config is not nil.
+// The TLS handshake runs after the PROXY protocol header was read.
+func (srv *Server) serve(l net.Listener, tlsConfig *tls.Config) error {
+	if srv.ProxyProtocol != ProxyProtocolOff && len(srv.TrustedProxies) == 0 {
+		l.Close()
+		return ErrNoTrustedProxies
+	}
+	if !srv.trackListener(l, true) {
+		l.Close()
+		return ErrServerClosed
+	}
+	defer srv.trackListener(l, false)
+	defer l.Close()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if srv.isClosed() {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Temporary() {
+				srv.logf("accepting connection failed: %s", err.Error())
+				continue
+			}
+			return err
+		}
+
+		go srv.serveConn(conn, tlsConfig)
+	}
+}
+
+// Close closes all listeners and active connections.
+func (srv *Server) Close() error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.closed = true
+	var firstErr error
+	for l := range srv.listeners {
+		if err := l.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for conn := range srv.conns {
+		conn.Close()
+	}
+	srv.listeners = nil
+	srv.conns = nil
+
+	return firstErr
+}
+
+// ServeConn serves a single connection until it is closed or sends an invalid packet.
+// It can be used to serve connections that were not accepted by Serve.
+// The PROXY protocol header is read if the ProxyProtocol is enabled.
+func (srv *Server) ServeConn(conn net.Conn) {
+	srv.serveConn(conn, nil)
+}
+
+func (srv *Server) serveConn(conn net.Conn, tlsConfig *tls.Config) {
+	if !srv.trackConn(conn, true) {
+		conn.Close()
+		return
+	}
+	defer srv.trackConn(conn, false)
+	defer conn.Close()
+
+	session := &Session{RemoteAddr: conn.RemoteAddr()}
+	if srv.ProxyProtocol != ProxyProtocolOff {
+		var ok bool
+		if conn, ok = srv.readProxyHeader(conn, session); !ok {
+			return
+		}
+	}
+	if tlsConfig != nil {
+		conn = tls.Server(conn, tlsConfig)
+	}
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		state, err := handshake(tlsConn)
+		if err != nil {
+			srv.logf("TLS handshake with %s failed: %s", session.RemoteAddr, err.Error())
+			return
+		}
+		session.TLS = &state
+	}
+	remoteConsole := grcon.NewRemoteConsole(conn)
+
+	for {
+		packet, err := remoteConsole.Read()
+		if err != nil {
+			if err != io.EOF && !srv.isClosed() {
+				srv.logf("reading from %s failed: %s", session.RemoteAddr, err.Error())
+			}
+			return
+		}
+
+		if err := srv.handlePacket(remoteConsole, session, packet); err != nil {
+			srv.logf("connection of %s: %s", session.RemoteAddr, err.Error())
+			return
+		}
+	}
+}
+
+// readProxyHeader reads the PROXY protocol header of trusted sources and updates the address of the session.
+// Returns false if the connection has to be closed.
+func (srv *Server) readProxyHeader(conn net.Conn, session *Session) (net.Conn, bool) {
+	if !srv.trusted(conn.RemoteAddr()) {
+		if srv.ProxyProtocol == ProxyProtocolRequired {
+			srv.logf("connection of untrusted proxy %s rejected", conn.RemoteAddr())
+			return conn, false
+		}
+		return conn, true
+	}
+
+	wrapped, err := ReadProxyHeader(conn)
+	switch {
+	case err == ErrNoProxyHeader && srv.ProxyProtocol == ProxyProtocolOptional:
+		return wrapped, true
+	case err != nil:
+		srv.logf("connection of %s: %s", conn.RemoteAddr(), err.Error())
+		return wrapped, false
+	}
+
+	if wrapped.RemoteAddr() != conn.RemoteAddr() {
+		session.ProxyAddr = conn.RemoteAddr()
+		session.RemoteAddr = wrapped.RemoteAddr()
+	}
+	return wrapped, true
+}
+
+// handshake runs the TLS handshake with a timeout to expose the client certificate to the AuthFunc.
+func handshake(conn *tls.Conn) (tls.ConnectionState, error) {
+	if err := conn.SetDeadline(time.Now().Add(tlsHandshakeTimeout)); err != nil {
+		return tls.ConnectionState{}, err
+	}
+	if err := conn.Handshake(); err != nil {
+		return tls.ConnectionState{}, err
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return tls.ConnectionState{}, err
+	}
+	return conn.ConnectionState(), nil
+}
+
+// handlePacket responds to a single packet.
+// Errors indicate that the connection should be closed.
+func (srv *Server) handlePacket(remoteConsole *grcon.RemoteConsole, session *Session, packet grcon.Packet) error {
+	switch packet.Type {
+	case grcon.SERVERDATA_AUTH:
+		return srv.authenticate(remoteConsole, session, packet)
+	case grcon.SERVERDATA_EXECCOMMAND:
+		if !session.Authenticated {
+			return errors.New("command before authentication")
+		}
+		return srv.execute(remoteConsole, session, packet)
+	case grcon.SERVERDATA_RESPONSE_VALUE:
+		if !session.Authenticated {
+			return errors.New("packet before authentication")
+		}
+		if srv.MirrorEmptyResponse {
+			return remoteConsole.Write(grcon.Packet{Id: packet.Id, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte{}})
+		}
+		return nil
+	}
+
+	return errors.New("unknown packet type")
+}
+
+func (srv *Server) authenticate(remoteConsole *grcon.RemoteConsole, session *Session, packet grcon.Packet) error {
+	var attempt *AuthAttempt
+	if srv.AuthGuard != nil {
+		attempt = srv.AuthGuard.Attempt(session.RemoteAddr)
+	}
+	banned := attempt != nil && attempt.Banned
+	if banned && srv.AuthGuard.Disconnect {
+		return errors.New("authentication of a banned client")
+	}
+
+	user, ok := "", false
+	if srv.Auth != nil && !banned {
+		user, ok = srv.Auth(session, string(packet.Body))
+	}
+
+	if attempt != nil && !banned {
+		attempt.Done(ok)
+		time.Sleep(attempt.Delay)
+	}
+
+	if srv.SendPreAuthResponse {
+		err := remoteConsole.Write(grcon.Packet{Id: packet.Id, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: []byte{}})
+		if err != nil {
+			return err
+		}
+	}
+
+	id := packet.Id
+	// a failed authentication ends an earlier one of the connection.
+	session.User, session.Authenticated = user, ok
+	if !ok {
+		session.User = ""
+		id = -1
+	}
+
+	return remoteConsole.Write(grcon.Packet{Id: id, Type: grcon.SERVERDATA_AUTH_RESPONSE, Body: []byte{}})
+}
+
+func (srv *Server) execute(remoteConsole *grcon.RemoteConsole, session *Session, packet grcon.Packet) error {
+	w := &responseBuffer{}
+	if srv.Handler != nil {
+		srv.Handler.ServeRCON(w, &Request{Id: packet.Id, Command: string(packet.Body), Session: session})
+	}
+
+	for _, body := range splitResponse(w.Bytes(), srv.maxResponseBody()) {
+		err := remoteConsole.Write(grcon.Packet{Id: packet.Id, Type: grcon.SERVERDATA_RESPONSE_VALUE, Body: body})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (srv *Server) maxResponseBody() int {
+	if srv.MaxResponseBody <= 0 || srv.MaxResponseBody > int(grcon.MaxBody) {
+		return int(grcon.MaxBody)
+	}
+	return srv.MaxResponseBody
+}
+
+// splitResponse splits the response into bodies with the maximal size.
+// An empty response results in a single empty body.
+func splitResponse(response []byte, maxSize int) [][]byte {
+	bodies := [][]byte{}
+	for len(response) > maxSize {
+		bodies = append(bodies, response[:maxSize])
+		response = response[maxSize:]
+	}
+	return append(bodies, response)
+}
+
+// trackListener adds or removes the listener. Returns false if the server is closed.
+func (srv *Server) trackListener(l net.Listener, add bool) bool {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if !add {
+		delete(srv.listeners, l)
+		return true
+	}
+	if srv.closed {
+		return false
+	}
+	if srv.listeners == nil {
+		srv.listeners = map[net.Listener]struct{}{}
+	}
+	srv.listeners[l] = struct{}{}
+	return true
+}
+
+// trackConn adds or removes the connection. Returns false if the server is closed.
+func (srv *Server) trackConn(conn net.Conn, add bool) bool {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if !add {
+		delete(srv.conns, conn)
+		return true
+	}
+	if srv.closed {
+		return false
+	}
+	if srv.conns == nil {
+		srv.conns = map[net.Conn]struct{}{}
+	}
+	srv.conns[conn] = struct{}{}
+	return true
+}
+
+func (srv *Server) isClosed() bool {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	return srv.closed
+}
+
+func (srv *Server) logf(format string, args ...interface{}) {
+	if srv.ErrorLog != nil {
+		srv.ErrorLog.Printf(format, args...)
+	}
+}
@@ -0,0 +1,195 @@
+package server_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/server"
+)
+
+// echoHandler responds with the user and the command.
+var echoHandler = server.HandlerFunc(func(w server.ResponseWriter, r *server.Request) {
+	w.Write([]byte(r.Session.User + ": " + r.Command))
+})
+
+// connect serves a loopback TCP connection with the server and returns the RemoteConsole for the client side.
+// A real connection is used instead of net.Pipe because clients write the delimiter packet
+// while the server already writes the response.
+func connect(t *testing.T, srv *server.Server) *grcon.RemoteConsole {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.ServeConn(serverConn)
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return grcon.NewRemoteConsole(conn)
+}
+
+func newIdGenerator() func() grcon.PacketId {
+	id := grcon.PacketId(0)
+	return func() grcon.PacketId {
+		id++
+		return id
+	}
+}
+
+func TestServer(t *testing.T) {
+	auth := func(s *server.Session, password string) (string, bool) {
+		return "alice", password == "secret"
+	}
+
+	t.Run("minecraft client", func(t *testing.T) {
+		srv := &server.Server{Auth: auth, Handler: echoHandler}
+		remoteConsole := connect(t, srv)
+		defer remoteConsole.Conn.Close()
+
+		minecraftClient := client.NewMinecraftClient(remoteConsole, newIdGenerator())
+		if err := minecraftClient.Auth("secret"); err != nil {
+			t.Fatal(err)
+		}
+		got, err := minecraftClient.Exec("list")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "alice: list" {
+			t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", "alice: list", string(got))
+		}
+	})
+
+	t.Run("simple client with multi packet response", func(t *testing.T) {
+		handler := server.HandlerFunc(func(w server.ResponseWriter, r *server.Request) {
+			w.Write([]byte(strings.Repeat("a", 25)))
+		})
+		srv := &server.Server{
+			Auth:                auth,
+			Handler:             handler,
+			MaxResponseBody:     10,
+			SendPreAuthResponse: true,
+			MirrorEmptyResponse: true,
+		}
+		remoteConsole := connect(t, srv)
+		defer remoteConsole.Conn.Close()
+
+		simpleClient := client.NewSimpleClient(remoteConsole, newIdGenerator())
+		if err := simpleClient.Auth("secret"); err != nil {
+			t.Fatal(err)
+		}
+		got, err := simpleClient.Exec("cvarlist")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != strings.Repeat("a", 25) {
+			t.Errorf("response did not match:\nexpected: %s\ngot: %s\n", strings.Repeat("a", 25), string(got))
+		}
+	})
+
+	t.Run("fragments", func(t *testing.T) {
+		handler := server.HandlerFunc(func(w server.ResponseWriter, r *server.Request) {
+			w.Write([]byte(strings.Repeat("a", int(grcon.MaxBody)+1)))
+		})
+		srv := &server.Server{Auth: auth, Handler: handler}
+		remoteConsole := connect(t, srv)
+		defer remoteConsole.Conn.Close()
+
+		minecraftClient := client.NewMinecraftClient(remoteConsole, newIdGenerator())
+		if err := minecraftClient.Auth("secret"); err != nil {
+			t.Fatal(err)
+		}
+		if err := remoteConsole.Write(grcon.Packet{Id: 7, Type: grcon.SERVERDATA_EXECCOMMAND, Body: []byte("x")}); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, expected := range []int{int(grcon.MaxBody), 1} {
+			packet, err := remoteConsole.Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if packet.Id != 7 || len(packet.Body) != expected {
+				t.Errorf("fragment did not match:\nexpected: id 7 with %d bytes\ngot: id %d with %d bytes\n", expected, packet.Id, len(packet.Body))
+			}
+		}
+	})
+
+	t.Run("failed auth", func(t *testing.T) {
+		srv := &server.Server{Auth: auth, Handler: echoHandler}
+		remoteConsole := connect(t, srv)
+		defer remoteConsole.Conn.Close()
+
+		err := client.NewMinecraftClient(remoteConsole, newIdGenerator()).Auth("wrong")
+		if _, ok := err.(client.AuthFailedError); !ok {
+			t.Errorf("error did not match:\nexpected: %T\ngot: %v\n", client.AuthFailedError{}, err)
+		}
+	})
+
+	t.Run("command before auth closes the connection", func(t *testing.T) {
+		srv := &server.Server{Auth: auth, Handler: echoHandler}
+		remoteConsole := connect(t, srv)
+		defer remoteConsole.Conn.Close()
+
+		_, err := client.NewMinecraftClient(remoteConsole, newIdGenerator()).Exec("list")
+		if err == nil {
+			t.Error("expected an error but got nil")
+		}
+	})
+
+	t.Run("serve and close", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv := &server.Server{Auth: server.PasswordAuth("secret"), Handler: echoHandler}
+		done := make(chan error, 1)
+		go func() { done <- srv.Serve(l) }()
+
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		minecraftClient := client.NewMinecraftClient(grcon.NewRemoteConsole(conn), newIdGenerator())
+		if err := minecraftClient.Auth("secret"); err != nil {
+			t.Fatal(err)
+		}
+
+		srv.Close()
+		select {
+		case err := <-done:
+			if err != server.ErrServerClosed {
+				t.Errorf("error did not match:\nexpected: %v\ngot: %v\n", server.ErrServerClosed, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Serve did not return after Close")
+		}
+
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := minecraftClient.Exec("list"); err == nil {
+			t.Error("expected the connection to be closed")
+		}
+	})
+	t.Run("TLS without certificate", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		srv := &server.Server{Auth: server.PasswordAuth("secret"), Handler: echoHandler}
+		if err := srv.ServeTLS(l, "", ""); err == nil || err == server.ErrServerClosed {
+			t.Errorf("expected an error for the missing certificate but got: %v\n", err)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package snbt
+
+import "reflect"
+
+// Unmarshal parses the SNBT data and stores the result in the value pointed to by v.
+// See Decode for the rules of the conversion.
+func Unmarshal(data []byte, v interface{}) error {
+	tag, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+
+	return Decode(tag, v)
+}
+
+// Decode stores the tag in the value pointed to by v.
+//
+// Compounds are decoded into structs or maps with string keys.
+// The key of a struct field is the value of its "snbt" tag or the field name.
+// Fields with the tag "-" are ignored. Keys without a matching field are skipped.
+//
+// Numeric tags can be stored in all integer and float types as long as the value fits.
+// Bytes can also be stored in bools, lists and arrays in slices and
+// any tag in an interface{} or Tag value, which keeps the tag as it is.
+//
+// Returns an UnmarshalTypeError if a tag can not be stored in the target type.
+func Decode(tag Tag, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return InvalidUnmarshalError{GoType: reflect.TypeOf(v)}
+	}
+
+	return decodeValue(tag, rv.Elem(), "")
+}
+
+func decodeValue(tag Tag, rv reflect.Value, field string) error {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeValue(tag, rv.Elem(), field)
+	}
+
+	if rv.Kind() == reflect.Interface {
+		if !reflect.TypeOf(tag).AssignableTo(rv.Type()) {
+			return newUnmarshalTypeError(tag.Type(), rv.Type(), field)
+		}
+		rv.Set(reflect.ValueOf(tag))
+		return nil
+	}
+
+	switch t := tag.(type) {
+	case Byte:
+		if rv.Kind() == reflect.Bool {
+			rv.SetBool(t != 0)
+			return nil
+		}
+		return decodeInt(int64(t), tag, rv, field)
+	case Short:
+		return decodeInt(int64(t), tag, rv, field)
+	case Int:
+		return decodeInt(int64(t), tag, rv, field)
+	case Long:
+		return decodeInt(int64(t), tag, rv, field)
+	case Float:
+		return decodeFloat(float64(t), tag, rv, field)
+	case Double:
+		return decodeFloat(float64(t), tag, rv, field)
+	case String:
+		if rv.Kind() != reflect.String {
+			return newUnmarshalTypeError(tag.Type(), rv.Type(), field)
+		}
+		rv.SetString(string(t))
+		return nil
+	case List:
+		return decodeList(t, rv, field)
+	case ByteArray:
+		list := make(List, len(t))
+		for i, b := range t {
+			list[i] = Byte(b)
+		}
+		return decodeList(list, rv, field)
+	case IntArray:
+		list := make(List, len(t))
+		for i, n := range t {
+			list[i] = Int(n)
+		}
+		return decodeList(list, rv, field)
+	case LongArray:
+		list := make(List, len(t))
+		for i, n := range t {
+			list[i] = Long(n)
+		}
+		return decodeList(list, rv, field)
+	case Compound:
+		return decodeCompound(t, rv, field)
+	}
+
+	return newUnmarshalTypeError(tag.Type(), rv.Type(), field)
+}
+
+func decodeInt(n int64, tag Tag, rv reflect.Value, field string) error {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.OverflowInt(n) {
+			return newUnmarshalTypeError(tag.Type(), rv.Type(), field)
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n < 0 || rv.OverflowUint(uint64(n)) {
+			return newUnmarshalTypeError(tag.Type(), rv.Type(), field)
+		}
+		rv.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		rv.SetFloat(float64(n))
+	default:
+		return newUnmarshalTypeError(tag.Type(), rv.Type(), field)
+	}
+	return nil
+}
+
+func decodeFloat(f float64, tag Tag, rv reflect.Value, field string) error {
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		rv.SetFloat(f)
+		return nil
+	}
+	return newUnmarshalTypeError(tag.Type(), rv.Type(), field)
+}
+
+func decodeList(list List, rv reflect.Value, field string) error {
+	switch rv.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(rv.Type(), len(list), len(list))
+		for i, tag := range list {
+			if err := decodeValue(tag, slice.Index(i), field); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+		return nil
+	case reflect.Array:
+		if rv.Len() != len(list) {
+			return newUnmarshalTypeError(TagList, rv.Type(), field)
+		}
+		for i, tag := range list {
+			if err := decodeValue(tag, rv.Index(i), field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return newUnmarshalTypeError(TagList, rv.Type(), field)
+}
+
+func decodeCompound(compound Compound, rv reflect.Value, field string) error {
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return newUnmarshalTypeError(TagCompound, rv.Type(), field)
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(compound)))
+		}
+		for key, tag := range compound {
+			value := reflect.New(rv.Type().Elem()).Elem()
+			if err := decodeValue(tag, value, joinField(field, key)); err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()), value)
+		}
+		return nil
+	case reflect.Struct:
+		typ := rv.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			structField := typ.Field(i)
+			if structField.PkgPath != "" {
+				// unexported field
+				continue
+			}
+
+			key := structField.Name
+			if name, ok := structField.Tag.Lookup("snbt"); ok {
+				if name == "-" {
+					continue
+				}
+				if name != "" {
+					key = name
+				}
+			}
+
+			tag, ok := compound[key]
+			if !ok {
+				continue
+			}
+			if err := decodeValue(tag, rv.Field(i), joinField(field, structField.Name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return newUnmarshalTypeError(TagCompound, rv.Type(), field)
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,83 @@
+package snbt_test
+
+import (
+	"testing"
+
+	"github.com/hamburghammer/grcon/snbt"
+)
+
+type item struct {
+	Count int    `snbt:"Count"`
+	Slot  int    `snbt:"Slot"`
+	ID    string `snbt:"id"`
+}
+
+type player struct {
+	Health    float32
+	Pos       [3]float64
+	UUID      []int32
+	OnGround  bool
+	Inventory []item
+	Abilities map[string]snbt.Tag `snbt:"abilities"`
+	Ignored   string              `snbt:"-"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		data := `{Health: 20.0f, Pos: [0.5d, 64.0d, -2.5d], UUID: [I; 1, 2, 3, 4], OnGround: 1b,
+			Inventory: [{Count: 64b, Slot: 0b, id: "minecraft:stone"}], abilities: {flying: 0b, walkSpeed: 0.1f},
+			Ignored: "foo", Unknown: 1}`
+
+		var got player
+		err := snbt.Unmarshal([]byte(data), &got)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		if got.Health != 20 || got.Pos != [3]float64{0.5, 64, -2.5} || !got.OnGround {
+			t.Errorf("player did not match: %+v\n", got)
+		}
+		if len(got.UUID) != 4 || got.UUID[3] != 4 {
+			t.Errorf("uuid did not match: %v\n", got.UUID)
+		}
+		if len(got.Inventory) != 1 || got.Inventory[0] != (item{Count: 64, Slot: 0, ID: "minecraft:stone"}) {
+			t.Errorf("inventory did not match: %+v\n", got.Inventory)
+		}
+		if got.Abilities["walkSpeed"] != snbt.Float(0.1) {
+			t.Errorf("abilities did not match: %+v\n", got.Abilities)
+		}
+		if got.Ignored != "" {
+			t.Errorf("ignored field was set: %s\n", got.Ignored)
+		}
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		var got player
+		err := snbt.Unmarshal([]byte(`{Inventory: [{Count: "many"}]}`), &got)
+
+		typeErr, ok := err.(snbt.UnmarshalTypeError)
+		if !ok {
+			t.Fatalf("expected: UnmarshalTypeError\ngot: %T\n", err)
+		}
+		if typeErr.Field != "Inventory.Count" {
+			t.Errorf("field did not match:\nexpected: %s\ngot: %s\n", "Inventory.Count", typeErr.Field)
+		}
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		var got int8
+		err := snbt.Unmarshal([]byte(`300`), &got)
+		if _, ok := err.(snbt.UnmarshalTypeError); !ok {
+			t.Errorf("expected: UnmarshalTypeError\ngot: %T\n", err)
+		}
+	})
+
+	t.Run("non pointer", func(t *testing.T) {
+		var got player
+		err := snbt.Unmarshal([]byte(`{}`), got)
+		if _, ok := err.(snbt.InvalidUnmarshalError); !ok {
+			t.Errorf("expected: InvalidUnmarshalError\ngot: %T\n", err)
+		}
+	})
+}
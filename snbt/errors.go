@@ -0,0 +1,54 @@
+package snbt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+func newSyntaxError(offset int, msg string) SyntaxError {
+	return SyntaxError{Offset: offset, Msg: msg}
+}
+
+// SyntaxError occurres when the input is no valid SNBT.
+type SyntaxError struct {
+	// Offset is the byte offset in the input where the error was detected.
+	Offset int
+	Msg    string
+}
+
+func (se SyntaxError) Error() string {
+	return fmt.Sprintf("grcon-snbt: syntax error at offset %d: %s", se.Offset, se.Msg)
+}
+
+func newUnmarshalTypeError(tag TagType, typ reflect.Type, field string) UnmarshalTypeError {
+	return UnmarshalTypeError{Tag: tag, GoType: typ, Field: field}
+}
+
+// UnmarshalTypeError occurres when a tag can not be stored in the Go value.
+type UnmarshalTypeError struct {
+	// Tag is the type of the tag that should be decoded.
+	Tag TagType
+	// GoType is the type of the target value.
+	GoType reflect.Type
+	// Field is the path to the field in the Go value, empty for the root value.
+	Field string
+}
+
+func (ute UnmarshalTypeError) Error() string {
+	if ute.Field == "" {
+		return fmt.Sprintf("grcon-snbt: cannot unmarshal %s into Go value of type %s", ute.Tag, ute.GoType)
+	}
+	return fmt.Sprintf("grcon-snbt: cannot unmarshal %s into Go struct field %s of type %s", ute.Tag, ute.Field, ute.GoType)
+}
+
+// InvalidUnmarshalError occurres when a nil or non-pointer value is passed to Unmarshal or Decode.
+type InvalidUnmarshalError struct {
+	GoType reflect.Type
+}
+
+func (iue InvalidUnmarshalError) Error() string {
+	if iue.GoType == nil {
+		return "grcon-snbt: Unmarshal(nil)"
+	}
+	return fmt.Sprintf("grcon-snbt: Unmarshal(non-pointer %s)", iue.GoType)
+}
@@ -0,0 +1,362 @@
+package snbt
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Parse parses the SNBT string into a tree of tags.
+// Leading and trailing whitespace is ignored.
+//
+// Returns a SyntaxError if the string is no valid SNBT.
+func Parse(s string) (Tag, error) {
+	p := &parser{data: s}
+
+	p.skipWhitespace()
+	tag, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipWhitespace()
+	if p.pos < len(p.data) {
+		return nil, newSyntaxError(p.pos, "unexpected data after the value")
+	}
+
+	return tag, nil
+}
+
+// parser holds the state of a single Parse call.
+type parser struct {
+	data string
+	pos  int
+}
+
+func (p *parser) skipWhitespace() {
+	for p.pos < len(p.data) && strings.IndexByte(" \t\r\n", p.data[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// peek returns the current byte or 0 at the end of the input.
+func (p *parser) peek() byte {
+	if p.pos >= len(p.data) {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+// expect consumes the byte or returns a SyntaxError.
+func (p *parser) expect(c byte) error {
+	if p.peek() != c {
+		return newSyntaxError(p.pos, "expected '"+string(c)+"'")
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) parseValue() (Tag, error) {
+	switch c := p.peek(); c {
+	case 0:
+		return nil, newSyntaxError(p.pos, "unexpected end of input")
+	case '{':
+		return p.parseCompound()
+	case '[':
+		return p.parseList()
+	case '"', '\'':
+		s, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return String(s), nil
+	default:
+		start := p.pos
+		s := p.parseUnquoted()
+		if s == "" {
+			return nil, newSyntaxError(start, "unexpected character '"+string(c)+"'")
+		}
+		return parseScalar(s), nil
+	}
+}
+
+func (p *parser) parseCompound() (Tag, error) {
+	// skip '{'
+	p.pos++
+	compound := Compound{}
+
+	p.skipWhitespace()
+	if p.peek() == '}' {
+		p.pos++
+		return compound, nil
+	}
+
+	for {
+		p.skipWhitespace()
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipWhitespace()
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+
+		p.skipWhitespace()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		compound[key] = value
+
+		p.skipWhitespace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return compound, nil
+		default:
+			return nil, newSyntaxError(p.pos, "expected ',' or '}' in compound")
+		}
+	}
+}
+
+func (p *parser) parseKey() (string, error) {
+	if c := p.peek(); c == '"' || c == '\'' {
+		return p.parseQuoted()
+	}
+
+	start := p.pos
+	key := p.parseUnquoted()
+	if key == "" {
+		return "", newSyntaxError(start, "expected key")
+	}
+	return key, nil
+}
+
+func (p *parser) parseList() (Tag, error) {
+	start := p.pos
+	// skip '['
+	p.pos++
+
+	// typed arrays start with the type character and a semicolon: [I; 1, 2]
+	if p.pos+1 < len(p.data) && p.data[p.pos+1] == ';' {
+		arrayType := p.data[p.pos]
+		p.pos += 2
+		return p.parseArray(start, arrayType)
+	}
+
+	list := List{}
+	p.skipWhitespace()
+	if p.peek() == ']' {
+		p.pos++
+		return list, nil
+	}
+
+	for {
+		p.skipWhitespace()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+
+		p.skipWhitespace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return list, nil
+		default:
+			return nil, newSyntaxError(p.pos, "expected ',' or ']' in list")
+		}
+	}
+}
+
+// parseArray parses the values of a typed array after the "X;" prefix.
+func (p *parser) parseArray(start int, arrayType byte) (Tag, error) {
+	var bytes ByteArray
+	var ints IntArray
+	var longs LongArray
+
+	switch arrayType {
+	case 'B':
+		bytes = ByteArray{}
+	case 'I':
+		ints = IntArray{}
+	case 'L':
+		longs = LongArray{}
+	default:
+		return nil, newSyntaxError(start+1, "invalid array type '"+string(arrayType)+"'")
+	}
+
+	p.skipWhitespace()
+	if p.peek() == ']' {
+		p.pos++
+	} else {
+		for {
+			p.skipWhitespace()
+			valueStart := p.pos
+			value := parseScalar(p.parseUnquoted())
+
+			switch v := value.(type) {
+			case Byte:
+				if bytes == nil {
+					return nil, newSyntaxError(valueStart, "unexpected byte in array")
+				}
+				bytes = append(bytes, int8(v))
+			case Int:
+				if ints != nil {
+					ints = append(ints, int32(v))
+				} else if longs != nil {
+					longs = append(longs, int64(v))
+				} else if v >= -128 && v <= 127 {
+					bytes = append(bytes, int8(v))
+				} else {
+					return nil, newSyntaxError(valueStart, "byte out of range in array")
+				}
+			case Long:
+				if longs == nil {
+					return nil, newSyntaxError(valueStart, "unexpected long in array")
+				}
+				longs = append(longs, int64(v))
+			default:
+				return nil, newSyntaxError(valueStart, "expected integer in array")
+			}
+
+			p.skipWhitespace()
+			if p.peek() == ']' {
+				p.pos++
+				break
+			}
+			if err := p.expect(','); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	switch arrayType {
+	case 'B':
+		return bytes, nil
+	case 'I':
+		return ints, nil
+	default:
+		return longs, nil
+	}
+}
+
+// parseQuoted parses a string in single or double quotes with backslash escapes.
+func (p *parser) parseQuoted() (string, error) {
+	start := p.pos
+	quote := p.data[p.pos]
+	p.pos++
+
+	var b strings.Builder
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '\\':
+			if p.pos+1 >= len(p.data) {
+				return "", newSyntaxError(p.pos, "unterminated escape sequence")
+			}
+			next := p.data[p.pos+1]
+			if next != '\\' && next != '"' && next != '\'' {
+				return "", newSyntaxError(p.pos, "invalid escape sequence")
+			}
+			b.WriteByte(next)
+			p.pos += 2
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", newSyntaxError(start, "unterminated string")
+}
+
+// parseUnquoted consumes all characters that are allowed in unquoted strings.
+func (p *parser) parseUnquoted() string {
+	start := p.pos
+	for p.pos < len(p.data) && isUnquotedChar(p.data[p.pos]) {
+		p.pos++
+	}
+	return p.data[start:p.pos]
+}
+
+func isUnquotedChar(c byte) bool {
+	return c >= '0' && c <= '9' ||
+		c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' ||
+		c == '_' || c == '-' || c == '.' || c == '+'
+}
+
+// parseScalar interprets an unquoted string as number, boolean or string.
+func parseScalar(s string) Tag {
+	switch s {
+	case "true":
+		return Byte(1)
+	case "false":
+		return Byte(0)
+	}
+
+	if len(s) > 1 {
+		number := s[:len(s)-1]
+		switch s[len(s)-1] {
+		case 'b', 'B':
+			if v, err := strconv.ParseInt(number, 10, 8); err == nil {
+				return Byte(v)
+			}
+		case 's', 'S':
+			if v, err := strconv.ParseInt(number, 10, 16); err == nil {
+				return Short(v)
+			}
+		case 'l', 'L':
+			if v, err := strconv.ParseInt(number, 10, 64); err == nil {
+				return Long(v)
+			}
+		case 'f', 'F':
+			if isDecimal(number) {
+				if v, err := strconv.ParseFloat(number, 32); err == nil {
+					return Float(v)
+				}
+			}
+		case 'd', 'D':
+			if isDecimal(number) {
+				if v, err := strconv.ParseFloat(number, 64); err == nil {
+					return Double(v)
+				}
+			}
+		}
+	}
+
+	if v, err := strconv.ParseInt(s, 10, 32); err == nil {
+		return Int(v)
+	}
+	if strings.ContainsAny(s, ".eE") && isDecimal(s) {
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return Double(v)
+		}
+	}
+
+	return String(s)
+}
+
+// isDecimal reports if the string only contains characters of a decimal number.
+// It prevents strconv from accepting values like "Inf" or hex floats.
+func isDecimal(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c == '.' || c == '-' || c == '+' || c == 'e' || c == 'E') {
+			return false
+		}
+	}
+	return true
+}
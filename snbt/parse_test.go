@@ -0,0 +1,70 @@
+package snbt_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hamburghammer/grcon/snbt"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect snbt.Tag
+	}{
+		{name: "byte", input: "1b", expect: snbt.Byte(1)},
+		{name: "boolean", input: "true", expect: snbt.Byte(1)},
+		{name: "short", input: "-3s", expect: snbt.Short(-3)},
+		{name: "int", input: "42", expect: snbt.Int(42)},
+		{name: "long", input: "9000000000L", expect: snbt.Long(9000000000)},
+		{name: "float", input: "20.0f", expect: snbt.Float(20)},
+		{name: "double with suffix", input: "0.5d", expect: snbt.Double(0.5)},
+		{name: "double without suffix", input: "1.5", expect: snbt.Double(1.5)},
+		{name: "unquoted string", input: "stone", expect: snbt.String("stone")},
+		{name: "double quoted string", input: `"minecraft:stone"`, expect: snbt.String("minecraft:stone")},
+		{name: "single quoted string with escapes", input: `'It\'s a \"test\"'`, expect: snbt.String(`It's a "test"`)},
+		{name: "empty list", input: "[]", expect: snbt.List{}},
+		{name: "list", input: "[0.5d, 64.0d, 0.5d]", expect: snbt.List{snbt.Double(0.5), snbt.Double(64), snbt.Double(0.5)}},
+		{name: "byte array", input: "[B; 1b, 2b]", expect: snbt.ByteArray{1, 2}},
+		{name: "int array", input: "[I; -1, 2, 3, 4]", expect: snbt.IntArray{-1, 2, 3, 4}},
+		{name: "long array", input: "[L;1l,2l]", expect: snbt.LongArray{1, 2}},
+		{
+			name:  "compound",
+			input: `{Health: 20.0f, "quoted key": 1b, Inventory: [{Count: 1b, Slot: 0b, id: "minecraft:stone"}]}`,
+			expect: snbt.Compound{
+				"Health":     snbt.Float(20),
+				"quoted key": snbt.Byte(1),
+				"Inventory": snbt.List{
+					snbt.Compound{"Count": snbt.Byte(1), "Slot": snbt.Byte(0), "id": snbt.String("minecraft:stone")},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got, err := snbt.Parse(test.input)
+			if err != nil {
+				t.Error(err)
+				t.FailNow()
+			}
+
+			if !reflect.DeepEqual(test.expect, got) {
+				t.Errorf("parsed tag did not match:\nexpected: %#v\ngot: %#v\n", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+	inputs := []string{"", "{", "{Health 20.0f}", "[1, 2", `"unterminated`, "[X; 1]", "[I; 1b]", "{a: 1} b", "minecraft:stone"}
+
+	for _, input := range inputs {
+		_, err := snbt.Parse(input)
+		if _, ok := err.(snbt.SyntaxError); !ok {
+			t.Errorf("expected SyntaxError for %q\ngot: %T (%v)\n", input, err, err)
+		}
+	}
+}
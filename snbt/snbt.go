@@ -0,0 +1,132 @@
+/*
+Package snbt decodes stringified NBT (SNBT) as it is returned by the data commands of a minecraft server.
+
+A response like "{Health: 20.0f, Pos: [0.5d, 64.0d, 0.5d]}" gets parsed into a tree of Tags
+that keep the NBT type of every value. The tree can be used directly or be decoded into
+Go structs with the help of the "snbt" struct tag.
+
+Format description: https://minecraft.wiki/w/NBT_format#SNBT_format
+*/
+package snbt
+
+// TagType is the NBT type of a Tag.
+type TagType byte
+
+// NBT tag types.
+const (
+	TagByte TagType = iota + 1
+	TagShort
+	TagInt
+	TagLong
+	TagFloat
+	TagDouble
+	TagByteArray
+	TagString
+	TagList
+	TagCompound
+	TagIntArray
+	TagLongArray
+)
+
+var tagTypeNames = map[TagType]string{
+	TagByte:      "byte",
+	TagShort:     "short",
+	TagInt:       "int",
+	TagLong:      "long",
+	TagFloat:     "float",
+	TagDouble:    "double",
+	TagByteArray: "byte array",
+	TagString:    "string",
+	TagList:      "list",
+	TagCompound:  "compound",
+	TagIntArray:  "int array",
+	TagLongArray: "long array",
+}
+
+// String returns the human readable name of the type.
+func (t TagType) String() string {
+	if name, ok := tagTypeNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Tag is a node of a parsed SNBT tree.
+type Tag interface {
+	// Type returns the NBT type of the tag.
+	Type() TagType
+}
+
+// Byte is a signed 8 bit integer with the suffix 'b'.
+// The boolean values true and false are parsed as Byte 1 and 0.
+type Byte int8
+
+// Short is a signed 16 bit integer with the suffix 's'.
+type Short int16
+
+// Int is a signed 32 bit integer without suffix.
+type Int int32
+
+// Long is a signed 64 bit integer with the suffix 'l'.
+type Long int64
+
+// Float is a 32 bit floating point number with the suffix 'f'.
+type Float float32
+
+// Double is a 64 bit floating point number with the suffix 'd' or
+// a decimal number without suffix.
+type Double float64
+
+// String is a quoted or unquoted string.
+type String string
+
+// List is an ordered list of tags.
+type List []Tag
+
+// Compound maps the keys to their tags.
+type Compound map[string]Tag
+
+// ByteArray is written as [B; 1b, 2b].
+type ByteArray []int8
+
+// IntArray is written as [I; 1, 2].
+type IntArray []int32
+
+// LongArray is written as [L; 1l, 2l].
+type LongArray []int64
+
+// Type returns TagByte.
+func (Byte) Type() TagType { return TagByte }
+
+// Type returns TagShort.
+func (Short) Type() TagType { return TagShort }
+
+// Type returns TagInt.
+func (Int) Type() TagType { return TagInt }
+
+// Type returns TagLong.
+func (Long) Type() TagType { return TagLong }
+
+// Type returns TagFloat.
+func (Float) Type() TagType { return TagFloat }
+
+// Type returns TagDouble.
+func (Double) Type() TagType { return TagDouble }
+
+// Type returns TagString.
+func (String) Type() TagType { return TagString }
+
+// Type returns TagList.
+func (List) Type() TagType { return TagList }
+
+// Type returns TagCompound.
+func (Compound) Type() TagType { return TagCompound }
+
+// Type returns TagByteArray.
+func (ByteArray) Type() TagType { return TagByteArray }
+
+// Type returns TagIntArray.
+func (IntArray) Type() TagType { return TagIntArray }
+
+// Type returns TagLongArray.
+func (LongArray) Type() TagType { return TagLongArray }
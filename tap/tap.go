@@ -0,0 +1,295 @@
+/*
+Package tap implements a man-in-the-middle proxy that decodes the RCON traffic.
+
+The Tap forwards all bytes unchanged between the clients and the upstream
+server and decodes both directions with a grcon.RemoteConsole.
+Every decoded packet is passed as Event to the Handler:
+
+	t := &tap.Tap{
+		Dial: client.TCPDialer("127.0.0.1:27015", 5*time.Second),
+		Handler: func(e tap.Event) {
+			fmt.Println(e.Direction, e.Id, e.TypeName, string(e.Body))
+		},
+	}
+	log.Fatal(t.ListenAndServe(":27016"))
+*/
+package tap
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+)
+
+// Direction of a packet.
+type Direction string
+
+// Directions of the traffic.
+const (
+	ClientToServer Direction = "client->server"
+	ServerToClient Direction = "server->client"
+)
+
+// Event describes a decoded packet.
+type Event struct {
+	// Session is the number of the client connection starting at 1.
+	Session   int       `json:"session"`
+	Direction Direction `json:"direction"`
+	// Time when the last byte of the packet was received.
+	Time     time.Time        `json:"time"`
+	Id       grcon.PacketId   `json:"id"`
+	Type     grcon.PacketType `json:"type"`
+	TypeName string           `json:"type_name"`
+	Body     []byte           `json:"body"`
+	// Size of the packet on the wire including the size field.
+	Size int `json:"size"`
+	// Fragments is the number of reads the packet was received in.
+	// More than one means that the packet got split by the network or the sender.
+	Fragments int `json:"fragments"`
+	// Latency is the time since the last packet of the client with the same id.
+	// It is only set for packets of the server.
+	Latency time.Duration `json:"latency,omitempty"`
+}
+
+// TypeName returns the name of the packet type.
+// The name depends on the direction because SERVERDATA_EXECCOMMAND and SERVERDATA_AUTH_RESPONSE share the value 2.
+func TypeName(d Direction, t grcon.PacketType) string {
+	switch {
+	case t == grcon.SERVERDATA_AUTH:
+		return "SERVERDATA_AUTH"
+	case t == grcon.SERVERDATA_RESPONSE_VALUE:
+		return "SERVERDATA_RESPONSE_VALUE"
+	case t == grcon.SERVERDATA_EXECCOMMAND && d == ClientToServer:
+		return "SERVERDATA_EXECCOMMAND"
+	case t == grcon.SERVERDATA_AUTH_RESPONSE && d == ServerToClient:
+		return "SERVERDATA_AUTH_RESPONSE"
+	}
+	return "UNKNOWN"
+}
+
+// Tap forwards the connections of clients to the upstream server and decodes the traffic.
+type Tap struct {
+	// Dial opens the connections to the upstream server.
+	Dial client.DialFunc
+	// Handler is called for every decoded packet. The calls are serialized.
+	Handler func(Event)
+	// Logger for the connection log and decoding errors. Nothing is logged if it is nil.
+	Logger *log.Logger
+
+	mutex    sync.Mutex
+	sessions int
+}
+
+// ListenAndServe listens on the TCP address and serves the connections.
+func (t *Tap) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return t.Serve(l)
+}
+
+// Serve accepts connections on the listener and serves each of them in a new goroutine.
+func (t *Tap) Serve(l net.Listener) error {
+	defer l.Close()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		go t.ServeConn(conn)
+	}
+}
+
+// ServeConn forwards the client connection to a new upstream connection until one of them gets closed.
+func (t *Tap) ServeConn(conn net.Conn) {
+	defer conn.Close()
+
+	t.mutex.Lock()
+	t.sessions++
+	s := &session{tap: t, id: t.sessions, requests: map[grcon.PacketId]time.Time{}}
+	t.mutex.Unlock()
+
+	upstream, err := t.Dial()
+	if err != nil {
+		t.logf("session %d: connecting to the upstream failed: %s", s.id, err.Error())
+		return
+	}
+	defer upstream.Close()
+
+	t.logf("session %d: opened from %s", s.id, conn.RemoteAddr())
+	defer t.logf("session %d: closed", s.id)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		s.forward(upstream, conn, ClientToServer)
+		done <- struct{}{}
+	}()
+	go func() {
+		s.forward(conn, upstream, ServerToClient)
+		done <- struct{}{}
+	}()
+
+	// closing both connections after the first direction ended stops the other one.
+	<-done
+	conn.Close()
+	upstream.Close()
+	<-done
+}
+
+func (t *Tap) emit(e Event) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.Handler != nil {
+		t.Handler(e)
+	}
+}
+
+func (t *Tap) logf(format string, args ...interface{}) {
+	if t.Logger != nil {
+		t.Logger.Printf(format, args...)
+	}
+}
+
+// session holds the state of a single client connection.
+type session struct {
+	tap *Tap
+	id  int
+
+	mutex sync.Mutex
+	// requests maps the packet ids of the client to the time they were received.
+	requests map[grcon.PacketId]time.Time
+}
+
+// forward copies the bytes from src to dst and decodes them.
+func (s *session) forward(dst, src net.Conn, d Direction) {
+	stream := &stream{}
+	decoderIn, decoderOut := net.Pipe()
+	defer decoderIn.Close()
+
+	decoded := make(chan struct{})
+	go func() {
+		defer close(decoded)
+		s.decode(decoderOut, stream, d)
+	}()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			stream.add(n, time.Now())
+			if _, err := dst.Write(buf[:n]); err != nil {
+				break
+			}
+			// errors mean that decoding stopped, the traffic is forwarded anyway.
+			decoderIn.Write(buf[:n])
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	decoderIn.Close()
+	<-decoded
+}
+
+// decode reads the packets from the conn until it gets closed.
+func (s *session) decode(conn net.Conn, stream *stream, d Direction) {
+	defer conn.Close()
+	// minecraft servers send the fragments of long responses in packets longer than the grcon.MaxPacket.
+	remoteConsole := grcon.NewRemoteConsoleSize(conn, client.MinecraftMaxPacket)
+
+	for {
+		packet, err := remoteConsole.Read()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrClosedPipe) {
+				s.tap.logf("session %d: decoding %s stopped: %s", s.id, d, err.Error())
+			}
+			return
+		}
+
+		size := len(packet.Body) + int(grcon.MinPacket) + 4
+		fragments, received := stream.consume(size)
+		e := Event{
+			Session:   s.id,
+			Direction: d,
+			Time:      received,
+			Id:        packet.Id,
+			Type:      packet.Type,
+			TypeName:  TypeName(d, packet.Type),
+			Body:      packet.Body,
+			Size:      size,
+			Fragments: fragments,
+		}
+
+		s.mutex.Lock()
+		if d == ClientToServer {
+			s.requests[packet.Id] = received
+		} else if requested, ok := s.requests[packet.Id]; ok {
+			e.Latency = received.Sub(requested)
+		}
+		s.mutex.Unlock()
+
+		s.tap.emit(e)
+	}
+}
+
+// stream tracks the reads of a direction to map the decoded packets back to them.
+type stream struct {
+	mutex sync.Mutex
+	// chunks are the reads that were not completely consumed by decoded packets.
+	chunks []chunk
+	// end is the offset after the last read.
+	end int64
+	// decoded is the offset after the last decoded packet.
+	decoded int64
+}
+
+type chunk struct {
+	start, end int64
+	time       time.Time
+}
+
+func (s *stream) add(n int, t time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.chunks = append(s.chunks, chunk{start: s.end, end: s.end + int64(n), time: t})
+	s.end += int64(n)
+}
+
+// consume marks the next size bytes as decoded and returns the number of
+// reads they were part of and the time of the last one.
+func (s *stream) consume(size int) (int, time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	start := s.decoded
+	s.decoded += int64(size)
+
+	fragments := 0
+	var received time.Time
+	remaining := s.chunks[:0]
+	for _, c := range s.chunks {
+		if c.end > start && c.start < s.decoded {
+			fragments++
+			received = c.time
+		}
+		if c.end > s.decoded {
+			remaining = append(remaining, c)
+		}
+	}
+	s.chunks = remaining
+
+	return fragments, received
+}
@@ -0,0 +1,156 @@
+package tap_test
+
+import (
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/emulator"
+	"github.com/hamburghammer/grcon/server"
+	"github.com/hamburghammer/grcon/tap"
+	"github.com/hamburghammer/grcon/util"
+)
+
+func listen(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func TestTap(t *testing.T) {
+	upstreamListener := listen(t)
+	upstream := &server.Server{
+		Auth: server.PasswordAuth("secret"),
+		Handler: server.HandlerFunc(func(w server.ResponseWriter, r *server.Request) {
+			io.WriteString(w, "echo: "+r.Command)
+		}),
+	}
+	go upstream.Serve(upstreamListener)
+	defer upstream.Close()
+
+	events := make(chan tap.Event, 10)
+	tapListener := listen(t)
+	tp := &tap.Tap{
+		Dial:    client.TCPDialer(upstreamListener.Addr().String(), time.Second),
+		Handler: func(e tap.Event) { events <- e },
+	}
+	go tp.Serve(tapListener)
+
+	conn, err := net.Dial("tcp", tapListener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	remoteConsole := grcon.NewRemoteConsole(conn)
+
+	id := grcon.PacketId(0)
+	minecraftClient := client.NewMinecraftClient(remoteConsole, func() grcon.PacketId {
+		id++
+		return id
+	})
+	if err := minecraftClient.Auth("secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// write a command packet in two parts to split it inside of the size field.
+	packet := []byte{14, 0, 0, 0, 2, 0, 0, 0, 2, 0, 0, 0, 'l', 'i', 's', 't', 0, 0}
+	if _, err := conn.Write(packet[:2]); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, err := conn.Write(packet[2:]); err != nil {
+		t.Fatal(err)
+	}
+	response, err := remoteConsole.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(response.Body) != "echo: list" {
+		t.Errorf("forwarded response did not match:\nexpected: %s\ngot: %s\n", "echo: list", string(response.Body))
+	}
+
+	expected := []tap.Event{
+		{Session: 1, Direction: tap.ClientToServer, Id: 1, TypeName: "SERVERDATA_AUTH", Body: []byte("secret"), Size: 20, Fragments: 1},
+		{Session: 1, Direction: tap.ServerToClient, Id: 1, TypeName: "SERVERDATA_AUTH_RESPONSE", Body: []byte{}, Size: 14, Fragments: 1},
+		{Session: 1, Direction: tap.ClientToServer, Id: 2, TypeName: "SERVERDATA_EXECCOMMAND", Body: []byte("list"), Size: 18, Fragments: 2},
+		{Session: 1, Direction: tap.ServerToClient, Id: 2, TypeName: "SERVERDATA_RESPONSE_VALUE", Body: []byte("echo: list"), Size: 24, Fragments: 1},
+	}
+	for _, expect := range expected {
+		var got tap.Event
+		select {
+		case got = <-events:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("missing event: %+v\n", expect)
+		}
+
+		if got.Session != expect.Session || got.Direction != expect.Direction || got.Id != expect.Id ||
+			got.TypeName != expect.TypeName || string(got.Body) != string(expect.Body) ||
+			got.Size != expect.Size || got.Fragments != expect.Fragments {
+			t.Errorf("event did not match:\nexpected: %+v\ngot: %+v\n", expect, got)
+		}
+		if got.Direction == tap.ServerToClient && got.Latency < 0 {
+			t.Errorf("latency is negative: %s\n", got.Latency)
+		}
+	}
+}
+
+func TestTap_MinecraftFragments(t *testing.T) {
+	upstreamListener := listen(t)
+	mc := emulator.NewMinecraft("secret")
+	mc.HandleFunc("dump", func(args string) string { return strings.Repeat("é", 5000) })
+	go mc.Serve(upstreamListener)
+	defer mc.Close()
+
+	events := make(chan tap.Event, 10)
+	tapListener := listen(t)
+	tp := &tap.Tap{
+		Dial:    client.TCPDialer(upstreamListener.Addr().String(), time.Second),
+		Handler: func(e tap.Event) { events <- e },
+	}
+	go tp.Serve(tapListener)
+
+	conn, err := net.Dial("tcp", tapListener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	minecraftClient := client.NewMinecraftClient(grcon.NewRemoteConsoleSize(conn, client.MinecraftMaxPacket), util.GenerateRequestId)
+	if err := minecraftClient.Auth("secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := minecraftClient.Exec("dump"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first fragment has 4096 characters with 2 bytes each.
+	expectSize := 2*4096 + 14
+	for {
+		select {
+		case got := <-events:
+			if got.Direction == tap.ServerToClient && got.Size == expectSize {
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("missing event of the first fragment with the size %d\n", expectSize)
+		}
+	}
+}
+
+func TestTypeName(t *testing.T) {
+	if got := tap.TypeName(tap.ClientToServer, 2); got != "SERVERDATA_EXECCOMMAND" {
+		t.Errorf("type name did not match:\nexpected: %s\ngot: %s\n", "SERVERDATA_EXECCOMMAND", got)
+	}
+	if got := tap.TypeName(tap.ServerToClient, 2); got != "SERVERDATA_AUTH_RESPONSE" {
+		t.Errorf("type name did not match:\nexpected: %s\ngot: %s\n", "SERVERDATA_AUTH_RESPONSE", got)
+	}
+}
@@ -0,0 +1,148 @@
+package text
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Color is a named color or a hex color in the format "#RRGGBB".
+type Color string
+
+// Named colors of minecraft.
+const (
+	Black       Color = "black"
+	DarkBlue    Color = "dark_blue"
+	DarkGreen   Color = "dark_green"
+	DarkAqua    Color = "dark_aqua"
+	DarkRed     Color = "dark_red"
+	DarkPurple  Color = "dark_purple"
+	Gold        Color = "gold"
+	Gray        Color = "gray"
+	DarkGray    Color = "dark_gray"
+	Blue        Color = "blue"
+	Green       Color = "green"
+	Aqua        Color = "aqua"
+	Red         Color = "red"
+	LightPurple Color = "light_purple"
+	Yellow      Color = "yellow"
+	White       Color = "white"
+)
+
+// ClickAction is the action that gets executed if a player clicks on a component.
+type ClickAction string
+
+// Click actions.
+const (
+	OpenURL         ClickAction = "open_url"
+	RunCommand      ClickAction = "run_command"
+	SuggestCommand  ClickAction = "suggest_command"
+	CopyToClipboard ClickAction = "copy_to_clipboard"
+)
+
+// ClickEvent of a component.
+type ClickEvent struct {
+	Action ClickAction `json:"action"`
+	Value  string      `json:"value"`
+}
+
+// HoverEvent of a component that shows a text as tooltip.
+type HoverEvent struct {
+	Action   string    `json:"action"`
+	Contents Component `json:"contents"`
+}
+
+// Component is a JSON text component.
+// Use Text to create a new one and the methods to style it.
+// The methods return a modified copy, so components can be used as templates.
+type Component struct {
+	Text          string      `json:"text"`
+	Color         Color       `json:"color,omitempty"`
+	Bold          bool        `json:"bold,omitempty"`
+	Italic        bool        `json:"italic,omitempty"`
+	Underlined    bool        `json:"underlined,omitempty"`
+	Strikethrough bool        `json:"strikethrough,omitempty"`
+	Obfuscated    bool        `json:"obfuscated,omitempty"`
+	ClickEvent    *ClickEvent `json:"clickEvent,omitempty"`
+	HoverEvent    *HoverEvent `json:"hoverEvent,omitempty"`
+	Extra         []Component `json:"extra,omitempty"`
+}
+
+// Text creates a new component with the text.
+// The text is taken literally, JSON escaping is done while encoding.
+func Text(s string) Component {
+	return Component{Text: s}
+}
+
+// WithColor returns a copy of the component with the color.
+func (c Component) WithColor(color Color) Component {
+	c.Color = color
+	return c
+}
+
+// WithBold returns a bold copy of the component.
+func (c Component) WithBold() Component {
+	c.Bold = true
+	return c
+}
+
+// WithItalic returns an italic copy of the component.
+func (c Component) WithItalic() Component {
+	c.Italic = true
+	return c
+}
+
+// WithUnderlined returns an underlined copy of the component.
+func (c Component) WithUnderlined() Component {
+	c.Underlined = true
+	return c
+}
+
+// WithStrikethrough returns a struck through copy of the component.
+func (c Component) WithStrikethrough() Component {
+	c.Strikethrough = true
+	return c
+}
+
+// WithObfuscated returns an obfuscated copy of the component.
+func (c Component) WithObfuscated() Component {
+	c.Obfuscated = true
+	return c
+}
+
+// OnClick returns a copy of the component that executes the action with the value on click.
+func (c Component) OnClick(action ClickAction, value string) Component {
+	c.ClickEvent = &ClickEvent{Action: action, Value: value}
+	return c
+}
+
+// OnHover returns a copy of the component that shows the tooltip on hover.
+func (c Component) OnHover(tooltip Component) Component {
+	c.HoverEvent = &HoverEvent{Action: "show_text", Contents: tooltip}
+	return c
+}
+
+// Append returns a copy of the component with the children appended.
+// Children inherit the style of their parent.
+func (c Component) Append(children ...Component) Component {
+	extra := make([]Component, 0, len(c.Extra)+len(children))
+	extra = append(extra, c.Extra...)
+	c.Extra = append(extra, children...)
+	return c
+}
+
+// JSON returns the component encoded as JSON.
+func (c Component) JSON() string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	// keep characters like '<' and '&' readable in the chat commands.
+	encoder.SetEscapeHTML(false)
+	// encoding a struct of strings and bools can not fail.
+	_ = encoder.Encode(c)
+
+	return string(bytes.TrimRight(b.Bytes(), "\n"))
+}
+
+// String returns the JSON encoding of the component.
+func (c Component) String() string {
+	return c.JSON()
+}
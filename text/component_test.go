@@ -0,0 +1,50 @@
+package text_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hamburghammer/grcon/text"
+)
+
+func TestComponent_JSON(t *testing.T) {
+	t.Run("escaping", func(t *testing.T) {
+		got := text.Text(`Bob "the <builder>" \o/`).JSON()
+		expect := `{"text":"Bob \"the <builder>\" \\o/"}`
+		if got != expect {
+			t.Errorf("json did not match:\nexpected: %s\ngot: %s\n", expect, got)
+		}
+	})
+
+	t.Run("events and children", func(t *testing.T) {
+		component := text.Text("Server restart in 5 minutes. ").WithColor(text.Red).WithBold().Append(
+			text.Text("[Details]").
+				WithColor(text.Aqua).
+				OnClick(text.OpenURL, "https://example.com/status").
+				OnHover(text.Text("Open the status page")),
+		)
+
+		expect := `{"text":"Server restart in 5 minutes. ","color":"red","bold":true,"extra":[` +
+			`{"text":"[Details]","color":"aqua","clickEvent":{"action":"open_url","value":"https://example.com/status"},` +
+			`"hoverEvent":{"action":"show_text","contents":{"text":"Open the status page"}}}]}`
+		got := component.JSON()
+		if got != expect {
+			t.Errorf("json did not match:\nexpected: %s\ngot: %s\n", expect, got)
+		}
+
+		var decoded text.Component
+		if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+			t.Errorf("json is not valid: %s\n", err)
+		}
+	})
+
+	t.Run("append does not modify the template", func(t *testing.T) {
+		template := text.Text("prefix: ")
+		first := template.Append(text.Text("a"))
+		_ = template.Append(text.Text("b"))
+
+		if len(template.Extra) != 0 || first.Extra[0].Text != "a" {
+			t.Errorf("template was modified:\ntemplate: %s\nfirst: %s\n", template, first)
+		}
+	})
+}
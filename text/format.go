@@ -0,0 +1,96 @@
+/*
+Package text handles the text formatting of minecraft.
+
+It strips or converts the legacy '§' formatting codes that are part of many responses
+and builds JSON text components for commands like tellraw and title.
+
+https://minecraft.wiki/w/Formatting_codes
+https://minecraft.wiki/w/Raw_JSON_text_format
+*/
+package text
+
+import "strings"
+
+// FormattingPrefix is the character that starts a formatting code.
+const FormattingPrefix = '§'
+
+// ansiCodes maps the formatting codes to their ANSI escape sequences.
+var ansiCodes = map[rune]string{
+	'0': "\x1b[30m",
+	'1': "\x1b[34m",
+	'2': "\x1b[32m",
+	'3': "\x1b[36m",
+	'4': "\x1b[31m",
+	'5': "\x1b[35m",
+	'6': "\x1b[33m",
+	'7': "\x1b[37m",
+	'8': "\x1b[90m",
+	'9': "\x1b[94m",
+	'a': "\x1b[92m",
+	'b': "\x1b[96m",
+	'c': "\x1b[91m",
+	'd': "\x1b[95m",
+	'e': "\x1b[93m",
+	'f': "\x1b[97m",
+	// obfuscated has no ANSI equivalent and gets dropped.
+	'k': "",
+	'l': "\x1b[1m",
+	'm': "\x1b[9m",
+	'n': "\x1b[4m",
+	'o': "\x1b[3m",
+	'r': "\x1b[0m",
+}
+
+const ansiReset = "\x1b[0m"
+
+// Strip removes all formatting codes from the string.
+func Strip(s string) string {
+	return convert(s, func(rune) string { return "" })
+}
+
+// ToANSI converts the formatting codes into ANSI escape sequences for terminals.
+// Unknown codes are removed. A reset sequence gets appended if the string contained any code.
+func ToANSI(s string) string {
+	converted := false
+	result := convert(s, func(code rune) string {
+		converted = true
+		return ansiCodes[code]
+	})
+
+	if converted {
+		result += ansiReset
+	}
+	return result
+}
+
+// convert replaces every formatting code with the result of the replace function.
+// The code gets passed in lower case. A trailing prefix without code is removed.
+func convert(s string, replace func(code rune) string) string {
+	if !strings.ContainsRune(s, FormattingPrefix) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != FormattingPrefix {
+			b.WriteRune(runes[i])
+			continue
+		}
+		if i+1 < len(runes) {
+			b.WriteString(replace(toLower(runes[i+1])))
+			i++
+		}
+	}
+
+	return b.String()
+}
+
+func toLower(r rune) rune {
+	if r >= 'A' && r <= 'Z' {
+		return r + ('a' - 'A')
+	}
+	return r
+}
@@ -0,0 +1,41 @@
+package text_test
+
+import (
+	"testing"
+
+	"github.com/hamburghammer/grcon/text"
+)
+
+func TestStrip(t *testing.T) {
+	tests := map[string]string{
+		"no codes":         "no codes",
+		"§6Gold §lbold§r!": "Gold bold!",
+		"§AUpper case":     "Upper case",
+		"trailing§":        "trailing",
+		"§x":               "",
+	}
+
+	for input, expect := range tests {
+		got := text.Strip(input)
+		if got != expect {
+			t.Errorf("stripped string did not match for %q:\nexpected: %q\ngot: %q\n", input, expect, got)
+		}
+	}
+}
+
+func TestToANSI(t *testing.T) {
+	t.Run("convert codes", func(t *testing.T) {
+		got := text.ToANSI("§6Gold §lbold")
+		expect := "\x1b[33mGold \x1b[1mbold\x1b[0m"
+		if got != expect {
+			t.Errorf("converted string did not match:\nexpected: %q\ngot: %q\n", expect, got)
+		}
+	})
+
+	t.Run("no codes", func(t *testing.T) {
+		got := text.ToANSI("plain")
+		if got != "plain" {
+			t.Errorf("converted string did not match:\nexpected: %q\ngot: %q\n", "plain", got)
+		}
+	})
+}
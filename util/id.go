@@ -1,12 +1,30 @@
 package util
 
 import (
+	"sync"
 	"time"
 
 	"github.com/hamburghammer/grcon"
 )
 
+var (
+	requestIdMutex sync.Mutex
+	lastRequestId  grcon.PacketId
+)
+
 // GenerateRequestId is a convenience function to generate an id using the current time.
+// Consecutive calls never return the same id, even within the same tenth of a millisecond.
+// The clients tell the response of a command from the answer to the following delimiter
+// or marker packet by their ids, which are generated right after each other.
 func GenerateRequestId() grcon.PacketId {
-	return grcon.PacketId((time.Now().UnixNano() / 100000) % 100000)
+	requestIdMutex.Lock()
+	defer requestIdMutex.Unlock()
+
+	id := grcon.PacketId((time.Now().UnixNano() / 100000) % 100000)
+	if id == lastRequestId {
+		id = (id + 1) % 100000
+	}
+	lastRequestId = id
+
+	return id
 }
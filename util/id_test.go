@@ -0,0 +1,42 @@
+package util_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/util"
+)
+
+func TestGenerateRequestId(t *testing.T) {
+	t.Run("consecutive ids differ", func(t *testing.T) {
+		last := util.GenerateRequestId()
+		for i := 0; i < 1000; i++ {
+			id := util.GenerateRequestId()
+			if id == last {
+				t.Fatalf("consecutive ids are equal: %d\n", id)
+			}
+			if id < 0 {
+				t.Fatalf("id is negative: %d\n", id)
+			}
+			last = id
+		}
+	})
+
+	t.Run("concurrent calls", func(t *testing.T) {
+		// the clients of different connections generate their ids concurrently.
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					if id := util.GenerateRequestId(); id < 0 || id >= grcon.PacketId(100000) {
+						t.Errorf("id out of range: %d\n", id)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
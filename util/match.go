@@ -0,0 +1,77 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MatchCommand reports if the command matches the pattern.
+// In the pattern '*' matches any sequence of characters including spaces and slashes
+// and '?' matches a single character. All other characters match themself.
+//
+// The command word, the first word of the pattern and the command, is compared case-insensitively
+// because the servers execute commands in any case. Leading and trailing whitespace is ignored
+// and every run of whitespace counts as a single space. A single leading '/', which minecraft
+// servers accept, and the quotes around the command word, which source servers remove, are
+// ignored as well.
+//
+// Commands that contain ';', '\n' or '\r' never match: servers execute them as multiple commands,
+// which would let e.g. "say hi; rcon_password x" pass the pattern "say *".
+//
+// Example: the pattern "say *" matches "SAY  hello world" but not "sayhello".
+func MatchCommand(pattern, cmd string) bool {
+	if strings.ContainsAny(cmd, ";\n\r") {
+		return false
+	}
+	return MatchWildcard(normalizeCommand(pattern), normalizeCommand(cmd))
+}
+
+// normalizeCommand removes the slash and the quotes of the command word, lowers it
+// and collapses the whitespace.
+func normalizeCommand(cmd string) string {
+	cmd = strings.TrimPrefix(strings.TrimSpace(cmd), "/")
+
+	word, rest := cmd, ""
+	if strings.HasPrefix(cmd, `"`) {
+		// the word ends at the closing quote or at the end of the command.
+		word = cmd[1:]
+		if end := strings.IndexByte(word, '"'); end >= 0 {
+			word, rest = word[:end], word[end+1:]
+		}
+	} else if i := strings.IndexFunc(cmd, unicode.IsSpace); i >= 0 {
+		word, rest = cmd[:i], cmd[i:]
+	}
+
+	words := append([]string{strings.ToLower(word)}, strings.Fields(rest)...)
+	return strings.Join(words, " ")
+}
+
+// MatchWildcard reports if the string matches the pattern.
+// In the pattern '*' matches any sequence of bytes and '?' matches a single byte.
+// Unlike MatchCommand it compares the string as it is and case-sensitively, which suits names and labels.
+func MatchWildcard(pattern, s string) bool {
+	p, i := 0, 0
+	starP, starI := -1, 0
+
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[i]):
+			p++
+			i++
+		case p < len(pattern) && pattern[p] == '*':
+			starP, starI = p, i
+			p++
+		case starP >= 0:
+			// let the last star consume one more character.
+			starI++
+			p, i = starP+1, starI
+		default:
+			return false
+		}
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,74 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/hamburghammer/grcon/util"
+)
+
+func TestMatchCommand(t *testing.T) {
+	tests := []struct {
+		pattern string
+		cmd     string
+		expect  bool
+	}{
+		{pattern: "status", cmd: "status", expect: true},
+		{pattern: "status", cmd: " status ", expect: true},
+		{pattern: "status", cmd: "statuses", expect: false},
+		{pattern: "say *", cmd: "say hello world", expect: true},
+		{pattern: "say *", cmd: "sayhello", expect: false},
+		{pattern: "*", cmd: "/silent-command rcon.print(1)", expect: true},
+		{pattern: "/c *", cmd: "/c game.print('hi')", expect: true},
+		{pattern: "kick ? *", cmd: "kick a reason", expect: true},
+		{pattern: "*ban*", cmd: "banid 1", expect: true},
+		{pattern: "a*b*c", cmd: "axxbyyc", expect: true},
+		{pattern: "a*b*c", cmd: "axxbyy", expect: false},
+		{pattern: "say *", cmd: "say hi; rcon_password x", expect: false},
+		{pattern: "say *", cmd: "say hi\nrcon_password x", expect: false},
+		{pattern: "say *", cmd: "say hi\rrcon_password x", expect: false},
+		{pattern: "*", cmd: "status;kickall", expect: false},
+		{pattern: "rcon_password *", cmd: "RCON_PASSWORD x", expect: true},
+		{pattern: "rcon_password *", cmd: "rcon_password\tx", expect: true},
+		{pattern: "rcon_password *", cmd: "  Rcon_Password   x ", expect: true},
+		{pattern: "say  *", cmd: "say\t\thello", expect: true},
+		{pattern: "say Hello", cmd: "say hello", expect: false},
+		{pattern: "stop", cmd: "/stop", expect: true},
+		{pattern: "stop", cmd: " /STOP ", expect: true},
+		{pattern: "/stop", cmd: "stop", expect: true},
+		{pattern: "stop", cmd: "//stop", expect: false},
+		{pattern: "rcon_password *", cmd: `"rcon_password" x`, expect: true},
+		{pattern: "rcon_password *", cmd: `"RCON_PASSWORD"x`, expect: true},
+		{pattern: "rcon_password", cmd: `"rcon_password`, expect: true},
+		{pattern: "say *", cmd: `say "hi"`, expect: true},
+	}
+
+	for _, test := range tests {
+		got := util.MatchCommand(test.pattern, test.cmd)
+		if got != test.expect {
+			t.Errorf("match of pattern %q and command %q:\nexpected: %t\ngot: %t\n", test.pattern, test.cmd, test.expect, got)
+		}
+	}
+}
+
+func TestMatchWildcard(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		expect  bool
+	}{
+		{pattern: "cs2-*", s: "cs2-eu-1", expect: true},
+		{pattern: "cs2-?u-1", s: "cs2-eu-1", expect: true},
+		{pattern: "*", s: "", expect: true},
+		{pattern: "fra", s: "FRA", expect: false},
+		{pattern: "survival", s: "/survival", expect: false},
+		{pattern: "a b", s: "a  b", expect: false},
+		{pattern: "a;*", s: "a;b", expect: true},
+	}
+
+	for _, test := range tests {
+		got := util.MatchWildcard(test.pattern, test.s)
+		if got != test.expect {
+			t.Errorf("match of pattern %q and string %q:\nexpected: %t\ngot: %t\n", test.pattern, test.s, test.expect, got)
+		}
+	}
+}
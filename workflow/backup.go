@@ -0,0 +1,129 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+)
+
+// save-on is retried because the connection might have broken during the snapshot.
+const saveOnAttempts = 3
+
+var saveOnRetryDelay = time.Second
+
+// Snapshotter copies the world while the server does not write to it.
+type Snapshotter interface {
+	// Snapshot creates the snapshot and returns its location.
+	Snapshot(ctx context.Context) (string, error)
+}
+
+// SnapshotFunc is an adapter to use a function as Snapshotter.
+type SnapshotFunc func(ctx context.Context) (string, error)
+
+// Snapshot calls the function.
+func (sf SnapshotFunc) Snapshot(ctx context.Context) (string, error) {
+	return sf(ctx)
+}
+
+// NewBackup is a constructor for the Backup struct.
+func NewBackup(c *client.ReconnectingClient, snapshotter Snapshotter) *Backup {
+	return &Backup{Client: c, Snapshotter: snapshotter}
+}
+
+// Backup is a consistent backup of a minecraft world:
+//	- the automatic saving gets disabled with "save-off"
+//	- the world gets flushed with "save-all flush" and the "Saved the game" confirmation is awaited
+//	- the Snapshotter copies the world
+//	- the automatic saving gets enabled again with "save-on"
+// "save-on" is always issued once "save-off" was sent, even if a later step failed or the context was canceled.
+type Backup struct {
+	// Client of the minecraft server. Its dialect must be client.DialectMinecraft.
+	Client *client.ReconnectingClient
+	// Snapshotter copies the world, e.g. a DirSnapshot or a TarGzSnapshot.
+	Snapshotter Snapshotter
+	// Logger for the progress. Nothing is logged if it is nil.
+	Logger *log.Logger
+}
+
+// Run creates the backup and returns the location of the snapshot.
+//
+// Errors:
+// Returns a StepError with the failed step. A failed "save-on" is reported
+// even if an earlier step failed, because the world is not saved anymore.
+func (b *Backup) Run(ctx context.Context) (location string, err error) {
+	if b.Client.Dialect != client.DialectMinecraft {
+		return "", StepError{Step: "backup", Err: fmt.Errorf("unsupported dialect '%s'", b.Client.Dialect)}
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	defer func() {
+		saveOnErr := b.saveOn()
+		switch {
+		case saveOnErr == nil:
+		case err == nil:
+			err = StepError{Step: "save-on", Err: saveOnErr}
+		default:
+			err = fmt.Errorf("%w (save-on also failed: %s)", err, saveOnErr.Error())
+		}
+	}()
+
+	b.logf("disabling automatic saving")
+	if err := b.do(client.MinecraftClient.SaveOff); err != nil {
+		return "", StepError{Step: "save-off", Err: err}
+	}
+
+	b.logf("flushing the world")
+	if err := b.do(client.MinecraftClient.SaveAll); err != nil {
+		return "", StepError{Step: "save-all", Err: err}
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	b.logf("creating the snapshot")
+	location, err = b.Snapshotter.Snapshot(ctx)
+	if err != nil {
+		return "", StepError{Step: "snapshot", Err: err}
+	}
+	b.logf("created snapshot %s", location)
+
+	return location, nil
+}
+
+// saveOn enables the automatic saving and retries on failures.
+func (b *Backup) saveOn() error {
+	var err error
+	for attempt := 1; attempt <= saveOnAttempts; attempt++ {
+		b.logf("enabling automatic saving")
+		if err = b.do(client.MinecraftClient.SaveOn); err == nil {
+			return nil
+		}
+		b.logf("enabling automatic saving failed: %s", err.Error())
+		if attempt < saveOnAttempts {
+			time.Sleep(saveOnRetryDelay)
+		}
+	}
+	return err
+}
+
+// do calls the method with the MinecraftClient of the connection.
+func (b *Backup) do(method func(client.MinecraftClient) error) error {
+	return b.Client.Do(func(c client.Client) error {
+		minecraftClient, ok := c.(client.MinecraftClient)
+		if !ok {
+			return fmt.Errorf("expected a MinecraftClient but got %T", c)
+		}
+		return method(minecraftClient)
+	})
+}
+
+func (b *Backup) logf(format string, args ...interface{}) {
+	if b.Logger != nil {
+		b.Logger.Printf(format, args...)
+	}
+}
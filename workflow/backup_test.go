@@ -0,0 +1,120 @@
+package workflow_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hamburghammer/grcon/workflow"
+)
+
+func newMinecraftServer() *fakeServer {
+	return &fakeServer{Responses: map[string]string{
+		"save-off":       "Automatic saving is now disabled",
+		"save-all flush": "Saving the game (this may take a moment!)Saved the game",
+		"save-on":        "Automatic saving is now enabled",
+	}}
+}
+
+func TestBackup_Run(t *testing.T) {
+	t.Run("successful backup", func(t *testing.T) {
+		fs := newMinecraftServer()
+		var executedBeforeSnapshot []string
+		backup := workflow.NewBackup(fs.start(t), workflow.SnapshotFunc(func(ctx context.Context) (string, error) {
+			executedBeforeSnapshot = fs.Executed()
+			return "/backups/world-1", nil
+		}))
+
+		location, err := backup.Run(context.Background())
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if location != "/backups/world-1" {
+			t.Errorf("location did not match:\nexpected: %s\ngot: %s\n", "/backups/world-1", location)
+		}
+
+		expected := []string{"save-off", "save-all flush"}
+		if strings.Join(executedBeforeSnapshot, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("commands before the snapshot did not match:\nexpected: %q\ngot: %q\n", expected, executedBeforeSnapshot)
+		}
+		expected = append(expected, "save-on")
+		if got := fs.Executed(); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("commands did not match:\nexpected: %q\ngot: %q\n", expected, got)
+		}
+	})
+
+	t.Run("save-on after failures", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			response string
+			snapshot workflow.SnapshotFunc
+			step     string
+		}{
+			{
+				name:     "snapshot failed",
+				snapshot: func(ctx context.Context) (string, error) { return "", errors.New("disk full") },
+				step:     "snapshot",
+			},
+			{
+				name:     "save not confirmed",
+				response: "Saving failed",
+				snapshot: func(ctx context.Context) (string, error) { return "", nil },
+				step:     "save-all",
+			},
+		}
+
+		for _, test := range tests {
+			test := test
+			t.Run(test.name, func(t *testing.T) {
+				fs := newMinecraftServer()
+				if test.response != "" {
+					fs.Responses["save-all flush"] = test.response
+				}
+				backup := workflow.NewBackup(fs.start(t), test.snapshot)
+
+				_, err := backup.Run(context.Background())
+				var stepErr workflow.StepError
+				if !errors.As(err, &stepErr) || stepErr.Step != test.step {
+					t.Errorf("error did not match:\nexpected: StepError of %s\ngot: %v\n", test.step, err)
+				}
+				if got := fs.Executed(); got[len(got)-1] != "save-on" {
+					t.Errorf("save-on was not issued: %q\n", got)
+				}
+			})
+		}
+	})
+
+	t.Run("save-on after cancellation", func(t *testing.T) {
+		fs := newMinecraftServer()
+		ctx, cancel := context.WithCancel(context.Background())
+		backup := workflow.NewBackup(fs.start(t), workflow.SnapshotFunc(func(ctx context.Context) (string, error) {
+			cancel()
+			<-ctx.Done()
+			return "", ctx.Err()
+		}))
+
+		_, err := backup.Run(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected: %s\ngot: %v\n", context.Canceled, err)
+		}
+		if got := fs.Executed(); got[len(got)-1] != "save-on" {
+			t.Errorf("save-on was not issued: %q\n", got)
+		}
+	})
+
+	t.Run("unsupported dialect", func(t *testing.T) {
+		fs := newMinecraftServer()
+		c := fs.start(t)
+		c.Dialect = "source"
+		backup := workflow.NewBackup(c, workflow.SnapshotFunc(func(ctx context.Context) (string, error) { return "", nil }))
+
+		if _, err := backup.Run(context.Background()); err == nil {
+			t.Error("expected an error for the source dialect")
+		}
+		if got := fs.Executed(); len(got) != 0 {
+			t.Errorf("expected no commands but got: %q\n", got)
+		}
+	})
+}
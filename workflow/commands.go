@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"strings"
+
+	"github.com/hamburghammer/grcon/client"
+)
+
+// Disabled turns off a command, e.g. the Save of a game that saves on its own.
+const Disabled = "-"
+
+// Commands are the server commands the workflows use.
+// The "%s" in Broadcast and Kick gets replaced by the message.
+// Empty commands get the command of the dialect, commands set to Disabled are skipped.
+// The Stop command cannot be disabled.
+type Commands struct {
+	// Broadcast sends a message to all players, e.g. "say %s" or "AdminBroadcast %s".
+	Broadcast string `json:"broadcast"`
+	// Kick disconnects all players.
+	Kick string `json:"kick"`
+	// Save flushes the world to the disk.
+	Save string `json:"save"`
+	// Stop shuts the server down.
+	Stop string `json:"stop"`
+}
+
+// DefaultCommands returns the commands of the dialect.
+// Unknown dialects get the commands of Source servers.
+func DefaultCommands(d client.Dialect) Commands {
+	switch d {
+	case client.DialectMinecraft:
+		return Commands{Broadcast: "say %s", Kick: "kick @a %s", Save: "save-all flush", Stop: "stop"}
+	case client.DialectFactorio:
+		// Factorio broadcasts everything that is not a command as chat message.
+		return Commands{Broadcast: "%s", Save: "/server-save", Stop: "/quit"}
+	}
+	return Commands{Broadcast: "say %s", Kick: "kickall %s", Stop: "quit"}
+}
+
+// merge returns the commands with the empty ones replaced by the defaults
+// and the disabled ones replaced by empty commands.
+func (c Commands) merge(defaults Commands) Commands {
+	if c.Broadcast == "" {
+		c.Broadcast = defaults.Broadcast
+	}
+	if c.Kick == "" {
+		c.Kick = defaults.Kick
+	}
+	if c.Save == "" {
+		c.Save = defaults.Save
+	}
+	if c.Stop == "" || c.Stop == Disabled {
+		c.Stop = defaults.Stop
+	}
+
+	for _, command := range []*string{&c.Broadcast, &c.Kick, &c.Save} {
+		if *command == Disabled {
+			*command = ""
+		}
+	}
+	return c
+}
+
+// format replaces the "%s" in the command with the message.
+func format(command, msg string) string {
+	return strings.Replace(command, "%s", msg, -1)
+}
@@ -0,0 +1,30 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+)
+
+// StillRunningError occurs if the server did not close the connection after the stop command.
+type StillRunningError struct {
+	Timeout time.Duration
+}
+
+func (sre StillRunningError) Error() string {
+	return fmt.Sprintf("grcon-workflow: server did not close the connection within %s after the stop command", sre.Timeout)
+}
+
+// StepError occurs if a step of a workflow failed.
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (se StepError) Error() string {
+	return fmt.Sprintf("grcon-workflow: %s failed: %s", se.Step, se.Err.Error())
+}
+
+// Unwrap returns the cause of the error.
+func (se StepError) Unwrap() error {
+	return se.Err
+}
@@ -0,0 +1,269 @@
+/*
+Package workflow implements multi-step operations on top of the clients.
+*/
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/hamburghammer/grcon"
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/util"
+)
+
+// DefaultWarnings are the remaining times at which a restart gets announced.
+var DefaultWarnings = []time.Duration{
+	30 * time.Minute, 15 * time.Minute, 10 * time.Minute, 5 * time.Minute,
+	time.Minute, 30 * time.Second, 10 * time.Second,
+}
+
+// Defaults of the Restart.
+const (
+	DefaultRestartMessage = "Server restarts in %s"
+	DefaultCancelMessage  = "Restart canceled"
+	DefaultDownTimeout    = time.Minute
+)
+
+// NewRestart is a constructor for the Restart struct with the commands of the dialect of the client.
+func NewRestart(c *client.ReconnectingClient, in time.Duration) *Restart {
+	return &Restart{
+		Client:      c,
+		In:          in,
+		Warnings:    DefaultWarnings,
+		Message:     DefaultRestartMessage,
+		DownTimeout: DefaultDownTimeout,
+	}
+}
+
+// Restart is a graceful shutdown:
+//	- the restart gets announced at the start and at the Warnings
+//	- the players get kicked if a KickMessage is set
+//	- the world gets saved
+//	- the server gets stopped
+//	- the restart is confirmed when the server closes the connection
+// A process manager like systemd or Docker is expected to start the server again.
+type Restart struct {
+	// Client for the announcements. Its Dial, Dialect and Password are used to
+	// open a dedicated connection for the shutdown.
+	Client *client.ReconnectingClient
+	// In is the time until the server gets stopped.
+	In time.Duration
+	// Warnings are the remaining times at which the restart gets announced.
+	Warnings []time.Duration
+	// Message is the announcement. "%s" gets replaced by the remaining time like "10 minutes".
+	Message string
+	// KickMessage kicks all players with the message before the world gets saved. No one is kicked if it is empty.
+	KickMessage string
+	// Commands overrides the commands of the dialect.
+	Commands Commands
+	// DownTimeout limits the wait for the closed connection after the stop command.
+	DownTimeout time.Duration
+	// Logger for the progress. Nothing is logged if it is nil.
+	Logger *log.Logger
+}
+
+// Run announces the restart and stops the server.
+// Canceling the context during the countdown aborts the restart and announces the cancellation.
+//
+// Errors:
+// Returns a StepError if the shutdown connection, kicking, saving or the stop command failed
+// and a StillRunningError if the server did not close the connection in time.
+// Failed announcements are only logged.
+func (r *Restart) Run(ctx context.Context) error {
+	commands := r.Commands.merge(DefaultCommands(r.Client.Dialect))
+
+	if err := r.countdown(ctx, commands); err != nil {
+		if commands.Broadcast != "" {
+			r.exec(r.Client, format(commands.Broadcast, DefaultCancelMessage))
+		}
+		return err
+	}
+
+	// the connection of the announcements is not needed anymore.
+	r.Client.Close()
+	return r.shutdown(commands)
+}
+
+// countdown announces the restart until it is due or the context is done.
+func (r *Restart) countdown(ctx context.Context, commands Commands) error {
+	stopAt := time.Now().Add(r.In)
+
+	warnings := []time.Duration{}
+	for _, warning := range r.Warnings {
+		if warning > 0 && warning < r.In {
+			warnings = append(warnings, warning)
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i] > warnings[j] })
+	if r.In > 0 {
+		warnings = append([]time.Duration{r.In}, warnings...)
+	}
+
+	for _, remaining := range warnings {
+		if err := sleepUntil(ctx, stopAt.Add(-remaining)); err != nil {
+			return err
+		}
+		r.announce(commands, remaining)
+	}
+
+	return sleepUntil(ctx, stopAt)
+}
+
+func (r *Restart) announce(commands Commands, remaining time.Duration) {
+	if commands.Broadcast == "" {
+		return
+	}
+	msg := format(r.Message, FormatRemaining(remaining))
+	r.logf("announcing: %s", msg)
+	if _, err := r.exec(r.Client, format(commands.Broadcast, msg)); err != nil {
+		r.logf("announcement failed: %s", err.Error())
+	}
+}
+
+// shutdown kicks, saves and stops over a dedicated connection and waits until the server closes it.
+func (r *Restart) shutdown(commands Commands) error {
+	conn, err := r.Client.Dial()
+	if err != nil {
+		return StepError{Step: "connecting", Err: err}
+	}
+	defer conn.Close()
+
+	timeout := r.Client.Timeout
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+	c, err := client.NewDialectClient(r.Client.Dialect, grcon.NewRemoteConsoleSize(conn, r.Client.Dialect.MaxPacket()), util.GenerateRequestId)
+	if err != nil {
+		return StepError{Step: "connecting", Err: err}
+	}
+	if err := c.Auth(r.Client.Password); err != nil {
+		return StepError{Step: "authentication", Err: err}
+	}
+
+	if r.KickMessage != "" && commands.Kick != "" {
+		r.logf("kicking all players")
+		if _, err := r.exec(c, format(commands.Kick, r.KickMessage)); err != nil {
+			return StepError{Step: "kicking", Err: err}
+		}
+	}
+
+	if commands.Save != "" {
+		r.logf("saving")
+		if timeout > 0 {
+			conn.SetDeadline(time.Now().Add(timeout))
+		}
+		if _, err := r.exec(c, commands.Save); err != nil {
+			return StepError{Step: "saving", Err: err}
+		}
+	}
+
+	r.logf("stopping")
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+	if _, err := r.exec(c, commands.Stop); err != nil {
+		// servers often close the connection before they respond to the stop command.
+		if !isClosed(err) {
+			return StepError{Step: "stopping", Err: err}
+		}
+	}
+
+	return r.waitClosed(conn)
+}
+
+// waitClosed reads from the connection until the server closes it.
+func (r *Restart) waitClosed(conn net.Conn) error {
+	downTimeout := r.DownTimeout
+	if downTimeout <= 0 {
+		downTimeout = DefaultDownTimeout
+	}
+	conn.SetDeadline(time.Now().Add(downTimeout))
+
+	buf := make([]byte, 4096)
+	for {
+		_, err := conn.Read(buf)
+		if err == nil {
+			continue
+		}
+
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return StillRunningError{Timeout: downTimeout}
+		}
+		r.logf("server closed the connection")
+		return nil
+	}
+}
+
+func (r *Restart) exec(c client.Client, cmd string) ([]byte, error) {
+	response, err := c.Exec(cmd)
+	if err == nil && len(response) > 0 {
+		r.logf("%s: %s", cmd, string(response))
+	}
+	return response, err
+}
+
+func (r *Restart) logf(format string, args ...interface{}) {
+	if r.Logger != nil {
+		r.Logger.Printf(format, args...)
+	}
+}
+
+// isClosed reports if the error is caused by a closed connection.
+func isClosed(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return false
+	}
+	var grconErr grcon.GrconError
+	// protocol errors mean the server is still talking.
+	return !errors.As(err, &grconErr)
+}
+
+// sleepUntil waits until the time or returns the error of the context.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// FormatRemaining formats the duration for announcements like "10 minutes" or "1 minute 30 seconds".
+func FormatRemaining(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Second {
+		return "now"
+	}
+
+	parts := []string{}
+	for _, unit := range []struct {
+		size time.Duration
+		name string
+	}{{time.Hour, "hour"}, {time.Minute, "minute"}, {time.Second, "second"}} {
+		n := d / unit.size
+		d -= n * unit.size
+		switch {
+		case n == 1:
+			parts = append(parts, "1 "+unit.name)
+		case n > 1:
+			parts = append(parts, fmt.Sprintf("%d %ss", n, unit.name))
+		}
+	}
+
+	result := parts[0]
+	for _, part := range parts[1:] {
+		result += " " + part
+	}
+	return result
+}
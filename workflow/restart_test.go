@@ -0,0 +1,175 @@
+package workflow_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hamburghammer/grcon/client"
+	"github.com/hamburghammer/grcon/server"
+	"github.com/hamburghammer/grcon/workflow"
+)
+
+// fakeServer records the commands, answers with the Responses and shuts down on "stop" if Stops is set.
+type fakeServer struct {
+	Stops     bool
+	Responses map[string]string
+
+	srv      *server.Server
+	mutex    sync.Mutex
+	executed []string
+}
+
+func (fs *fakeServer) ServeRCON(w server.ResponseWriter, r *server.Request) {
+	fs.mutex.Lock()
+	fs.executed = append(fs.executed, r.Command)
+	fs.mutex.Unlock()
+
+	if r.Command == "stop" {
+		w.Write([]byte("Stopping the server"))
+		if fs.Stops {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				fs.srv.Close()
+			}()
+		}
+		return
+	}
+	w.Write([]byte(fs.Responses[r.Command]))
+}
+
+func (fs *fakeServer) Executed() []string {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	return append([]string{}, fs.executed...)
+}
+
+// start serves the fake minecraft server on a loopback address and returns a client for it.
+func (fs *fakeServer) start(t *testing.T) *client.ReconnectingClient {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs.srv = &server.Server{Handler: fs, Auth: server.PasswordAuth("secret")}
+	go fs.srv.Serve(l)
+	t.Cleanup(func() { fs.srv.Close() })
+
+	c := client.NewReconnectingClient(client.TCPDialer(l.Addr().String(), time.Second), client.DialectMinecraft, "secret")
+	c.Timeout = time.Second
+	return c
+}
+
+func TestRestart_Run(t *testing.T) {
+	t.Run("announces, kicks, saves and stops", func(t *testing.T) {
+		fs := &fakeServer{Stops: true}
+		restart := workflow.NewRestart(fs.start(t), 300*time.Millisecond)
+		restart.Warnings = []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, time.Hour}
+		restart.Message = "restart soon"
+		restart.KickMessage = "be right back"
+
+		start := time.Now()
+		if err := restart.Run(context.Background()); err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+			t.Errorf("stopped too early: %s\n", elapsed)
+		}
+
+		expected := []string{"say restart soon", "say restart soon", "say restart soon", "kick @a be right back", "save-all flush", "stop"}
+		got := fs.Executed()
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("commands did not match:\nexpected: %q\ngot: %q\n", expected, got)
+		}
+	})
+
+	t.Run("overridden commands", func(t *testing.T) {
+		fs := &fakeServer{Stops: true}
+		restart := workflow.NewRestart(fs.start(t), 0)
+		restart.Commands = workflow.Commands{Broadcast: "AdminBroadcast %s", Save: "save-all"}
+
+		if err := restart.Run(context.Background()); err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		expected := []string{"save-all", "stop"}
+		got := fs.Executed()
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("commands did not match:\nexpected: %q\ngot: %q\n", expected, got)
+		}
+	})
+
+	t.Run("disabled commands", func(t *testing.T) {
+		fs := &fakeServer{Stops: true}
+		restart := workflow.NewRestart(fs.start(t), 100*time.Millisecond)
+		restart.Warnings = []time.Duration{50 * time.Millisecond}
+		restart.KickMessage = "be right back"
+		restart.Commands = workflow.Commands{Broadcast: workflow.Disabled, Kick: workflow.Disabled, Save: workflow.Disabled, Stop: workflow.Disabled}
+
+		if err := restart.Run(context.Background()); err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		expected := []string{"stop"}
+		got := fs.Executed()
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("commands did not match:\nexpected: %q\ngot: %q\n", expected, got)
+		}
+	})
+
+	t.Run("server keeps running", func(t *testing.T) {
+		fs := &fakeServer{}
+		restart := workflow.NewRestart(fs.start(t), 0)
+		restart.DownTimeout = 100 * time.Millisecond
+
+		err := restart.Run(context.Background())
+		var stillRunning workflow.StillRunningError
+		if !errors.As(err, &stillRunning) {
+			t.Errorf("expected: StillRunningError\ngot: %v\n", err)
+		}
+	})
+
+	t.Run("canceled countdown", func(t *testing.T) {
+		fs := &fakeServer{Stops: true}
+		restart := workflow.NewRestart(fs.start(t), time.Hour)
+		restart.Warnings = nil
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		err := restart.Run(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected: %s\ngot: %v\n", context.DeadlineExceeded, err)
+		}
+
+		expected := []string{"say Server restarts in 1 hour", "say Restart canceled"}
+		got := fs.Executed()
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("commands did not match:\nexpected: %q\ngot: %q\n", expected, got)
+		}
+	})
+}
+
+func TestFormatRemaining(t *testing.T) {
+	tests := []struct {
+		in     time.Duration
+		expect string
+	}{
+		{in: 10 * time.Minute, expect: "10 minutes"},
+		{in: time.Minute, expect: "1 minute"},
+		{in: 90 * time.Second, expect: "1 minute 30 seconds"},
+		{in: 2*time.Hour + time.Second, expect: "2 hours 1 second"},
+		{in: 10 * time.Millisecond, expect: "now"},
+	}
+
+	for _, test := range tests {
+		if got := workflow.FormatRemaining(test.in); got != test.expect {
+			t.Errorf("formatted duration did not match:\nexpected: %s\ngot: %s\n", test.expect, got)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package workflow
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotTimeFormat is used for the names of the snapshots. The names sort chronologically.
+const snapshotTimeFormat = "20060102-150405"
+
+// DirSnapshot copies the world directory into a new directory named
+// Prefix followed by the time in Destination.
+type DirSnapshot struct {
+	// World is the directory of the world.
+	World string
+	// Destination is the directory that contains the snapshots.
+	Destination string
+	// Prefix of the snapshot names, defaults to the base name of the World followed by a dash.
+	Prefix string
+	// Keep is the number of snapshots to keep. Older ones get pruned after a successful snapshot.
+	// Zero keeps all snapshots.
+	Keep int
+}
+
+// Snapshot copies the world and prunes old snapshots.
+func (ds DirSnapshot) Snapshot(ctx context.Context) (string, error) {
+	prefix := snapshotPrefix(ds.Prefix, ds.World)
+	target := filepath.Join(ds.Destination, prefix+time.Now().UTC().Format(snapshotTimeFormat))
+
+	// the snapshot gets renamed when it is complete to never prune or keep a partial one.
+	partial := target + ".partial"
+	if err := copyDir(ctx, ds.World, partial); err != nil {
+		os.RemoveAll(partial)
+		return "", err
+	}
+	if err := os.Rename(partial, target); err != nil {
+		os.RemoveAll(partial)
+		return "", err
+	}
+
+	if _, err := Prune(ds.Destination, prefix, "", ds.Keep); err != nil {
+		return target, err
+	}
+	return target, nil
+}
+
+// TarGzSnapshot writes the world directory into a new .tar.gz archive named
+// Prefix followed by the time in Destination.
+type TarGzSnapshot struct {
+	// World is the directory of the world.
+	World string
+	// Destination is the directory that contains the archives.
+	Destination string
+	// Prefix of the archive names, defaults to the base name of the World followed by a dash.
+	Prefix string
+	// Keep is the number of archives to keep. Older ones get pruned after a successful snapshot.
+	// Zero keeps all archives.
+	Keep int
+}
+
+// Snapshot archives the world and prunes old archives.
+func (ts TarGzSnapshot) Snapshot(ctx context.Context) (string, error) {
+	prefix := snapshotPrefix(ts.Prefix, ts.World)
+	target := filepath.Join(ts.Destination, prefix+time.Now().UTC().Format(snapshotTimeFormat)+".tar.gz")
+
+	partial := target + ".partial"
+	if err := writeTarGz(ctx, ts.World, partial); err != nil {
+		os.Remove(partial)
+		return "", err
+	}
+	if err := os.Rename(partial, target); err != nil {
+		os.Remove(partial)
+		return "", err
+	}
+
+	if _, err := Prune(ts.Destination, prefix, ".tar.gz", ts.Keep); err != nil {
+		return target, err
+	}
+	return target, nil
+}
+
+// Prune removes all but the newest keep snapshots in the directory.
+// Only entries with the prefix and suffix followed by a snapshot time are considered.
+// Nothing gets removed if keep is zero or negative. Returns the paths of the removed snapshots.
+func Prune(dir, prefix, suffix string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return []string{}, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix)
+		if _, err := time.Parse(snapshotTimeFormat, timestamp); err != nil {
+			continue
+		}
+		snapshots = append(snapshots, name)
+	}
+	sort.Strings(snapshots)
+
+	removed := []string{}
+	for len(snapshots) > keep {
+		path := filepath.Join(dir, snapshots[0])
+		if err := os.RemoveAll(path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, path)
+		snapshots = snapshots[1:]
+	}
+	return removed, nil
+}
+
+func snapshotPrefix(prefix, world string) string {
+	if prefix != "" {
+		return prefix
+	}
+	return filepath.Base(filepath.Clean(world)) + "-"
+}
+
+// copyDir copies the regular files and directories of src into dst.
+func copyDir(ctx context.Context, src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		// sockets, devices and symlinks are not part of a world.
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeTarGz writes the regular files and directories of src into a gzip compressed tar archive.
+// The paths in the archive start with the base name of src.
+func writeTarGz(ctx context.Context, src, dst string) error {
+	file, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	base := filepath.Base(filepath.Clean(src))
+
+	err = filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(base, rel))
+		if entry.IsDir() {
+			header.Name += "/"
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		if _, err := io.Copy(tarWriter, in); err != nil {
+			return fmt.Errorf("archiving '%s': %w", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,149 @@
+package workflow_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hamburghammer/grcon/workflow"
+)
+
+// newWorld creates a small world directory and returns its path.
+func newWorld(t *testing.T) string {
+	world := filepath.Join(t.TempDir(), "world")
+	files := map[string]string{
+		"level.dat":            "level",
+		"region/r.0.0.mca":     "region",
+		"playerdata/alice.dat": "alice",
+	}
+	for name, content := range files {
+		path := filepath.Join(world, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return world
+}
+
+func TestDirSnapshot(t *testing.T) {
+	world := newWorld(t)
+	destination := t.TempDir()
+
+	location, err := workflow.DirSnapshot{World: world, Destination: destination}.Snapshot(context.Background())
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if !strings.HasPrefix(filepath.Base(location), "world-") {
+		t.Errorf("unexpected snapshot name: %s\n", location)
+	}
+
+	got, err := os.ReadFile(filepath.Join(location, "region", "r.0.0.mca"))
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if string(got) != "region" {
+		t.Errorf("copied file did not match:\nexpected: %s\ngot: %s\n", "region", string(got))
+	}
+}
+
+func TestTarGzSnapshot(t *testing.T) {
+	world := newWorld(t)
+	destination := t.TempDir()
+
+	location, err := workflow.TarGzSnapshot{World: world, Destination: destination}.Snapshot(context.Background())
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	file, err := os.Open(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	files := map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(content)
+	}
+
+	if files["world/playerdata/alice.dat"] != "alice" || len(files) != 3 {
+		t.Errorf("archived files did not match: %v\n", files)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"world-20261001-030000.tar.gz",
+		"world-20261002-030000.tar.gz",
+		"world-20261003-030000.tar.gz",
+		"world-20261003-030000.tar.gz.partial",
+		"world-notes.tar.gz",
+		"other-20261001-030000.tar.gz",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte{}, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := workflow.Prune(dir, "world-", ".tar.gz", 2)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if len(removed) != 1 || filepath.Base(removed[0]) != "world-20261001-030000.tar.gz" {
+		t.Errorf("removed snapshots did not match: %v\n", removed)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	left := []string{}
+	for _, entry := range entries {
+		left = append(left, entry.Name())
+	}
+	sort.Strings(left)
+	expected := []string{
+		"other-20261001-030000.tar.gz",
+		"world-20261002-030000.tar.gz",
+		"world-20261003-030000.tar.gz",
+		"world-20261003-030000.tar.gz.partial",
+		"world-notes.tar.gz",
+	}
+	if strings.Join(left, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("remaining files did not match:\nexpected: %q\ngot: %q\n", expected, left)
+	}
+}